@@ -21,7 +21,15 @@ import (
 	"github.com/poimgs/coffee-tracker/backend/internal/domain/defaults"
 	"github.com/poimgs/coffee-tracker/backend/internal/domain/dripper"
 	"github.com/poimgs/coffee-tracker/backend/internal/domain/filterpaper"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/flavor"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/grinder"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/recipe"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/roaster"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/search"
 	"github.com/poimgs/coffee-tracker/backend/internal/domain/sharelink"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/stats"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/tag"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/water"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
 )
 
@@ -51,20 +59,44 @@ func main() {
 	refreshTokenRepo := auth.NewPgRefreshTokenRepository(pool)
 	filterPaperRepo := filterpaper.NewPgRepository(pool)
 	dripperRepo := dripper.NewPgRepository(pool)
+	grinderRepo := grinder.NewPgRepository(pool)
+	waterRepo := water.NewPgRepository(pool)
+	roasterRepo := roaster.NewPgRepository(pool)
 	coffeeRepo := coffee.NewPgRepository(pool)
 	brewRepo := brew.NewPgRepository(pool)
 	defaultsRepo := defaults.NewPgRepository(pool)
+	recipeRepo := recipe.NewPgRepository(pool)
 	shareLinkRepo := sharelink.NewPgRepository(pool)
+	statsRepo := stats.NewPgRepository(pool)
+	searchRepo := search.NewPgRepository(pool)
+	tagRepo := tag.NewPgRepository(pool)
+	flavorRepo := flavor.NewPgRepository(pool)
 
 	// Handlers
 	secureCookie := cfg.Environment != "development"
 	authHandler := auth.NewHandler(userRepo, refreshTokenRepo, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, secureCookie)
 	filterPaperHandler := filterpaper.NewHandler(filterPaperRepo)
 	dripperHandler := dripper.NewHandler(dripperRepo)
+	grinderHandler := grinder.NewHandler(grinderRepo)
+	waterHandler := water.NewHandler(waterRepo)
+	roasterHandler := roaster.NewHandler(roasterRepo)
 	coffeeHandler := coffee.NewHandler(coffeeRepo)
 	brewHandler := brew.NewHandler(brewRepo)
 	defaultsHandler := defaults.NewHandler(defaultsRepo)
-	shareLinkHandler := sharelink.NewHandler(shareLinkRepo, cfg.BaseURL)
+	recipeHandler := recipe.NewHandler(recipeRepo)
+	shareLinkHandler := sharelink.NewHandler(shareLinkRepo, cfg.BaseURL, cfg.JWTSecret)
+	statsHandler := stats.NewHandler(statsRepo)
+	searchHandler := search.NewHandler(searchRepo)
+	tagHandler := tag.NewHandler(tagRepo)
+	flavorHandler := flavor.NewHandler(flavorRepo)
+
+	// Background jobs
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+	autoArchiver := coffee.NewAutoArchiver(coffeeRepo, time.Duration(cfg.AutoArchiveInterval)*time.Second)
+	go autoArchiver.Run(jobsCtx)
+	viewPurger := sharelink.NewViewPurger(shareLinkRepo, time.Duration(cfg.ShareViewRetentionDays)*24*time.Hour, time.Hour)
+	go viewPurger.Run(jobsCtx)
 
 	r := chi.NewRouter()
 
@@ -88,10 +120,16 @@ func main() {
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
+	// Share page for link previews. Caddy sends crawlers here; people get
+	// the frontend.
+	r.With(middleware.RateLimit(30, time.Minute)).Get("/share/{token}", shareLinkHandler.Page)
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
-		// Public share endpoint (rate limited)
+		// Public share endpoints (rate limited)
 		r.With(middleware.RateLimit(30, time.Minute)).Get("/share/{token}", shareLinkHandler.GetSharedCoffees)
+		r.With(middleware.RateLimit(5, time.Minute)).Post("/share/{token}/unlock", shareLinkHandler.Unlock)
+		r.With(middleware.RateLimit(30, time.Minute)).Get("/share/{token}/image.png", shareLinkHandler.Image)
 
 		// Auth routes (public)
 		r.Route("/auth", func(r chi.Router) {
@@ -123,39 +161,127 @@ func main() {
 				r.Delete("/{id}", dripperHandler.Delete)
 			})
 
+			// Grinders
+			r.Route("/grinders", func(r chi.Router) {
+				r.Get("/", grinderHandler.List)
+				r.Post("/", grinderHandler.Create)
+				r.Get("/{id}", grinderHandler.GetByID)
+				r.Put("/{id}", grinderHandler.Update)
+				r.Delete("/{id}", grinderHandler.Delete)
+				r.Get("/{id}/calibration", grinderHandler.ListCalibration)
+				r.Post("/{id}/calibration", grinderHandler.AddCalibration)
+				r.Delete("/{id}/calibration/{pointId}", grinderHandler.DeleteCalibration)
+				r.Get("/{id}/translate", grinderHandler.Translate)
+			})
+
+			// Water profiles
+			r.Route("/water-profiles", func(r chi.Router) {
+				r.Get("/", waterHandler.List)
+				r.Post("/", waterHandler.Create)
+				r.Get("/{id}", waterHandler.GetByID)
+				r.Put("/{id}", waterHandler.Update)
+				r.Delete("/{id}", waterHandler.Delete)
+			})
+
+			// Roasters
+			r.Route("/roasters", func(r chi.Router) {
+				r.Get("/", roasterHandler.List)
+				r.Post("/", roasterHandler.Create)
+				r.Get("/{id}", roasterHandler.GetByID)
+				r.Put("/{id}", roasterHandler.Update)
+				r.Delete("/{id}", roasterHandler.Delete)
+				r.Post("/{id}/merge", roasterHandler.Merge)
+				r.Get("/{name}/stats", roasterHandler.Stats)
+			})
+
 			// Coffees
 			r.Route("/coffees", func(r chi.Router) {
 				r.Get("/", coffeeHandler.List)
 				r.Post("/", coffeeHandler.Create)
 				r.Get("/suggestions", coffeeHandler.Suggestions)
+				r.Get("/peak-windows", coffeeHandler.PeakWindows)
 				r.Get("/{id}", coffeeHandler.GetByID)
 				r.Put("/{id}", coffeeHandler.Update)
 				r.Delete("/{id}", coffeeHandler.Delete)
 				r.Post("/{id}/archive", coffeeHandler.Archive)
 				r.Post("/{id}/unarchive", coffeeHandler.Unarchive)
 				r.Post("/{id}/reference-brew", coffeeHandler.SetReferenceBrew)
+				r.Get("/{id}/adjustments", coffeeHandler.ListAdjustments)
+				r.Post("/{id}/adjustments", coffeeHandler.AdjustInventory)
+				r.Get("/{id}/archive-events", coffeeHandler.ListArchiveEvents)
+				r.Get("/{id}/peak-window", coffeeHandler.PeakWindow)
 				r.Get("/{id}/brews", brewHandler.ListByCoffee)
 				r.Get("/{id}/reference", brewHandler.GetReference)
+				r.Get("/{id}/suggestion", brewHandler.GetSuggestion)
 			})
 
 			// Defaults
 			r.Route("/defaults", func(r chi.Router) {
 				r.Get("/", defaultsHandler.Get)
 				r.Put("/", defaultsHandler.Put)
+				r.Get("/auto-archive", defaultsHandler.GetAutoArchive)
+				r.Put("/auto-archive", defaultsHandler.PutAutoArchive)
+				r.Get("/brew-targets", defaultsHandler.GetBrewTargets)
+				r.Put("/brew-targets", defaultsHandler.PutBrewTargets)
 				r.Delete("/{field}", defaultsHandler.DeleteField)
 			})
 
+			// Recipes
+			r.Route("/recipes", func(r chi.Router) {
+				r.Get("/", recipeHandler.List)
+				r.Post("/", recipeHandler.Create)
+				r.Get("/{id}", recipeHandler.GetByID)
+				r.Put("/{id}", recipeHandler.Update)
+				r.Delete("/{id}", recipeHandler.Delete)
+			})
+
 			// Brews
 			r.Route("/brews", func(r chi.Router) {
 				r.Get("/", brewHandler.List)
 				r.Get("/recent", brewHandler.Recent)
+				r.Get("/control-chart", brewHandler.ControlChart)
 				r.Post("/", brewHandler.Create)
 				r.Get("/{id}", brewHandler.GetByID)
 				r.Put("/{id}", brewHandler.Update)
 				r.Delete("/{id}", brewHandler.Delete)
 			})
 
-			// Share link management
+			// Tags
+			r.Route("/tags", func(r chi.Router) {
+				r.Get("/", tagHandler.List)
+				r.Post("/", tagHandler.Create)
+				r.Get("/suggestions", tagHandler.Suggestions)
+				r.Get("/{id}", tagHandler.GetByID)
+				r.Put("/{id}", tagHandler.Update)
+				r.Delete("/{id}", tagHandler.Delete)
+			})
+
+			// Flavor wheel
+			r.Route("/flavors", func(r chi.Router) {
+				r.Get("/wheel", flavorHandler.Wheel)
+				r.Get("/profiles", flavorHandler.Profiles)
+			})
+
+			// Statistics
+			r.Route("/stats", func(r chi.Router) {
+				r.Get("/brews", statsHandler.Series)
+				r.Get("/correlations", statsHandler.Correlations)
+			})
+
+			// Search
+			r.Get("/search", searchHandler.Search)
+
+			// Share links
+			r.Route("/share-links", func(r chi.Router) {
+				r.Get("/", shareLinkHandler.ListLinks)
+				r.Post("/", shareLinkHandler.CreateLink)
+				r.Get("/{id}", shareLinkHandler.GetLink)
+				r.Put("/{id}", shareLinkHandler.UpdateLink)
+				r.Delete("/{id}", shareLinkHandler.RevokeLink)
+				r.Get("/{id}/analytics", shareLinkHandler.Analytics)
+			})
+
+			// Default share link
 			r.Get("/share-link", shareLinkHandler.GetShareLink)
 			r.Post("/share-link", shareLinkHandler.CreateShareLink)
 			r.Delete("/share-link", shareLinkHandler.RevokeShareLink)
@@ -184,6 +310,7 @@ func main() {
 	<-quit
 
 	log.Println("shutting down server...")
+	stopJobs()
 	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
@@ -9,6 +9,7 @@ require (
 	github.com/golang-migrate/migrate/v4 v4.19.1
 	github.com/jackc/pgx/v5 v5.8.0
 	golang.org/x/crypto v0.45.0
+	golang.org/x/image v0.33.0
 )
 
 require (
@@ -1,14 +1,21 @@
 package api
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+)
 
 const (
-	CodeValidationError = "VALIDATION_ERROR"
-	CodeNotFound        = "NOT_FOUND"
-	CodeUnauthorized    = "UNAUTHORIZED"
-	CodeForbidden       = "FORBIDDEN"
-	CodeConflict        = "CONFLICT"
-	CodeInternalError   = "INTERNAL_ERROR"
+	CodeValidationError  = "VALIDATION_ERROR"
+	CodeNotFound         = "NOT_FOUND"
+	CodeUnauthorized     = "UNAUTHORIZED"
+	CodeForbidden        = "FORBIDDEN"
+	CodeConflict         = "CONFLICT"
+	CodeInvalidReference = "INVALID_REFERENCE"
+	CodeRangeTooLarge    = "RANGE_TOO_LARGE"
+	CodeInternalError    = "INTERNAL_ERROR"
 )
 
 type ErrorResponse struct {
@@ -72,6 +79,30 @@ func ConflictError(w http.ResponseWriter, message string) {
 	})
 }
 
+// InvalidReferenceError reports request fields that point at records the user
+// does not own or that no longer exist.
+func InvalidReferenceError(w http.ResponseWriter, details []FieldError) {
+	WriteJSON(w, http.StatusUnprocessableEntity, ErrorResponse{
+		Error: ErrorBody{
+			Code:    CodeInvalidReference,
+			Message: "Referenced record not found",
+			Details: details,
+		},
+	})
+}
+
+// RangeTooLargeError reports a well-formed date range that spans more than
+// the endpoint will compute.
+func RangeTooLargeError(w http.ResponseWriter, details []FieldError) {
+	WriteJSON(w, http.StatusUnprocessableEntity, ErrorResponse{
+		Error: ErrorBody{
+			Code:    CodeRangeTooLarge,
+			Message: "Requested range is too large",
+			Details: details,
+		},
+	})
+}
+
 func InternalError(w http.ResponseWriter) {
 	WriteJSON(w, http.StatusInternalServerError, ErrorResponse{
 		Error: ErrorBody{
@@ -80,3 +111,32 @@ func InternalError(w http.ResponseWriter) {
 		},
 	})
 }
+
+// DomainError writes the response for a domainerr error and reports whether it
+// did. Any other error is left to the caller, which logs it and responds with
+// InternalError.
+func DomainError(w http.ResponseWriter, err error) bool {
+	var de *domainerr.Error
+	if !errors.As(err, &de) {
+		return false
+	}
+
+	var details []FieldError
+	if de.Field != "" {
+		details = []FieldError{{Field: de.Field, Message: de.Message}}
+	}
+
+	switch {
+	case errors.Is(de, domainerr.ErrNotFound):
+		NotFoundError(w, de.Message)
+	case errors.Is(de, domainerr.ErrConflict):
+		ConflictError(w, de.Message)
+	case errors.Is(de, domainerr.ErrInvalidReference):
+		InvalidReferenceError(w, details)
+	case errors.Is(de, domainerr.ErrInvalid):
+		ValidationError(w, details)
+	default:
+		return false
+	}
+	return true
+}
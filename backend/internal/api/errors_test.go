@@ -2,8 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
 )
 
 func TestValidationError(t *testing.T) {
@@ -84,6 +88,25 @@ func TestConflictError(t *testing.T) {
 	}
 }
 
+func TestInvalidReferenceError(t *testing.T) {
+	w := httptest.NewRecorder()
+	InvalidReferenceError(w, []FieldError{{Field: "dripper_id", Message: "Dripper not found"}})
+
+	if w.Code != 422 {
+		t.Errorf("expected status 422, got %d", w.Code)
+	}
+
+	var result ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &result)
+
+	if result.Error.Code != CodeInvalidReference {
+		t.Errorf("expected code INVALID_REFERENCE, got %s", result.Error.Code)
+	}
+	if len(result.Error.Details) != 1 || result.Error.Details[0].Field != "dripper_id" {
+		t.Errorf("expected dripper_id detail, got %v", result.Error.Details)
+	}
+}
+
 func TestInternalError(t *testing.T) {
 	w := httptest.NewRecorder()
 	InternalError(w)
@@ -115,3 +138,50 @@ func TestForbiddenError(t *testing.T) {
 		t.Errorf("expected code FORBIDDEN, got %s", result.Error.Code)
 	}
 }
+
+func TestDomainError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantField  string
+	}{
+		{"not found", domainerr.NotFound("Brew not found"), 404, CodeNotFound, ""},
+		{"conflict", domainerr.Conflict("Already exists"), 409, CodeConflict, ""},
+		{"invalid reference", domainerr.InvalidReference("dripper_id", "Dripper not found"), 422, CodeInvalidReference, "dripper_id"},
+		{"invalid", domainerr.Invalid("tds", "Value out of range"), 400, CodeValidationError, "tds"},
+		{"wrapped", fmt.Errorf("creating brew: %w", domainerr.NotFound("Coffee not found")), 404, CodeNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if !DomainError(w, tt.err) {
+				t.Fatal("expected DomainError to handle the error")
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			var result ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &result)
+			if result.Error.Code != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, result.Error.Code)
+			}
+			if tt.wantField != "" && (len(result.Error.Details) != 1 || result.Error.Details[0].Field != tt.wantField) {
+				t.Errorf("expected field %s, got %v", tt.wantField, result.Error.Details)
+			}
+		})
+	}
+}
+
+func TestDomainError_Unhandled(t *testing.T) {
+	w := httptest.NewRecorder()
+	if DomainError(w, errors.New("database error")) {
+		t.Error("expected plain errors to be left to the caller")
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected nothing written, got %s", w.Body.String())
+	}
+}
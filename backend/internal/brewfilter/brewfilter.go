@@ -0,0 +1,77 @@
+// Package brewfilter holds the filters that select brews for the brew list,
+// the control chart and the stats endpoints, and turns them into SQL, so the
+// endpoints agree on what each filter means.
+package brewfilter
+
+import (
+	"fmt"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+)
+
+// Filters select a user's brews. Empty strings and nil pointers don't filter.
+type Filters struct {
+	CoffeeID       string
+	DripperID      string
+	FilterPaperID  string
+	WaterProfileID string
+	Method         string
+	ScoreGTE       *int
+	ScoreLTE       *int
+	HasTDS         *bool
+	DateFrom       *string
+	DateTo         *string
+	Tags           []string
+	TagMatch       string
+}
+
+// Conditions turns filters into WHERE conditions on brews b, numbering
+// placeholders after the user ID at $1. It returns the conditions, their
+// arguments and the next placeholder number. IDs are compared as text so a
+// malformed UUID matches nothing instead of failing the cast.
+func Conditions(userID string, f Filters) ([]string, []interface{}, int) {
+	conditions := []string{"b.user_id = $1"}
+	args := []interface{}{userID}
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.CoffeeID != "" {
+		add("b.coffee_id::text = $%d", f.CoffeeID)
+	}
+	if f.DripperID != "" {
+		add("b.dripper_id::text = $%d", f.DripperID)
+	}
+	if f.FilterPaperID != "" {
+		add("b.filter_paper_id::text = $%d", f.FilterPaperID)
+	}
+	if f.WaterProfileID != "" {
+		add("b.water_profile_id::text = $%d", f.WaterProfileID)
+	}
+	if f.Method != "" {
+		add("b.method = $%d", f.Method)
+	}
+	if f.ScoreGTE != nil {
+		add("b.overall_score >= $%d", *f.ScoreGTE)
+	}
+	if f.ScoreLTE != nil {
+		add("b.overall_score <= $%d", *f.ScoreLTE)
+	}
+	if f.HasTDS != nil && *f.HasTDS {
+		conditions = append(conditions, "b.tds IS NOT NULL")
+	}
+	if f.DateFrom != nil {
+		add("b.brew_date >= $%d", *f.DateFrom)
+	}
+	if f.DateTo != nil {
+		add("b.brew_date <= $%d", *f.DateTo)
+	}
+	if len(f.Tags) > 0 {
+		args = append(args, f.Tags)
+		conditions = append(conditions, tags.Brews.FilterSQL("b.id", f.TagMatch, len(args)))
+	}
+
+	return conditions, args, len(args) + 1
+}
@@ -0,0 +1,47 @@
+package brewfilter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+)
+
+func TestConditions(t *testing.T) {
+	score := 7
+	from := "2026-01-01"
+	conditions, args, next := Conditions("user-1", Filters{
+		CoffeeID: "c-1",
+		ScoreGTE: &score,
+		DateFrom: &from,
+		Tags:     []string{"washed", "kenya"},
+		TagMatch: tags.MatchAll,
+	})
+
+	want := []string{
+		"b.user_id = $1",
+		"b.coffee_id::text = $2",
+		"b.overall_score >= $3",
+		"b.brew_date >= $4",
+	}
+	if !reflect.DeepEqual(conditions[:4], want) {
+		t.Errorf("conditions = %q, want prefix %q", conditions, want)
+	}
+	if len(conditions) != 5 || !strings.Contains(conditions[4], "brew_tags") || !strings.Contains(conditions[4], "$5::text[]") {
+		t.Errorf("expected a tag condition on $5, got %q", conditions)
+	}
+	if len(args) != 5 || !reflect.DeepEqual(args[4], []string{"washed", "kenya"}) {
+		t.Errorf("unexpected args %v", args)
+	}
+	if next != 6 {
+		t.Errorf("next placeholder = %d, want 6", next)
+	}
+}
+
+func TestConditions_NoFilters(t *testing.T) {
+	conditions, args, next := Conditions("user-1", Filters{})
+	if len(conditions) != 1 || len(args) != 1 || next != 2 {
+		t.Errorf("expected only the user condition, got %q %v %d", conditions, args, next)
+	}
+}
@@ -14,6 +14,11 @@ type Config struct {
 	RefreshTokenTTL int
 	Environment     string
 	BaseURL         string
+
+	// AutoArchiveInterval is how often, in seconds, auto-archive rules run.
+	AutoArchiveInterval int
+	// ShareViewRetentionDays is how long raw share link views are kept.
+	ShareViewRetentionDays int
 }
 
 func Load() (*Config, error) {
@@ -50,6 +55,30 @@ func Load() (*Config, error) {
 		refreshTTL = parsed
 	}
 
+	autoArchiveInterval := 3600
+	if v := os.Getenv("AUTO_ARCHIVE_INTERVAL"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTO_ARCHIVE_INTERVAL: %w", err)
+		}
+		if parsed <= 0 {
+			return nil, fmt.Errorf("invalid AUTO_ARCHIVE_INTERVAL: must be positive")
+		}
+		autoArchiveInterval = parsed
+	}
+
+	shareViewRetention := 90
+	if v := os.Getenv("SHARE_VIEW_RETENTION_DAYS"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SHARE_VIEW_RETENTION_DAYS: %w", err)
+		}
+		if parsed <= 0 {
+			return nil, fmt.Errorf("invalid SHARE_VIEW_RETENTION_DAYS: must be positive")
+		}
+		shareViewRetention = parsed
+	}
+
 	env := os.Getenv("ENVIRONMENT")
 	if env == "" {
 		env = "development"
@@ -68,5 +97,8 @@ func Load() (*Config, error) {
 		RefreshTokenTTL: refreshTTL,
 		Environment:     env,
 		BaseURL:         baseURL,
+
+		AutoArchiveInterval:    autoArchiveInterval,
+		ShareViewRetentionDays: shareViewRetention,
 	}, nil
 }
@@ -37,6 +37,8 @@ func TestLoad_Defaults(t *testing.T) {
 	os.Unsetenv("ACCESS_TOKEN_TTL")
 	os.Unsetenv("REFRESH_TOKEN_TTL")
 	os.Unsetenv("ENVIRONMENT")
+	os.Unsetenv("AUTO_ARCHIVE_INTERVAL")
+	os.Unsetenv("SHARE_VIEW_RETENTION_DAYS")
 
 	cfg, err := Load()
 	if err != nil {
@@ -55,6 +57,12 @@ func TestLoad_Defaults(t *testing.T) {
 	if cfg.Environment != "development" {
 		t.Errorf("expected environment development, got %s", cfg.Environment)
 	}
+	if cfg.AutoArchiveInterval != 3600 {
+		t.Errorf("expected auto-archive interval 3600, got %d", cfg.AutoArchiveInterval)
+	}
+	if cfg.ShareViewRetentionDays != 90 {
+		t.Errorf("expected share view retention 90, got %d", cfg.ShareViewRetentionDays)
+	}
 }
 
 func TestLoad_CustomValues(t *testing.T) {
@@ -113,3 +121,33 @@ func TestLoad_InvalidTTL(t *testing.T) {
 		t.Error("expected error for invalid ACCESS_TOKEN_TTL")
 	}
 }
+
+func TestLoad_InvalidAutoArchiveInterval(t *testing.T) {
+	os.Setenv("DATABASE_URL", "postgres://localhost/test")
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("DATABASE_URL")
+	defer os.Unsetenv("JWT_SECRET")
+
+	for _, v := range []string{"hourly", "0"} {
+		os.Setenv("AUTO_ARCHIVE_INTERVAL", v)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for AUTO_ARCHIVE_INTERVAL=%q", v)
+		}
+	}
+	os.Unsetenv("AUTO_ARCHIVE_INTERVAL")
+}
+
+func TestLoad_InvalidShareViewRetention(t *testing.T) {
+	os.Setenv("DATABASE_URL", "postgres://localhost/test")
+	os.Setenv("JWT_SECRET", "test-secret")
+	defer os.Unsetenv("DATABASE_URL")
+	defer os.Unsetenv("JWT_SECRET")
+
+	for _, v := range []string{"forever", "-1"} {
+		os.Setenv("SHARE_VIEW_RETENTION_DAYS", v)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for SHARE_VIEW_RETENTION_DAYS=%q", v)
+		}
+	}
+	os.Unsetenv("SHARE_VIEW_RETENTION_DAYS")
+}
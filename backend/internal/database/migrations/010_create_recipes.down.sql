@@ -0,0 +1,3 @@
+ALTER TABLE brews DROP COLUMN recipe_id;
+DROP TABLE IF EXISTS recipe_pours;
+DROP TABLE IF EXISTS recipes;
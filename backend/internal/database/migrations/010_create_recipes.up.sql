@@ -0,0 +1,33 @@
+CREATE TABLE recipes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    coffee_weight DECIMAL(5,2),
+    ratio DECIMAL(4,1),
+    grind_size DECIMAL(4,1),
+    water_temperature DECIMAL(4,1),
+    filter_paper_id UUID REFERENCES filter_papers(id),
+    dripper_id UUID REFERENCES drippers(id),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_recipes_user_id ON recipes(user_id);
+CREATE UNIQUE INDEX idx_recipes_user_name ON recipes(user_id, name);
+
+CREATE TABLE recipe_pours (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    pour_number INTEGER NOT NULL,
+    water_amount DECIMAL(6,1),
+    pour_style VARCHAR(50),
+    wait_time INTEGER,
+    UNIQUE(recipe_id, pour_number)
+);
+
+CREATE INDEX idx_recipe_pours_recipe_id ON recipe_pours(recipe_id);
+
+ALTER TABLE brews ADD COLUMN recipe_id UUID REFERENCES recipes(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_brews_recipe_id ON brews(recipe_id);
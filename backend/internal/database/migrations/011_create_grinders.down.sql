@@ -0,0 +1,4 @@
+ALTER TABLE recipes ALTER COLUMN grind_size TYPE DECIMAL(4,1);
+ALTER TABLE brews ALTER COLUMN grind_size TYPE DECIMAL(4,1);
+ALTER TABLE brews DROP COLUMN grinder_id;
+DROP TABLE IF EXISTS grinders;
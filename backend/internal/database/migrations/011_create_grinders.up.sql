@@ -0,0 +1,29 @@
+CREATE TABLE grinders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    brand VARCHAR(100),
+    notes TEXT,
+    scale_min DECIMAL(6,2) NOT NULL,
+    scale_max DECIMAL(6,2) NOT NULL,
+    scale_step DECIMAL(6,3),
+    stepless BOOLEAN NOT NULL DEFAULT FALSE,
+    micron_min INTEGER,
+    micron_max INTEGER,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (scale_max > scale_min),
+    CHECK (stepless OR scale_step > 0),
+    CHECK ((micron_min IS NULL) = (micron_max IS NULL))
+);
+
+CREATE INDEX idx_grinders_user_id ON grinders(user_id);
+CREATE UNIQUE INDEX idx_grinders_user_name ON grinders(user_id, name) WHERE deleted_at IS NULL;
+CREATE INDEX idx_grinders_deleted_at ON grinders(deleted_at) WHERE deleted_at IS NULL;
+
+ALTER TABLE brews ADD COLUMN grinder_id UUID REFERENCES grinders(id);
+
+-- Fractional steps (e.g. thirds of a number) need more precision than one decimal place
+ALTER TABLE brews ALTER COLUMN grind_size TYPE DECIMAL(7,3);
+ALTER TABLE recipes ALTER COLUMN grind_size TYPE DECIMAL(7,3);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS grinder_calibration_points;
@@ -0,0 +1,20 @@
+-- A calibration point either maps a setting to a measured particle size or
+-- records the setting on another grinder that produced an equivalent brew.
+CREATE TABLE grinder_calibration_points (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    grinder_id UUID NOT NULL REFERENCES grinders(id) ON DELETE CASCADE,
+    setting DECIMAL(7,3) NOT NULL,
+    microns INTEGER,
+    paired_grinder_id UUID REFERENCES grinders(id) ON DELETE CASCADE,
+    paired_setting DECIMAL(7,3),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CHECK (microns IS NULL OR microns > 0),
+    CHECK ((paired_grinder_id IS NULL) = (paired_setting IS NULL)),
+    CHECK ((microns IS NULL) <> (paired_grinder_id IS NULL)),
+    CHECK (paired_grinder_id <> grinder_id)
+);
+
+CREATE INDEX idx_grinder_calibration_points_grinder_id ON grinder_calibration_points(grinder_id);
+CREATE INDEX idx_grinder_calibration_points_paired_grinder_id ON grinder_calibration_points(paired_grinder_id)
+    WHERE paired_grinder_id IS NOT NULL;
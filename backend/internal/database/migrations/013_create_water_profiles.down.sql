@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_brews_water_profile_id;
+ALTER TABLE brews DROP COLUMN water_profile_id;
+DROP TABLE IF EXISTS water_profile_additions;
+DROP TABLE IF EXISTS water_profiles;
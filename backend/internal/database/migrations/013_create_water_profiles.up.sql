@@ -0,0 +1,34 @@
+CREATE TABLE water_profiles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    notes TEXT,
+    gh DECIMAL(6,1),
+    kh DECIMAL(6,1),
+    tds DECIMAL(6,1),
+    calcium DECIMAL(6,1),
+    magnesium DECIMAL(6,1),
+    sodium DECIMAL(6,1),
+    bicarbonate DECIMAL(6,1),
+    base_water VARCHAR(100),
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_water_profiles_user_id ON water_profiles(user_id);
+CREATE UNIQUE INDEX idx_water_profiles_user_name ON water_profiles(user_id, name) WHERE deleted_at IS NULL;
+CREATE INDEX idx_water_profiles_deleted_at ON water_profiles(deleted_at) WHERE deleted_at IS NULL;
+
+CREATE TABLE water_profile_additions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    water_profile_id UUID NOT NULL REFERENCES water_profiles(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    concentrate VARCHAR(100) NOT NULL,
+    amount DECIMAL(7,2) NOT NULL CHECK (amount > 0),
+    unit VARCHAR(20) NOT NULL CHECK (unit IN ('ml_per_l', 'g_per_l', 'drops_per_l')),
+    UNIQUE(water_profile_id, position)
+);
+
+ALTER TABLE brews ADD COLUMN water_profile_id UUID REFERENCES water_profiles(id);
+CREATE INDEX idx_brews_water_profile_id ON brews(water_profile_id);
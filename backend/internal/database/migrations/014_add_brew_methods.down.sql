@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_brews_user_method;
+ALTER TABLE brews DROP COLUMN dilution;
+ALTER TABLE brews DROP COLUMN steep_hours;
+ALTER TABLE brews DROP COLUMN plunge;
+ALTER TABLE brews DROP COLUMN agitation;
+ALTER TABLE brews DROP COLUMN steep_time;
+ALTER TABLE brews DROP COLUMN preinfusion_time;
+ALTER TABLE brews DROP COLUMN pressure;
+ALTER TABLE brews DROP COLUMN yield_weight;
+ALTER TABLE brews DROP COLUMN method;
@@ -0,0 +1,18 @@
+ALTER TABLE brews ADD COLUMN method VARCHAR(20) NOT NULL DEFAULT 'pour_over'
+    CHECK (method IN ('pour_over', 'immersion', 'espresso', 'cold_brew'));
+
+-- Espresso
+ALTER TABLE brews ADD COLUMN yield_weight DECIMAL(6,2);
+ALTER TABLE brews ADD COLUMN pressure DECIMAL(4,1);
+ALTER TABLE brews ADD COLUMN preinfusion_time INTEGER;
+
+-- Immersion
+ALTER TABLE brews ADD COLUMN steep_time INTEGER;
+ALTER TABLE brews ADD COLUMN agitation VARCHAR(20) CHECK (agitation IN ('none', 'stir', 'swirl'));
+ALTER TABLE brews ADD COLUMN plunge BOOLEAN;
+
+-- Cold brew
+ALTER TABLE brews ADD COLUMN steep_hours DECIMAL(4,1);
+ALTER TABLE brews ADD COLUMN dilution DECIMAL(4,2);
+
+CREATE INDEX idx_brews_user_method ON brews(user_id, method);
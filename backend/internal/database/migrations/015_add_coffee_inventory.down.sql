@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS coffee_inventory_adjustments;
+ALTER TABLE coffees DROP COLUMN remaining_grams;
+ALTER TABLE coffees DROP COLUMN currency;
+ALTER TABLE coffees DROP COLUMN price;
+ALTER TABLE coffees DROP COLUMN purchase_date;
+ALTER TABLE coffees DROP COLUMN bag_weight;
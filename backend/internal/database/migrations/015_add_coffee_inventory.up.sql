@@ -0,0 +1,21 @@
+ALTER TABLE coffees ADD COLUMN bag_weight DECIMAL(7,2) CHECK (bag_weight > 0);
+ALTER TABLE coffees ADD COLUMN purchase_date DATE;
+ALTER TABLE coffees ADD COLUMN price DECIMAL(10,2) CHECK (price >= 0);
+ALTER TABLE coffees ADD COLUMN currency CHAR(3);
+
+-- Running balance of the bag. Brews debit their coffee_weight and adjustments
+-- apply their signed amount in the same transaction as the change. NULL when
+-- the bag weight is unknown.
+ALTER TABLE coffees ADD COLUMN remaining_grams DECIMAL(8,2);
+
+CREATE TABLE coffee_inventory_adjustments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    coffee_id UUID NOT NULL REFERENCES coffees(id) ON DELETE CASCADE,
+    amount DECIMAL(7,2) NOT NULL CHECK (amount <> 0),
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('spill', 'gift', 'correction', 'other')),
+    notes TEXT,
+    remaining_after DECIMAL(8,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_coffee_inventory_adjustments_coffee_id ON coffee_inventory_adjustments(coffee_id);
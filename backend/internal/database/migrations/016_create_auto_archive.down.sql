@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS coffee_archive_events;
+DROP TABLE IF EXISTS auto_archive_rules;
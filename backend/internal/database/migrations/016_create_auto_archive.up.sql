@@ -0,0 +1,22 @@
+-- One rule per user. A NULL condition is off; a row with both off is deleted.
+CREATE TABLE auto_archive_rules (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    remaining_below_grams DECIMAL(7,2) CHECK (remaining_below_grams > 0),
+    idle_days INTEGER CHECK (idle_days > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Audit trail of automatic archives. reversed_at is set when the user
+-- unarchives the coffee.
+CREATE TABLE coffee_archive_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    coffee_id UUID NOT NULL REFERENCES coffees(id) ON DELETE CASCADE,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('low_remaining', 'idle')),
+    remaining_grams DECIMAL(8,2),
+    last_brewed DATE,
+    archived_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    reversed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_coffee_archive_events_coffee_id ON coffee_archive_events(coffee_id);
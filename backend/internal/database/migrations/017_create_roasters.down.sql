@@ -0,0 +1,13 @@
+ALTER TABLE coffees ADD COLUMN roaster VARCHAR(255);
+
+UPDATE coffees c SET roaster = r.name
+FROM roasters r
+WHERE r.id = c.roaster_id;
+
+ALTER TABLE coffees ALTER COLUMN roaster SET NOT NULL;
+CREATE INDEX idx_coffees_roaster ON coffees(roaster);
+
+DROP INDEX IF EXISTS idx_coffees_roaster_id;
+ALTER TABLE coffees DROP COLUMN roaster_id;
+
+DROP TABLE IF EXISTS roasters;
@@ -0,0 +1,33 @@
+CREATE TABLE roasters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    country VARCHAR(100),
+    website VARCHAR(500),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_roasters_user_id ON roasters(user_id);
+CREATE UNIQUE INDEX idx_roasters_user_name ON roasters(user_id, LOWER(name));
+
+-- One roaster per distinct spelling, ignoring case and surrounding spaces.
+-- The earliest coffee's spelling wins.
+INSERT INTO roasters (user_id, name)
+SELECT DISTINCT ON (user_id, LOWER(TRIM(roaster))) user_id, TRIM(roaster)
+FROM coffees
+ORDER BY user_id, LOWER(TRIM(roaster)), created_at;
+
+ALTER TABLE coffees ADD COLUMN roaster_id UUID REFERENCES roasters(id);
+
+UPDATE coffees c SET roaster_id = r.id
+FROM roasters r
+WHERE r.user_id = c.user_id AND LOWER(r.name) = LOWER(TRIM(c.roaster));
+
+ALTER TABLE coffees ALTER COLUMN roaster_id SET NOT NULL;
+
+DROP INDEX idx_coffees_roaster;
+ALTER TABLE coffees DROP COLUMN roaster;
+
+CREATE INDEX idx_coffees_roaster_id ON coffees(roaster_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS brew_targets;
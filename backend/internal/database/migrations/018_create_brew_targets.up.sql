@@ -0,0 +1,11 @@
+-- Per-user ideal box on the brewing control chart. A NULL bound falls back to
+-- the SCA default; a row with every bound NULL is deleted.
+CREATE TABLE brew_targets (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    ey_min DECIMAL(4,2),
+    ey_max DECIMAL(4,2),
+    tds_min DECIMAL(4,2),
+    tds_max DECIMAL(4,2),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
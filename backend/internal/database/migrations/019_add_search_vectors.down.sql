@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS roasters_reindex_coffees ON roasters;
+DROP FUNCTION IF EXISTS roasters_reindex_coffees();
+
+DROP TRIGGER IF EXISTS coffees_search_vector ON coffees;
+DROP FUNCTION IF EXISTS coffees_search_vector_update();
+
+DROP INDEX IF EXISTS idx_coffees_search_vector;
+ALTER TABLE coffees DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS idx_brews_search_vector;
+ALTER TABLE brews DROP COLUMN IF EXISTS search_vector;
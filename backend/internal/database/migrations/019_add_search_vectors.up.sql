@@ -0,0 +1,46 @@
+-- Brew notes, weighted by how much they say about the cup.
+ALTER TABLE brews ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(overall_notes, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(improvement_notes, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(technique_notes, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_brews_search_vector ON brews USING GIN (search_vector);
+
+-- The roaster name lives on roasters, which a generated column can't read,
+-- so a trigger maintains the coffee vector.
+ALTER TABLE coffees ADD COLUMN search_vector tsvector;
+
+CREATE FUNCTION coffees_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce((SELECT name FROM roasters WHERE id = NEW.roaster_id), '')), 'A') ||
+        setweight(to_tsvector('english', concat_ws(' ', NEW.country, NEW.region, NEW.farm, NEW.varietal, NEW.process)), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.tasting_notes, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(NEW.notes, '')), 'D');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER coffees_search_vector
+    BEFORE INSERT OR UPDATE OF name, roaster_id, country, region, farm, varietal, process, tasting_notes, notes
+    ON coffees
+    FOR EACH ROW EXECUTE FUNCTION coffees_search_vector_update();
+
+-- Renaming a roaster re-indexes its coffees.
+CREATE FUNCTION roasters_reindex_coffees() RETURNS trigger AS $$
+BEGIN
+    UPDATE coffees SET roaster_id = roaster_id WHERE roaster_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER roasters_reindex_coffees
+    AFTER UPDATE OF name ON roasters
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION roasters_reindex_coffees();
+
+UPDATE coffees SET roaster_id = roaster_id;
+
+CREATE INDEX idx_coffees_search_vector ON coffees USING GIN (search_vector);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS brew_tags;
+DROP TABLE IF EXISTS coffee_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,42 @@
+CREATE TABLE tags (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_tags_user_id ON tags(user_id);
+CREATE UNIQUE INDEX idx_tags_user_name ON tags(user_id, LOWER(name));
+
+CREATE TABLE coffee_tags (
+    coffee_id UUID NOT NULL REFERENCES coffees(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (coffee_id, tag_id)
+);
+
+CREATE INDEX idx_coffee_tags_tag_id ON coffee_tags(tag_id);
+
+CREATE TABLE brew_tags (
+    brew_id UUID NOT NULL REFERENCES brews(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (brew_id, tag_id)
+);
+
+CREATE INDEX idx_brew_tags_tag_id ON brew_tags(tag_id);
+
+-- One tag per distinct comma-separated tasting note, ignoring case and
+-- surrounding spaces. The earliest coffee's spelling wins. Notes too long
+-- for a tag are left as text only.
+INSERT INTO tags (user_id, name)
+SELECT DISTINCT ON (c.user_id, LOWER(TRIM(n.note))) c.user_id, TRIM(n.note)
+FROM coffees c
+CROSS JOIN LATERAL unnest(string_to_array(c.tasting_notes, ',')) AS n(note)
+WHERE TRIM(n.note) <> '' AND LENGTH(TRIM(n.note)) <= 100
+ORDER BY c.user_id, LOWER(TRIM(n.note)), c.created_at;
+
+INSERT INTO coffee_tags (coffee_id, tag_id)
+SELECT DISTINCT c.id, t.id
+FROM coffees c
+CROSS JOIN LATERAL unnest(string_to_array(c.tasting_notes, ',')) AS n(note)
+JOIN tags t ON t.user_id = c.user_id AND LOWER(t.name) = LOWER(TRIM(n.note));
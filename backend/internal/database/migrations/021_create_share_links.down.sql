@@ -0,0 +1,19 @@
+ALTER TABLE users ADD COLUMN share_token VARCHAR(64);
+ALTER TABLE users ADD COLUMN share_token_created_at TIMESTAMP WITH TIME ZONE;
+
+CREATE UNIQUE INDEX idx_users_share_token ON users(share_token) WHERE share_token IS NOT NULL;
+
+-- Only one token fits on users: keep each user's newest active unscoped link.
+UPDATE users u
+SET share_token = sl.token, share_token_created_at = sl.created_at
+FROM (
+    SELECT DISTINCT ON (user_id) user_id, token, created_at
+    FROM share_links
+    WHERE scope = 'all' AND revoked_at IS NULL
+      AND (expires_at IS NULL OR expires_at > NOW())
+    ORDER BY user_id, created_at DESC
+) sl
+WHERE u.id = sl.user_id;
+
+DROP TABLE IF EXISTS share_link_coffees;
+DROP TABLE IF EXISTS share_links;
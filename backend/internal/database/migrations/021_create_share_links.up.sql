@@ -0,0 +1,37 @@
+CREATE TABLE share_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(64) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    scope VARCHAR(20) NOT NULL DEFAULT 'all' CHECK (scope IN ('all', 'coffees', 'filter')),
+    filter_roaster_id UUID REFERENCES roasters(id),
+    filter_country VARCHAR(100),
+    filter_process VARCHAR(100),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_share_links_token ON share_links(token);
+CREATE INDEX idx_share_links_user_id ON share_links(user_id);
+
+CREATE TABLE share_link_coffees (
+    share_link_id UUID NOT NULL REFERENCES share_links(id) ON DELETE CASCADE,
+    coffee_id UUID NOT NULL REFERENCES coffees(id) ON DELETE CASCADE,
+    PRIMARY KEY (share_link_id, coffee_id)
+);
+
+CREATE INDEX idx_share_link_coffees_coffee_id ON share_link_coffees(coffee_id);
+
+-- Existing tokens become unscoped links that never expire, so shared URLs
+-- keep working.
+INSERT INTO share_links (user_id, token, label, created_at, updated_at)
+SELECT id, share_token, 'Share link',
+       COALESCE(share_token_created_at, NOW()), COALESCE(share_token_created_at, NOW())
+FROM users
+WHERE share_token IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_users_share_token;
+ALTER TABLE users DROP COLUMN share_token_created_at;
+ALTER TABLE users DROP COLUMN share_token;
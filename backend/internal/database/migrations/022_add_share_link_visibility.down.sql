@@ -0,0 +1,7 @@
+ALTER TABLE share_links
+    DROP COLUMN show_recipe,
+    DROP COLUMN show_score,
+    DROP COLUMN show_sensory,
+    DROP COLUMN show_tasting_notes,
+    DROP COLUMN show_roast_date,
+    DROP COLUMN show_origin;
@@ -0,0 +1,8 @@
+-- The defaults match what share pages showed before links had a policy.
+ALTER TABLE share_links
+    ADD COLUMN show_origin BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN show_roast_date BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN show_tasting_notes BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN show_sensory BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN show_score BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN show_recipe BOOLEAN NOT NULL DEFAULT FALSE;
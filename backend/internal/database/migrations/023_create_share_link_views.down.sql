@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS share_link_views;
+ALTER TABLE share_links DROP COLUMN view_count;
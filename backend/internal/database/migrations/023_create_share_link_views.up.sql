@@ -0,0 +1,14 @@
+-- view_count is the lifetime total; it survives the purge of raw views.
+ALTER TABLE share_links ADD COLUMN view_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE share_link_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    share_link_id UUID NOT NULL REFERENCES share_links(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ip_hash VARCHAR(64) NOT NULL,
+    ua_family VARCHAR(50) NOT NULL,
+    referrer VARCHAR(255)
+);
+
+CREATE INDEX idx_share_link_views_link_viewed_at ON share_link_views(share_link_id, viewed_at);
+CREATE INDEX idx_share_link_views_viewed_at ON share_link_views(viewed_at);
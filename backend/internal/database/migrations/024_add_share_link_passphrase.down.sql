@@ -0,0 +1 @@
+ALTER TABLE share_links DROP COLUMN passphrase_hash;
@@ -0,0 +1,2 @@
+-- bcrypt hash, as users.password_hash. NULL means the link is open.
+ALTER TABLE share_links ADD COLUMN passphrase_hash TEXT;
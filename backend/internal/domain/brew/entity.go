@@ -3,6 +3,8 @@ package brew
 import (
 	"math"
 	"time"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/grind"
 )
 
 type Brew struct {
@@ -15,6 +17,7 @@ type Brew struct {
 	CoffeeReferenceBrewID  *string      `json:"coffee_reference_brew_id"`
 	BrewDate         string       `json:"brew_date"`
 	DaysOffRoast     *int         `json:"days_off_roast"`
+	Method           string       `json:"method"`
 	CoffeeWeight     *float64     `json:"coffee_weight"`
 	Ratio            *float64     `json:"ratio"`
 	WaterWeight      *float64     `json:"water_weight"`
@@ -22,12 +25,18 @@ type Brew struct {
 	WaterTemperature *float64     `json:"water_temperature"`
 	FilterPaper      *FilterPaper `json:"filter_paper"`
 	Dripper          *Dripper     `json:"dripper"`
+	Grinder          *Grinder     `json:"grinder"`
+	WaterProfile     *WaterProfile `json:"water_profile"`
+	RecipeID         *string      `json:"recipe_id"`
 	Pours            []Pour       `json:"pours"`
 	TotalBrewTime    *int         `json:"total_brew_time"`
+	MethodParams
 	TechniqueNotes   *string      `json:"technique_notes"`
 	CoffeeMl         *float64     `json:"coffee_ml"`
 	TDS              *float64     `json:"tds"`
 	ExtractionYield  *float64     `json:"extraction_yield"`
+	ExtractionClass  *string      `json:"extraction_class"`
+	StrengthClass    *string      `json:"strength_class"`
 
 	AromaIntensity      *int `json:"aroma_intensity"`
 	BodyIntensity       *int `json:"body_intensity"`
@@ -39,9 +48,13 @@ type Brew struct {
 	OverallScore     *int    `json:"overall_score"`
 	OverallNotes     *string `json:"overall_notes"`
 	ImprovementNotes *string `json:"improvement_notes"`
+	Tags             []string `json:"tags"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// targets are the bounds the brew was classified against.
+	targets Targets
 }
 
 type FilterPaper struct {
@@ -56,6 +69,50 @@ type Dripper struct {
 	Brand *string `json:"brand"`
 }
 
+type Grinder struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Brand *string `json:"brand"`
+}
+
+type WaterProfile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Brew methods. Pour-over is the default for brews that don't name one.
+const (
+	MethodPourOver  = "pour_over"
+	MethodImmersion = "immersion"
+	MethodEspresso  = "espresso"
+	MethodColdBrew  = "cold_brew"
+)
+
+var validMethods = map[string]bool{
+	MethodPourOver:  true,
+	MethodImmersion: true,
+	MethodEspresso:  true,
+	MethodColdBrew:  true,
+}
+
+// MethodParams holds the parameters that only apply to one brew method. They
+// are stored alongside the core brew columns and are nil for other methods.
+type MethodParams struct {
+	// Espresso
+	YieldWeight     *float64 `json:"yield_weight"`
+	Pressure        *float64 `json:"pressure"`
+	PreinfusionTime *int     `json:"preinfusion_time"`
+
+	// Immersion
+	SteepTime *int    `json:"steep_time"`
+	Agitation *string `json:"agitation"`
+	Plunge    *bool   `json:"plunge"`
+
+	// Cold brew
+	SteepHours *float64 `json:"steep_hours"`
+	Dilution   *float64 `json:"dilution"`
+}
+
 type Pour struct {
 	PourNumber  int      `json:"pour_number"`
 	WaterAmount *float64 `json:"water_amount"`
@@ -66,14 +123,19 @@ type Pour struct {
 type CreateRequest struct {
 	CoffeeID         string  `json:"coffee_id"`
 	BrewDate         *string `json:"brew_date"`
+	Method           string   `json:"method"`
 	CoffeeWeight     *float64 `json:"coffee_weight"`
 	Ratio            *float64 `json:"ratio"`
 	GrindSize        *float64 `json:"grind_size"`
 	WaterTemperature *float64 `json:"water_temperature"`
 	FilterPaperID    *string  `json:"filter_paper_id"`
 	DripperID        *string  `json:"dripper_id"`
+	GrinderID        *string  `json:"grinder_id"`
+	WaterProfileID   *string  `json:"water_profile_id"`
+	RecipeID         *string  `json:"recipe_id"`
 	Pours            []PourRequest `json:"pours"`
 	TotalBrewTime    *int     `json:"total_brew_time"`
+	MethodParams
 	TechniqueNotes   *string  `json:"technique_notes"`
 	CoffeeMl         *float64 `json:"coffee_ml"`
 	TDS              *float64 `json:"tds"`
@@ -88,19 +150,27 @@ type CreateRequest struct {
 	OverallScore     *int    `json:"overall_score"`
 	OverallNotes     *string `json:"overall_notes"`
 	ImprovementNotes *string `json:"improvement_notes"`
+	Tags             []string `json:"tags"`
 }
 
+// UpdateRequest has the same fields as CreateRequest. A nil Tags keeps the
+// brew's current tags; an empty list clears them.
 type UpdateRequest struct {
 	CoffeeID         string  `json:"coffee_id"`
 	BrewDate         *string `json:"brew_date"`
+	Method           string   `json:"method"`
 	CoffeeWeight     *float64 `json:"coffee_weight"`
 	Ratio            *float64 `json:"ratio"`
 	GrindSize        *float64 `json:"grind_size"`
 	WaterTemperature *float64 `json:"water_temperature"`
 	FilterPaperID    *string  `json:"filter_paper_id"`
 	DripperID        *string  `json:"dripper_id"`
+	GrinderID        *string  `json:"grinder_id"`
+	WaterProfileID   *string  `json:"water_profile_id"`
+	RecipeID         *string  `json:"recipe_id"`
 	Pours            []PourRequest `json:"pours"`
 	TotalBrewTime    *int     `json:"total_brew_time"`
+	MethodParams
 	TechniqueNotes   *string  `json:"technique_notes"`
 	CoffeeMl         *float64 `json:"coffee_ml"`
 	TDS              *float64 `json:"tds"`
@@ -115,6 +185,7 @@ type UpdateRequest struct {
 	OverallScore     *int    `json:"overall_score"`
 	OverallNotes     *string `json:"overall_notes"`
 	ImprovementNotes *string `json:"improvement_notes"`
+	Tags             []string `json:"tags"`
 }
 
 type PourRequest struct {
@@ -125,27 +196,201 @@ type PourRequest struct {
 }
 
 // ComputeWaterWeight returns coffee_weight * ratio, or nil if either is missing.
-func ComputeWaterWeight(coffeeWeight, ratio *float64) *float64 {
-	if coffeeWeight == nil || ratio == nil {
+// Espresso has no water weight: its ratio is beverage out per dose in.
+func ComputeWaterWeight(b *Brew) *float64 {
+	if b.Method == MethodEspresso || b.CoffeeWeight == nil || b.Ratio == nil {
 		return nil
 	}
-	ww := *coffeeWeight * *ratio
+	ww := *b.CoffeeWeight * *b.Ratio
 	ww = math.Round(ww*100) / 100
 	return &ww
 }
 
-// ComputeExtractionYield returns (coffee_ml * tds) / coffee_weight, or nil if any is missing.
-func ComputeExtractionYield(coffeeMl, tds, coffeeWeight *float64) *float64 {
-	if coffeeMl == nil || tds == nil || coffeeWeight == nil || *coffeeWeight == 0 {
+// ComputeExtractionYield returns (beverage * tds) / coffee_weight, or nil if any
+// is missing. The beverage is coffee_ml for pour-over and the yield weight for
+// espresso. Immersion and cold brew use the brew water, since the liquid held
+// in the grounds is as strong as the cup, falling back to coffee_ml.
+func ComputeExtractionYield(b *Brew) *float64 {
+	beverage := b.CoffeeMl
+	switch b.Method {
+	case MethodEspresso:
+		beverage = b.YieldWeight
+	case MethodImmersion, MethodColdBrew:
+		if ww := ComputeWaterWeight(b); ww != nil {
+			beverage = ww
+		}
+	}
+	if beverage == nil || b.TDS == nil || b.CoffeeWeight == nil || *b.CoffeeWeight == 0 {
 		return nil
 	}
-	ey := (*coffeeMl * *tds) / *coffeeWeight
+	ey := (*beverage * *b.TDS) / *b.CoffeeWeight
 	ey = math.Round(ey*100) / 100
 	return &ey
 }
 
+// Targets is the ideal box on the brewing control chart: extraction yield and
+// TDS bounds in percent.
+type Targets struct {
+	EYMin  float64 `json:"ey_min"`
+	EYMax  float64 `json:"ey_max"`
+	TDSMin float64 `json:"tds_min"`
+	TDSMax float64 `json:"tds_max"`
+}
+
+// SCATargets is the SCA ideal box, used for any bound the user hasn't set.
+var SCATargets = Targets{
+	EYMin:  18,
+	EYMax:  22,
+	TDSMin: 1.15,
+	TDSMax: 1.35,
+}
+
+// targetBounds holds the user's stored targets. Nil bounds are unset.
+type targetBounds struct {
+	EYMin, EYMax, TDSMin, TDSMax *float64
+}
+
+// resolve fills unset bounds from SCATargets.
+func (t targetBounds) resolve() Targets {
+	r := SCATargets
+	if t.EYMin != nil {
+		r.EYMin = *t.EYMin
+	}
+	if t.EYMax != nil {
+		r.EYMax = *t.EYMax
+	}
+	if t.TDSMin != nil {
+		r.TDSMin = *t.TDSMin
+	}
+	if t.TDSMax != nil {
+		r.TDSMax = *t.TDSMax
+	}
+	return r
+}
+
+// Control chart classes. Bounds count as ideal.
+const (
+	ExtractionUnder = "under"
+	ExtractionIdeal = "ideal"
+	ExtractionOver  = "over"
+
+	StrengthWeak   = "weak"
+	StrengthIdeal  = "ideal"
+	StrengthStrong = "strong"
+)
+
+// Classify places the brew on the control chart against t. Extraction is
+// classified for every method with a yield. Strength is only classified for
+// pour-over and immersion, since the TDS targets are for filter coffee.
+func (b *Brew) Classify(t Targets) {
+	b.targets = t
+	b.ExtractionClass = nil
+	b.StrengthClass = nil
+
+	if ey := b.ExtractionYield; ey != nil {
+		class := ExtractionIdeal
+		if *ey < t.EYMin {
+			class = ExtractionUnder
+		} else if *ey > t.EYMax {
+			class = ExtractionOver
+		}
+		b.ExtractionClass = &class
+	}
+
+	if tds := b.TDS; tds != nil && judgesStrength(b.Method) {
+		class := StrengthIdeal
+		if *tds < t.TDSMin {
+			class = StrengthWeak
+		} else if *tds > t.TDSMax {
+			class = StrengthStrong
+		}
+		b.StrengthClass = &class
+	}
+}
+
+// ControlChartPoint places one brew on the brewing control chart.
+type ControlChartPoint struct {
+	BrewID          string  `json:"brew_id"`
+	CoffeeID        string  `json:"coffee_id"`
+	CoffeeName      string  `json:"coffee_name"`
+	BrewDate        string  `json:"brew_date"`
+	Method          string  `json:"method"`
+	ExtractionYield float64 `json:"extraction_yield"`
+	TDS             float64 `json:"tds"`
+	OverallScore    *int    `json:"overall_score"`
+	ExtractionClass string  `json:"extraction_class"`
+	StrengthClass   *string `json:"strength_class"`
+}
+
+// ControlChartResponse holds the brews with an extraction yield, oldest
+// first, with the SCA ideal box and the user's own targets.
+type ControlChartResponse struct {
+	SCA     Targets             `json:"sca"`
+	Targets Targets             `json:"targets"`
+	Items   []ControlChartPoint `json:"items"`
+}
+
+// controlChartPoint returns nil for brews without an extraction yield.
+func controlChartPoint(b *Brew) *ControlChartPoint {
+	if b.ExtractionYield == nil || b.ExtractionClass == nil {
+		return nil
+	}
+	return &ControlChartPoint{
+		BrewID:          b.ID,
+		CoffeeID:        b.CoffeeID,
+		CoffeeName:      b.CoffeeName,
+		BrewDate:        b.BrewDate,
+		Method:          b.Method,
+		ExtractionYield: *b.ExtractionYield,
+		TDS:             *b.TDS,
+		OverallScore:    b.OverallScore,
+		ExtractionClass: *b.ExtractionClass,
+		StrengthClass:   b.StrengthClass,
+	}
+}
+
+// recipeTemplate holds the brew parameters of a saved recipe.
+type recipeTemplate struct {
+	CoffeeWeight     *float64
+	Ratio            *float64
+	GrindSize        *float64
+	WaterTemperature *float64
+	FilterPaperID    *string
+	DripperID        *string
+	Pours            []PourRequest
+}
+
+// applyRecipe fills any setup fields the request leaves nil from the recipe.
+// Pours are only taken from the recipe when the request has none.
+func (req *CreateRequest) applyRecipe(rec recipeTemplate) {
+	if req.CoffeeWeight == nil {
+		req.CoffeeWeight = rec.CoffeeWeight
+	}
+	if req.Ratio == nil {
+		req.Ratio = rec.Ratio
+	}
+	if req.GrindSize == nil {
+		req.GrindSize = rec.GrindSize
+	}
+	if req.WaterTemperature == nil {
+		req.WaterTemperature = rec.WaterTemperature
+	}
+	if req.FilterPaperID == nil {
+		req.FilterPaperID = rec.FilterPaperID
+	}
+	if req.DripperID == nil {
+		req.DripperID = rec.DripperID
+	}
+	if len(req.Pours) == 0 {
+		req.Pours = rec.Pours
+	}
+}
+
 // ReferenceResponse wraps a brew with its source for the GET /coffees/:id/reference endpoint.
+// TranslatedGrindSize is set when the caller asks for the brew's grind size on
+// another of their grinders and the two grinders are calibrated.
 type ReferenceResponse struct {
-	Brew   *Brew  `json:"brew"`
-	Source string `json:"source"`
+	Brew                *Brew              `json:"brew"`
+	Source              string             `json:"source"`
+	TranslatedGrindSize *grind.Translation `json:"translated_grind_size"`
 }
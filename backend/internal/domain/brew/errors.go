@@ -0,0 +1,23 @@
+package brew
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound             = domainerr.NotFound("Brew not found")
+	ErrCoffeeNotFound       = domainerr.NotFound("Coffee not found")
+	ErrRecipeNotFound       = domainerr.NotFound("Recipe not found")
+	ErrFilterPaperNotFound  = domainerr.InvalidReference("filter_paper_id", "Filter paper not found")
+	ErrDripperNotFound      = domainerr.InvalidReference("dripper_id", "Dripper not found")
+	ErrGrinderNotFound      = domainerr.InvalidReference("grinder_id", "Grinder not found")
+	ErrWaterProfileNotFound = domainerr.InvalidReference("water_profile_id", "Water profile not found")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"brews_coffee_id_fkey":        ErrCoffeeNotFound,
+	"brews_recipe_id_fkey":        ErrRecipeNotFound,
+	"brews_filter_paper_id_fkey":  ErrFilterPaperNotFound,
+	"brews_dripper_id_fkey":       ErrDripperNotFound,
+	"brews_grinder_id_fkey":       ErrGrinderNotFound,
+	"brews_water_profile_id_fkey": ErrWaterProfileNotFound,
+}
@@ -5,12 +5,14 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
 )
 
 type Handler struct {
@@ -24,30 +26,14 @@ func NewHandler(repo Repository) *Handler {
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 	pagination := api.ParsePagination(r)
-	q := r.URL.Query()
 
+	// The brew lists ignore malformed filters rather than rejecting them.
+	filters, _ := parseListFilters(r)
 	params := ListParams{
-		Page:     pagination.Page,
-		PerPage:  pagination.PerPage,
-		Sort:     q.Get("sort"),
-		CoffeeID: q.Get("coffee_id"),
-		DateFrom: nilIfEmpty(q.Get("date_from")),
-		DateTo:   nilIfEmpty(q.Get("date_to")),
-	}
-
-	if v := q.Get("score_gte"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			params.ScoreGTE = &n
-		}
-	}
-	if v := q.Get("score_lte"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			params.ScoreLTE = &n
-		}
-	}
-	if v := q.Get("has_tds"); v != "" {
-		b := v == "true"
-		params.HasTDS = &b
+		Page:        pagination.Page,
+		PerPage:     pagination.PerPage,
+		Sort:        r.URL.Query().Get("sort"),
+		ListFilters: filters,
 	}
 
 	brews, total, err := h.repo.List(r.Context(), userID, params)
@@ -68,34 +54,39 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (h *Handler) ListByCoffee(w http.ResponseWriter, r *http.Request) {
+// ControlChart accepts the list filters; pagination and sorting don't apply.
+func (h *Handler) ControlChart(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
-	coffeeID := chi.URLParam(r, "id")
-	pagination := api.ParsePagination(r)
-	q := r.URL.Query()
 
-	params := ListParams{
-		Page:     pagination.Page,
-		PerPage:  pagination.PerPage,
-		Sort:     q.Get("sort"),
-		CoffeeID: coffeeID,
-		DateFrom: nilIfEmpty(q.Get("date_from")),
-		DateTo:   nilIfEmpty(q.Get("date_to")),
+	filters, fieldErrors := parseListFilters(r)
+	if len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
 	}
+	params := ListParams{ListFilters: filters}
 
-	if v := q.Get("score_gte"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			params.ScoreGTE = &n
-		}
-	}
-	if v := q.Get("score_lte"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil {
-			params.ScoreLTE = &n
-		}
+	chart, err := h.repo.ControlChart(r.Context(), userID, params)
+	if err != nil {
+		log.Printf("error building control chart: %v", err)
+		api.InternalError(w)
+		return
 	}
-	if v := q.Get("has_tds"); v != "" {
-		b := v == "true"
-		params.HasTDS = &b
+
+	api.WriteJSON(w, http.StatusOK, chart)
+}
+
+func (h *Handler) ListByCoffee(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	pagination := api.ParsePagination(r)
+
+	// The brew lists ignore malformed filters rather than rejecting them.
+	filters, _ := parseListFilters(r)
+	filters.CoffeeID = chi.URLParam(r, "id")
+	params := ListParams{
+		Page:        pagination.Page,
+		PerPage:     pagination.PerPage,
+		Sort:        r.URL.Query().Get("sort"),
+		ListFilters: filters,
 	}
 
 	brews, total, err := h.repo.List(r.Context(), userID, params)
@@ -169,15 +160,18 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req.CoffeeID = strings.TrimSpace(req.CoffeeID)
-	if req.CoffeeID == "" {
-		api.ValidationError(w, []api.FieldError{{Field: "coffee_id", Message: "Coffee is required"}})
+	if req.Method == "" {
+		req.Method = MethodPourOver
+	}
+	req.Tags = tags.Normalize(req.Tags)
+	if fieldErrors := validateRequest(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
 		return
 	}
 
 	brew, err := h.repo.Create(r.Context(), userID, req)
 	if err != nil {
-		if isCoffeeNotFoundError(err) {
-			api.NotFoundError(w, "Coffee not found")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error creating brew: %v", err)
@@ -199,13 +193,20 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	req.CoffeeID = strings.TrimSpace(req.CoffeeID)
-	if req.CoffeeID == "" {
-		api.ValidationError(w, []api.FieldError{{Field: "coffee_id", Message: "Coffee is required"}})
+	if req.Method == "" {
+		req.Method = MethodPourOver
+	}
+	req.Tags = tags.Normalize(req.Tags)
+	if fieldErrors := validateRequest(CreateRequest(req)); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
 		return
 	}
 
 	brew, err := h.repo.Update(r.Context(), userID, id, req)
 	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
 		log.Printf("error updating brew: %v", err)
 		api.InternalError(w)
 		return
@@ -224,8 +225,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err := h.repo.Delete(r.Context(), userID, id)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			api.NotFoundError(w, "Brew not found")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error deleting brew: %v", err)
@@ -242,8 +242,7 @@ func (h *Handler) GetReference(w http.ResponseWriter, r *http.Request) {
 
 	brew, source, err := h.repo.GetReference(r.Context(), userID, coffeeID)
 	if err != nil {
-		if isCoffeeNotFoundError(err) {
-			api.NotFoundError(w, "Coffee not found")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error getting reference: %v", err)
@@ -251,19 +250,115 @@ func (h *Handler) GetReference(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	api.WriteJSON(w, http.StatusOK, ReferenceResponse{
+	resp := ReferenceResponse{
 		Brew:   brew,
 		Source: source,
-	})
+	}
+
+	// Optionally express the reference grind size on another grinder
+	if grinderID := r.URL.Query().Get("grinder_id"); grinderID != "" && brew != nil && brew.Grinder != nil && brew.GrindSize != nil {
+		resp.TranslatedGrindSize, err = h.repo.TranslateGrind(r.Context(), userID, brew.Grinder.ID, grinderID, *brew.GrindSize)
+		if err != nil {
+			if api.DomainError(w, err) {
+				return
+			}
+			log.Printf("error translating reference grind size: %v", err)
+			api.InternalError(w)
+			return
+		}
+	}
+
+	api.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) GetSuggestion(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	coffeeID := chi.URLParam(r, "id")
+
+	history, err := h.repo.SuggestionHistory(r.Context(), userID, coffeeID)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error loading suggestion history: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	// The grind moves along the scale of the grinder the suggestion starts from
+	var scale *validate.GrindScale
+	if len(history) > 0 {
+		if basis, _, _ := pickBasis(history); basis.Grinder != nil {
+			scale, err = h.repo.GrindScale(r.Context(), userID, basis.Grinder.ID)
+			if err != nil {
+				log.Printf("error loading grind scale: %v", err)
+				api.InternalError(w)
+				return
+			}
+		}
+	}
+
+	api.WriteJSON(w, http.StatusOK, Suggest(history, scale))
+}
+
+// parseListFilters reads the brew filters from the query string. The brew
+// lists and the control chart share them. Malformed values are left unset
+// and reported as field errors, which only the control chart enforces.
+func parseListFilters(r *http.Request) (ListFilters, []api.FieldError) {
+	q := r.URL.Query()
+	var errs validate.Errors
+
+	f := ListFilters{
+		CoffeeID:       q.Get("coffee_id"),
+		DripperID:      q.Get("dripper_id"),
+		FilterPaperID:  q.Get("filter_paper_id"),
+		WaterProfileID: q.Get("water_profile_id"),
+		Method:         q.Get("method"),
+		ScoreGTE:       intParam(&errs, q.Get("score_gte"), "score_gte"),
+		ScoreLTE:       intParam(&errs, q.Get("score_lte"), "score_lte"),
+		DateFrom:       dateParam(&errs, q.Get("date_from"), "date_from"),
+		DateTo:         dateParam(&errs, q.Get("date_to"), "date_to"),
+		Tags:           tags.Parse(q.Get("tags")),
+		TagMatch:       q.Get("tag_match"),
+	}
+
+	if v := q.Get("has_tds"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs.Add("has_tds", "must be true or false")
+		} else {
+			f.HasTDS = &b
+		}
+	}
+	if f.TagMatch != "" && f.TagMatch != tags.MatchAny && f.TagMatch != tags.MatchAll {
+		errs.Add("tag_match", "must be one of any, all")
+	}
+
+	return f, errs
 }
 
-func nilIfEmpty(s string) *string {
-	if s == "" {
+// intParam parses an optional integer query parameter.
+func intParam(errs *validate.Errors, v, field string) *int {
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		errs.Add(field, "must be an integer")
 		return nil
 	}
-	return &s
+	return &n
 }
 
-func isCoffeeNotFoundError(err error) bool {
-	return strings.Contains(err.Error(), "coffee not found")
+// dateParam checks an optional YYYY-MM-DD query parameter. Unlike brew dates,
+// a filter date may be in the future.
+func dateParam(errs *validate.Errors, v, field string) *string {
+	if v == "" {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", v); err != nil {
+		errs.Add(field, "must be a date in YYYY-MM-DD format")
+		return nil
+	}
+	return &v
 }
@@ -14,10 +14,13 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/grind"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
 )
 
 const testSecret = "test-jwt-secret-key"
@@ -27,8 +30,20 @@ const testSecret = "test-jwt-secret-key"
 type mockRepo struct {
 	brews    map[string]*Brew
 	coffees  map[string]*mockCoffee
+	recipes  map[string]*mockRecipe
 	nextID   int
 	pourData map[string][]Pour
+	// deletedEquipment holds filter paper / dripper / grinder IDs that are
+	// soft-deleted or owned by another user.
+	deletedEquipment map[string]bool
+	grinders         map[string]validate.GrindScale
+	grindPairs       []grind.Pair
+	targets          Targets
+}
+
+type mockRecipe struct {
+	UserID   string
+	Template recipeTemplate
 }
 
 type mockCoffee struct {
@@ -45,9 +60,38 @@ func newMockRepo() *mockRepo {
 	return &mockRepo{
 		brews:    make(map[string]*Brew),
 		coffees:  make(map[string]*mockCoffee),
+		recipes:  make(map[string]*mockRecipe),
 		nextID:   1,
 		pourData: make(map[string][]Pour),
+
+		deletedEquipment: make(map[string]bool),
+		grinders:         make(map[string]validate.GrindScale),
+		targets:          SCATargets,
+	}
+}
+
+func (m *mockRepo) verifyGrinder(grinderID *string, grindSize *float64) error {
+	if grinderID == nil {
+		return nil
+	}
+	scale, ok := m.grinders[*grinderID]
+	if !ok || m.deletedEquipment[*grinderID] {
+		return ErrGrinderNotFound
+	}
+	return validate.GrindSizeError("grind_size", grindSize, scale)
+}
+
+func (m *mockRepo) verifyEquipment(filterPaperID, dripperID, waterProfileID *string) error {
+	if filterPaperID != nil && m.deletedEquipment[*filterPaperID] {
+		return ErrFilterPaperNotFound
+	}
+	if dripperID != nil && m.deletedEquipment[*dripperID] {
+		return ErrDripperNotFound
+	}
+	if waterProfileID != nil && m.deletedEquipment[*waterProfileID] {
+		return ErrWaterProfileNotFound
 	}
+	return nil
 }
 
 func (m *mockRepo) addCoffee(id, userID, name, roaster string, roastDate *string) {
@@ -69,6 +113,18 @@ func (m *mockRepo) List(_ context.Context, userID string, params ListParams) ([]
 		if params.CoffeeID != "" && b.CoffeeID != params.CoffeeID {
 			continue
 		}
+		if params.DripperID != "" && (b.Dripper == nil || b.Dripper.ID != params.DripperID) {
+			continue
+		}
+		if params.FilterPaperID != "" && (b.FilterPaper == nil || b.FilterPaper.ID != params.FilterPaperID) {
+			continue
+		}
+		if params.WaterProfileID != "" && (b.WaterProfile == nil || b.WaterProfile.ID != params.WaterProfileID) {
+			continue
+		}
+		if params.Method != "" && b.Method != params.Method {
+			continue
+		}
 		if params.ScoreGTE != nil && (b.OverallScore == nil || *b.OverallScore < *params.ScoreGTE) {
 			continue
 		}
@@ -78,6 +134,9 @@ func (m *mockRepo) List(_ context.Context, userID string, params ListParams) ([]
 		if params.HasTDS != nil && *params.HasTDS && b.TDS == nil {
 			continue
 		}
+		if len(params.Tags) > 0 && !hasTags(b.Tags, params.Tags, params.TagMatch) {
+			continue
+		}
 		result = append(result, *b)
 	}
 
@@ -98,6 +157,23 @@ func (m *mockRepo) List(_ context.Context, userID string, params ListParams) ([]
 	return result[offset:end], total, nil
 }
 
+// hasTags reports whether have holds any or all of want, ignoring case.
+func hasTags(have, want []string, match string) bool {
+	found := 0
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(h, w) {
+				found++
+				break
+			}
+		}
+	}
+	if match == tags.MatchAll {
+		return found == len(want)
+	}
+	return found > 0
+}
+
 func (m *mockRepo) Recent(_ context.Context, userID string, limit int) ([]Brew, error) {
 	var result []Brew
 	for _, b := range m.brews {
@@ -131,7 +207,25 @@ func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*Brew, error)
 func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (*Brew, error) {
 	c := m.coffees[req.CoffeeID]
 	if c == nil || c.UserID != userID {
-		return nil, fmt.Errorf("coffee not found")
+		return nil, ErrCoffeeNotFound
+	}
+
+	if req.RecipeID != nil {
+		rec := m.recipes[*req.RecipeID]
+		if rec == nil || rec.UserID != userID {
+			return nil, ErrRecipeNotFound
+		}
+		req.applyRecipe(rec.Template)
+		if err := validateMerged(req); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.verifyEquipment(req.FilterPaperID, req.DripperID, req.WaterProfileID); err != nil {
+		return nil, err
+	}
+	if err := m.verifyGrinder(req.GrinderID, req.GrindSize); err != nil {
+		return nil, err
 	}
 
 	m.nextID++
@@ -160,11 +254,14 @@ func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (
 		CoffeeReferenceBrewID: c.ReferenceBrewID,
 		BrewDate:              brewDate,
 		DaysOffRoast:        daysOffRoast,
+		Method:              req.Method,
 		CoffeeWeight:        req.CoffeeWeight,
 		Ratio:               req.Ratio,
 		GrindSize:           req.GrindSize,
 		WaterTemperature:    req.WaterTemperature,
 		TotalBrewTime:       req.TotalBrewTime,
+		MethodParams:        req.MethodParams,
+		RecipeID:            req.RecipeID,
 		TechniqueNotes:      req.TechniqueNotes,
 		CoffeeMl:            req.CoffeeMl,
 		TDS:                 req.TDS,
@@ -178,12 +275,17 @@ func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (
 		OverallNotes:        req.OverallNotes,
 		ImprovementNotes:    req.ImprovementNotes,
 		Pours:               []Pour{},
+		Tags:                req.Tags,
 		CreatedAt:           now,
 		UpdatedAt:           now,
 	}
 
-	b.WaterWeight = ComputeWaterWeight(b.CoffeeWeight, b.Ratio)
-	b.ExtractionYield = ComputeExtractionYield(b.CoffeeMl, b.TDS, b.CoffeeWeight)
+	if b.Tags == nil {
+		b.Tags = []string{}
+	}
+	b.WaterWeight = ComputeWaterWeight(b)
+	b.ExtractionYield = ComputeExtractionYield(b)
+	b.Classify(m.targets)
 
 	for _, p := range req.Pours {
 		b.Pours = append(b.Pours, Pour{
@@ -200,6 +302,12 @@ func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (
 	if req.DripperID != nil {
 		b.Dripper = &Dripper{ID: *req.DripperID, Name: "Test Dripper"}
 	}
+	if req.GrinderID != nil {
+		b.Grinder = &Grinder{ID: *req.GrinderID, Name: "Test Grinder"}
+	}
+	if req.WaterProfileID != nil {
+		b.WaterProfile = &WaterProfile{ID: *req.WaterProfileID, Name: "Test Water"}
+	}
 
 	m.brews[id] = b
 	return b, nil
@@ -211,6 +319,19 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 		return nil, nil
 	}
 
+	if req.RecipeID != nil {
+		if rec := m.recipes[*req.RecipeID]; rec == nil || rec.UserID != userID {
+			return nil, ErrRecipeNotFound
+		}
+	}
+
+	if err := m.verifyEquipment(req.FilterPaperID, req.DripperID, req.WaterProfileID); err != nil {
+		return nil, err
+	}
+	if err := m.verifyGrinder(req.GrinderID, req.GrindSize); err != nil {
+		return nil, err
+	}
+
 	brewDate := b.BrewDate
 	if req.BrewDate != nil {
 		brewDate = *req.BrewDate
@@ -218,11 +339,14 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 
 	b.CoffeeID = req.CoffeeID
 	b.BrewDate = brewDate
+	b.Method = req.Method
 	b.CoffeeWeight = req.CoffeeWeight
 	b.Ratio = req.Ratio
 	b.GrindSize = req.GrindSize
 	b.WaterTemperature = req.WaterTemperature
+	b.RecipeID = req.RecipeID
 	b.TotalBrewTime = req.TotalBrewTime
+	b.MethodParams = req.MethodParams
 	b.TechniqueNotes = req.TechniqueNotes
 	b.CoffeeMl = req.CoffeeMl
 	b.TDS = req.TDS
@@ -235,10 +359,14 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 	b.OverallScore = req.OverallScore
 	b.OverallNotes = req.OverallNotes
 	b.ImprovementNotes = req.ImprovementNotes
+	if req.Tags != nil {
+		b.Tags = req.Tags
+	}
 	b.UpdatedAt = time.Now()
 
-	b.WaterWeight = ComputeWaterWeight(b.CoffeeWeight, b.Ratio)
-	b.ExtractionYield = ComputeExtractionYield(b.CoffeeMl, b.TDS, b.CoffeeWeight)
+	b.WaterWeight = ComputeWaterWeight(b)
+	b.ExtractionYield = ComputeExtractionYield(b)
+	b.Classify(m.targets)
 
 	b.Pours = []Pour{}
 	for _, p := range req.Pours {
@@ -260,6 +388,16 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 	} else {
 		b.Dripper = nil
 	}
+	if req.GrinderID != nil {
+		b.Grinder = &Grinder{ID: *req.GrinderID, Name: "Test Grinder"}
+	} else {
+		b.Grinder = nil
+	}
+	if req.WaterProfileID != nil {
+		b.WaterProfile = &WaterProfile{ID: *req.WaterProfileID, Name: "Test Water"}
+	} else {
+		b.WaterProfile = nil
+	}
 
 	return b, nil
 }
@@ -267,7 +405,7 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 func (m *mockRepo) Delete(_ context.Context, userID, id string) error {
 	b := m.brews[id]
 	if b == nil || b.UserID != userID {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	delete(m.brews, id)
 	return nil
@@ -276,7 +414,7 @@ func (m *mockRepo) Delete(_ context.Context, userID, id string) error {
 func (m *mockRepo) GetReference(_ context.Context, userID, coffeeID string) (*Brew, string, error) {
 	c := m.coffees[coffeeID]
 	if c == nil || c.UserID != userID {
-		return nil, "", fmt.Errorf("coffee not found")
+		return nil, "", ErrCoffeeNotFound
 	}
 
 	// Check starred reference
@@ -303,6 +441,81 @@ func (m *mockRepo) GetReference(_ context.Context, userID, coffeeID string) (*Br
 	return nil, "", nil
 }
 
+func (m *mockRepo) TranslateGrind(_ context.Context, _, fromGrinderID, toGrinderID string, setting float64) (*grind.Translation, error) {
+	to, ok := m.grinders[toGrinderID]
+	if !ok || m.deletedEquipment[toGrinderID] {
+		return nil, ErrGrinderNotFound
+	}
+	from, ok := m.grinders[fromGrinderID]
+	if !ok || m.deletedEquipment[fromGrinderID] {
+		return nil, nil
+	}
+
+	t, err := grind.Translate(
+		grind.Grinder{ID: fromGrinderID, Scale: from},
+		grind.Grinder{ID: toGrinderID, Scale: to},
+		m.grindPairs, setting,
+	)
+	if errors.Is(err, domainerr.ErrInvalid) {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (m *mockRepo) SuggestionHistory(_ context.Context, userID, coffeeID string) ([]Brew, error) {
+	c := m.coffees[coffeeID]
+	if c == nil || c.UserID != userID {
+		return nil, ErrCoffeeNotFound
+	}
+
+	result := []Brew{}
+	for _, b := range m.brews {
+		if b.CoffeeID == coffeeID && b.UserID == userID {
+			b.WaterWeight = ComputeWaterWeight(b)
+			b.ExtractionYield = ComputeExtractionYield(b)
+			b.Classify(m.targets)
+			result = append(result, *b)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].BrewDate > result[j].BrewDate })
+	return result, nil
+}
+
+func (m *mockRepo) GrindScale(_ context.Context, _, grinderID string) (*validate.GrindScale, error) {
+	scale, ok := m.grinders[grinderID]
+	if !ok || m.deletedEquipment[grinderID] {
+		return nil, nil
+	}
+	return &scale, nil
+}
+
+func (m *mockRepo) ControlChart(_ context.Context, userID string, params ListParams) (*ControlChartResponse, error) {
+	var brews []*Brew
+	for _, b := range m.brews {
+		if b.UserID != userID || b.TDS == nil {
+			continue
+		}
+		if params.CoffeeID != "" && b.CoffeeID != params.CoffeeID {
+			continue
+		}
+		if params.Method != "" && b.Method != params.Method {
+			continue
+		}
+		brews = append(brews, b)
+	}
+	sort.Slice(brews, func(i, j int) bool { return brews[i].BrewDate < brews[j].BrewDate })
+
+	resp := &ControlChartResponse{SCA: SCATargets, Targets: m.targets, Items: []ControlChartPoint{}}
+	for _, b := range brews {
+		b.ExtractionYield = ComputeExtractionYield(b)
+		b.Classify(m.targets)
+		if p := controlChartPoint(b); p != nil {
+			resp.Items = append(resp.Items, *p)
+		}
+	}
+	return resp, nil
+}
+
 // Error-returning mock
 
 type errorRepo struct{}
@@ -329,6 +542,22 @@ func (e *errorRepo) GetReference(_ context.Context, _, _ string) (*Brew, string,
 	return nil, "", errors.New("database error")
 }
 
+func (e *errorRepo) TranslateGrind(_ context.Context, _, _, _ string, _ float64) (*grind.Translation, error) {
+	return nil, errors.New("database error")
+}
+
+func (e *errorRepo) SuggestionHistory(_ context.Context, _, _ string) ([]Brew, error) {
+	return nil, errors.New("database error")
+}
+
+func (e *errorRepo) ControlChart(_ context.Context, _ string, _ ListParams) (*ControlChartResponse, error) {
+	return nil, errors.New("database error")
+}
+
+func (e *errorRepo) GrindScale(_ context.Context, _, _ string) (*validate.GrindScale, error) {
+	return nil, errors.New("database error")
+}
+
 // --- Helpers ---
 
 func generateTestAccessToken(userID string) string {
@@ -351,6 +580,7 @@ func setupRouter(h *Handler) *chi.Mux {
 		r.Route("/brews", func(r chi.Router) {
 			r.Get("/", h.List)
 			r.Get("/recent", h.Recent)
+			r.Get("/control-chart", h.ControlChart)
 			r.Post("/", h.Create)
 			r.Get("/{id}", h.GetByID)
 			r.Put("/{id}", h.Update)
@@ -360,6 +590,7 @@ func setupRouter(h *Handler) *chi.Mux {
 		r.Route("/coffees", func(r chi.Router) {
 			r.Get("/{id}/brews", h.ListByCoffee)
 			r.Get("/{id}/reference", h.GetReference)
+			r.Get("/{id}/suggestion", h.GetSuggestion)
 		})
 	})
 	return r
@@ -386,8 +617,10 @@ func seedBrew(repo *mockRepo, id, userID, coffeeID, brewDate string, score *int)
 		CoffeeName:    "Test Coffee",
 		CoffeeRoaster: "Test Roaster",
 		BrewDate:      brewDate,
+		Method:        MethodPourOver,
 		OverallScore:  score,
 		Pours:         []Pour{},
+		Tags:          []string{},
 		CreatedAt:     now,
 		UpdatedAt:     now,
 	}
@@ -491,6 +724,32 @@ func TestList_ScoreFilter(t *testing.T) {
 	}
 }
 
+func TestList_IgnoresMalformedFilters(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Coffee", "Roaster", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", intPtr(5))
+	seedBrew(repo, "b-2", "user-123", "c-1", "2026-01-16", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	for _, url := range []string{
+		"/api/v1/brews?score_gte=high&score_lte=8.5&date_from=2026/01/01&has_tds=maybe",
+		"/api/v1/coffees/c-1/brews?score_gte=high&date_to=yesterday&has_tds=maybe",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, authRequest(http.MethodGet, url, ""))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", url, w.Code, w.Body.String())
+		}
+		var resp api.PaginatedResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if items := resp.Items.([]interface{}); len(items) != 2 {
+			t.Errorf("%s: expected malformed filters to be ignored, got %d items", url, len(items))
+		}
+	}
+}
+
 func TestList_Pagination(t *testing.T) {
 	repo := newMockRepo()
 	repo.addCoffee("c-1", "user-123", "Coffee", "Roaster", nil)
@@ -690,8 +949,8 @@ func TestGetByID_Success(t *testing.T) {
 	b.Ratio = floatPtr(15.0)
 	b.CoffeeMl = floatPtr(200.0)
 	b.TDS = floatPtr(1.38)
-	b.WaterWeight = ComputeWaterWeight(b.CoffeeWeight, b.Ratio)
-	b.ExtractionYield = ComputeExtractionYield(b.CoffeeMl, b.TDS, b.CoffeeWeight)
+	b.WaterWeight = ComputeWaterWeight(b)
+	b.ExtractionYield = ComputeExtractionYield(b)
 	h := NewHandler(repo)
 	router := setupRouter(h)
 
@@ -1187,6 +1446,79 @@ func TestUpdate_EmptyPoursRemovesAll(t *testing.T) {
 	}
 }
 
+func TestCreate_WithTags(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Coffee", "Roaster", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "tags": ["Dialing in", " dialing in", "guest"]}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Tags) != 2 || resp.Tags[0] != "Dialing in" || resp.Tags[1] != "guest" {
+		t.Errorf("expected [Dialing in guest], got %q", resp.Tags)
+	}
+}
+
+func TestCreate_TooManyTags(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Coffee", "Roaster", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	names := make([]string, tags.MaxTags+1)
+	for i := range names {
+		names[i] = fmt.Sprintf("%q", fmt.Sprintf("tag %d", i))
+	}
+	body := `{"coffee_id": "c-1", "tags": [` + strings.Join(names, ",") + `]}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"field":"tags"`) {
+		t.Errorf("expected error on tags, got %s", w.Body.String())
+	}
+}
+
+func TestUpdate_Tags(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Coffee", "Roaster", nil)
+	b := seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	b.Tags = []string{"guest"}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/brews/b-1", `{"coffee_id": "c-1"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Tags) != 1 {
+		t.Errorf("expected omitted tags to be kept, got %q", resp.Tags)
+	}
+
+	req = authRequest(http.MethodPut, "/api/v1/brews/b-1", `{"coffee_id": "c-1", "tags": []}`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Tags) != 0 {
+		t.Errorf("expected empty tags to clear them, got %q", resp.Tags)
+	}
+}
+
 // --- Delete Tests ---
 
 func TestDelete_Success(t *testing.T) {
@@ -1377,42 +1709,165 @@ func TestGetReference_DatabaseError(t *testing.T) {
 	}
 }
 
+func TestGetReference_TranslatedGrindSize(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	b := seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-10", intPtr(8))
+	b.Grinder = &Grinder{ID: "g-1", Name: "Ode 2"}
+	b.GrindSize = floatPtr(5.5)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	repo.grinders["g-2"] = validate.GrindScale{Min: 0, Max: 40, Step: floatPtr(1)}
+	repo.grindPairs = []grind.Pair{
+		{FromGrinderID: "g-1", FromSetting: 4, ToGrinderID: "g-2", ToSetting: 16},
+		{FromGrinderID: "g-1", FromSetting: 6, ToGrinderID: "g-2", ToSetting: 24},
+	}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/reference?grinder_id=g-2", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ReferenceResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	tr := resp.TranslatedGrindSize
+	if tr == nil {
+		t.Fatal("expected translated grind size")
+	}
+	if tr.ToGrinderID != "g-2" || tr.Setting != 22 || tr.Method != grind.MethodPaired {
+		t.Errorf("unexpected translation: %+v", tr)
+	}
+}
+
+func TestGetReference_UncalibratedGrinderOmitsTranslation(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	b := seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-10", intPtr(8))
+	b.Grinder = &Grinder{ID: "g-1", Name: "Ode 2"}
+	b.GrindSize = floatPtr(5.5)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	repo.grinders["g-2"] = validate.GrindScale{Min: 0, Max: 40, Step: floatPtr(1)}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/reference?grinder_id=g-2", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ReferenceResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Brew == nil || resp.TranslatedGrindSize != nil {
+		t.Errorf("expected brew without translation, got %+v", resp)
+	}
+}
+
+func TestGetReference_UnknownGrinder(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	b := seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-10", intPtr(8))
+	b.Grinder = &Grinder{ID: "g-1", Name: "Ode 2"}
+	b.GrindSize = floatPtr(5.5)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/reference?grinder_id=g-9", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
 // --- Computed Fields Tests ---
 
 func TestComputeWaterWeight(t *testing.T) {
-	ww := ComputeWaterWeight(floatPtr(15.0), floatPtr(15.0))
+	ww := ComputeWaterWeight(&Brew{Method: MethodPourOver, CoffeeWeight: floatPtr(15.0), Ratio: floatPtr(15.0)})
 	if ww == nil || *ww != 225.0 {
 		t.Errorf("expected 225.0, got %v", ww)
 	}
 
-	ww = ComputeWaterWeight(nil, floatPtr(15.0))
+	ww = ComputeWaterWeight(&Brew{Method: MethodPourOver, Ratio: floatPtr(15.0)})
 	if ww != nil {
 		t.Errorf("expected nil when coffee_weight missing, got %v", *ww)
 	}
 
-	ww = ComputeWaterWeight(floatPtr(15.0), nil)
+	ww = ComputeWaterWeight(&Brew{Method: MethodPourOver, CoffeeWeight: floatPtr(15.0)})
 	if ww != nil {
 		t.Errorf("expected nil when ratio missing, got %v", *ww)
 	}
+
+	ww = ComputeWaterWeight(&Brew{Method: MethodEspresso, CoffeeWeight: floatPtr(18.0), Ratio: floatPtr(2.0)})
+	if ww != nil {
+		t.Errorf("expected nil for espresso, got %v", *ww)
+	}
 }
 
 func TestComputeExtractionYield(t *testing.T) {
-	ey := ComputeExtractionYield(floatPtr(200.0), floatPtr(1.38), floatPtr(15.0))
+	ey := ComputeExtractionYield(&Brew{Method: MethodPourOver, CoffeeMl: floatPtr(200.0), TDS: floatPtr(1.38), CoffeeWeight: floatPtr(15.0)})
 	if ey == nil || *ey != 18.4 {
 		t.Errorf("expected 18.4, got %v", ey)
 	}
 
-	ey = ComputeExtractionYield(nil, floatPtr(1.38), floatPtr(15.0))
+	ey = ComputeExtractionYield(&Brew{Method: MethodPourOver, TDS: floatPtr(1.38), CoffeeWeight: floatPtr(15.0)})
 	if ey != nil {
 		t.Errorf("expected nil when coffee_ml missing, got %v", *ey)
 	}
 
-	ey = ComputeExtractionYield(floatPtr(200.0), floatPtr(1.38), floatPtr(0))
+	ey = ComputeExtractionYield(&Brew{Method: MethodPourOver, CoffeeMl: floatPtr(200.0), TDS: floatPtr(1.38), CoffeeWeight: floatPtr(0)})
 	if ey != nil {
 		t.Errorf("expected nil when coffee_weight is 0, got %v", *ey)
 	}
 }
 
+func TestComputeExtractionYield_Espresso(t *testing.T) {
+	b := &Brew{
+		Method:       MethodEspresso,
+		CoffeeWeight: floatPtr(18.0),
+		TDS:          floatPtr(9.0),
+		CoffeeMl:     floatPtr(30.0),
+		MethodParams: MethodParams{YieldWeight: floatPtr(36.0)},
+	}
+	ey := ComputeExtractionYield(b)
+	if ey == nil || *ey != 18.0 {
+		t.Errorf("expected 18.0 from yield weight, got %v", ey)
+	}
+
+	b.YieldWeight = nil
+	if ey := ComputeExtractionYield(b); ey != nil {
+		t.Errorf("expected nil when yield_weight missing, got %v", *ey)
+	}
+}
+
+func TestComputeExtractionYield_ImmersionUsesBrewWater(t *testing.T) {
+	b := &Brew{
+		Method:       MethodImmersion,
+		CoffeeWeight: floatPtr(15.0),
+		Ratio:        floatPtr(16.0),
+		CoffeeMl:     floatPtr(205.0),
+		TDS:          floatPtr(1.2),
+	}
+	ey := ComputeExtractionYield(b)
+	if ey == nil || *ey != 19.2 {
+		t.Errorf("expected 19.2 from 240g brew water, got %v", ey)
+	}
+
+	b.Ratio = nil
+	ey = ComputeExtractionYield(b)
+	if ey == nil || *ey != 16.4 {
+		t.Errorf("expected 16.4 from coffee_ml fallback, got %v", ey)
+	}
+}
+
 // --- Brew Date Tests (Issue #2: brew_date DATE scan) ---
 
 func TestCreate_BrewDateInResponse(t *testing.T) {
@@ -1849,3 +2304,1086 @@ func TestRecent_IncludesCoffeeReferenceBrewID(t *testing.T) {
 		t.Errorf("expected coffee_reference_brew_id 'b-1', got %v", refID)
 	}
 }
+
+// --- Recipe Tests ---
+
+func TestCreate_FromRecipe(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.recipes["r-1"] = &mockRecipe{
+		UserID: "user-123",
+		Template: recipeTemplate{
+			CoffeeWeight:     floatPtr(15),
+			Ratio:            floatPtr(16),
+			GrindSize:        floatPtr(3.5),
+			WaterTemperature: floatPtr(93),
+			DripperID:        strPtr("d-1"),
+			Pours: []PourRequest{
+				{PourNumber: 1, WaterAmount: floatPtr(45), PourStyle: strPtr("center"), WaitTime: intPtr(30)},
+				{PourNumber: 2, WaterAmount: floatPtr(195), PourStyle: strPtr("circular")},
+			},
+		},
+	}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id":"c-1","recipe_id":"r-1","grind_size":4,"overall_score":8}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var brew Brew
+	json.Unmarshal(w.Body.Bytes(), &brew)
+
+	if brew.RecipeID == nil || *brew.RecipeID != "r-1" {
+		t.Errorf("expected recipe_id r-1, got %v", brew.RecipeID)
+	}
+	if brew.CoffeeWeight == nil || *brew.CoffeeWeight != 15 {
+		t.Errorf("expected coffee_weight 15 from recipe, got %v", brew.CoffeeWeight)
+	}
+	if brew.GrindSize == nil || *brew.GrindSize != 4 {
+		t.Errorf("expected request grind_size 4 to win over recipe, got %v", brew.GrindSize)
+	}
+	if brew.WaterWeight == nil || *brew.WaterWeight != 240 {
+		t.Errorf("expected water_weight 240, got %v", brew.WaterWeight)
+	}
+	if brew.Dripper == nil || brew.Dripper.ID != "d-1" {
+		t.Errorf("expected dripper d-1 from recipe, got %v", brew.Dripper)
+	}
+	if len(brew.Pours) != 2 {
+		t.Errorf("expected 2 pours from recipe, got %d", len(brew.Pours))
+	}
+}
+
+func TestCreate_RecipePoursNotAppliedWhenRequestHasPours(t *testing.T) {
+	req := CreateRequest{
+		Pours: []PourRequest{{PourNumber: 1, WaterAmount: floatPtr(250)}},
+	}
+	req.applyRecipe(recipeTemplate{
+		Ratio: floatPtr(16),
+		Pours: []PourRequest{
+			{PourNumber: 1, WaterAmount: floatPtr(45)},
+			{PourNumber: 2, WaterAmount: floatPtr(195)},
+		},
+	})
+
+	if len(req.Pours) != 1 || *req.Pours[0].WaterAmount != 250 {
+		t.Errorf("expected request pours to be kept, got %v", req.Pours)
+	}
+	if req.Ratio == nil || *req.Ratio != 16 {
+		t.Errorf("expected ratio 16 from recipe, got %v", req.Ratio)
+	}
+}
+
+func TestCreate_RecipeNotFound(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.recipes["r-1"] = &mockRecipe{UserID: "user-456"}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id":"c-1","recipe_id":"r-1"}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for other user's recipe, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreate_RecipePoursExceedWaterWeight(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.recipes["r-1"] = &mockRecipe{
+		UserID: "user-123",
+		Template: recipeTemplate{
+			Pours: []PourRequest{
+				{PourNumber: 1, WaterAmount: floatPtr(150)},
+				{PourNumber: 2, WaterAmount: floatPtr(150)},
+			},
+		},
+	}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id":"c-1","recipe_id":"r-1","coffee_weight":15,"ratio":16}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for recipe pours over the water weight, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "pours" {
+		t.Errorf("expected a pours error, got %v", resp.Error.Details)
+	}
+	if len(repo.brews) != 0 {
+		t.Errorf("expected no brew to be created, got %d", len(repo.brews))
+	}
+}
+
+func TestCreate_RecipePoursOnEspresso(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.recipes["r-1"] = &mockRecipe{
+		UserID: "user-123",
+		Template: recipeTemplate{
+			Pours: []PourRequest{{PourNumber: 1, WaterAmount: floatPtr(45)}},
+		},
+	}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id":"c-1","recipe_id":"r-1","method":"espresso"}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for recipe pours on an espresso brew, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestUpdate_OtherUsersRecipe(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	repo.recipes["r-1"] = &mockRecipe{UserID: "user-456"}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id":"c-1","recipe_id":"r-1"}`
+	req := authRequest(http.MethodPut, "/api/v1/brews/b-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for other user's recipe, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.brews["b-1"].RecipeID != nil {
+		t.Errorf("expected recipe_id to stay unset, got %v", *repo.brews["b-1"].RecipeID)
+	}
+}
+
+// --- Validation Tests ---
+
+func TestCreate_ValidationFieldErrors(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{
+		"coffee_id": "c-1",
+		"brew_date": "2026/01/15",
+		"coffee_weight": -15,
+		"ratio": 50,
+		"water_temperature": 120,
+		"aroma_intensity": 11,
+		"overall_score": 0
+	}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Error.Code != api.CodeValidationError {
+		t.Errorf("expected VALIDATION_ERROR, got %s", resp.Error.Code)
+	}
+
+	got := map[string]bool{}
+	for _, d := range resp.Error.Details {
+		got[d.Field] = true
+	}
+	for _, field := range []string{"brew_date", "coffee_weight", "ratio", "water_temperature", "aroma_intensity", "overall_score"} {
+		if !got[field] {
+			t.Errorf("expected field error for %s, got %v", field, resp.Error.Details)
+		}
+	}
+}
+
+func TestCreate_PoursExceedWaterWeight(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{
+		"coffee_id": "c-1",
+		"coffee_weight": 15,
+		"ratio": 15,
+		"pours": [
+			{"pour_number": 1, "water_amount": 50},
+			{"pour_number": 2, "water_amount": 200}
+		]
+	}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "pours" {
+		t.Errorf("expected a single pours error, got %v", resp.Error.Details)
+	}
+}
+
+func TestUpdate_DuplicatePourNumbers(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "pours": [{"pour_number": 1}, {"pour_number": 1}]}`
+	req := authRequest(http.MethodPut, "/api/v1/brews/b-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "pours[1].pour_number" {
+		t.Errorf("expected pours[1].pour_number error, got %v", resp.Error.Details)
+	}
+}
+
+// --- Equipment Reference Tests ---
+
+func TestCreate_DeletedFilterPaper(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.deletedEquipment["fp-gone"] = true
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "filter_paper_id": "fp-gone"}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Error.Code != api.CodeInvalidReference {
+		t.Errorf("expected INVALID_REFERENCE, got %s", resp.Error.Code)
+	}
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "filter_paper_id" {
+		t.Errorf("expected filter_paper_id detail, got %v", resp.Error.Details)
+	}
+	if len(repo.brews) != 0 {
+		t.Errorf("expected no brew to be created, got %d", len(repo.brews))
+	}
+}
+
+func TestUpdate_DeletedDripper(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	repo.deletedEquipment["d-gone"] = true
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "dripper_id": "d-gone"}`
+	req := authRequest(http.MethodPut, "/api/v1/brews/b-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "dripper_id" {
+		t.Errorf("expected dripper_id detail, got %v", resp.Error.Details)
+	}
+}
+
+// --- Water Profile Tests ---
+
+func TestCreate_WaterProfileInResponse(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "water_profile_id": "wp-1"}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.WaterProfile == nil || resp.WaterProfile.ID != "wp-1" {
+		t.Errorf("expected water profile wp-1, got %+v", resp.WaterProfile)
+	}
+}
+
+func TestCreate_DeletedWaterProfile(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.deletedEquipment["wp-1"] = true
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "water_profile_id": "wp-1"}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "water_profile_id" {
+		t.Errorf("expected water_profile_id detail, got %v", resp.Error.Details)
+	}
+}
+
+func TestList_FilterByWaterProfile(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	b := seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	b.WaterProfile = &WaterProfile{ID: "wp-1", Name: "Rao/Perger"}
+	seedBrew(repo, "b-2", "user-123", "c-1", "2026-01-16", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews?water_profile_id=wp-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []Brew `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].ID != "b-1" {
+		t.Errorf("expected only b-1, got %d brews", len(resp.Items))
+	}
+}
+
+// --- Grinder Tests ---
+
+func TestCreate_GrinderInResponse(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "grinder_id": "g-1", "grind_size": 3.5}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Grinder == nil || resp.Grinder.ID != "g-1" {
+		t.Errorf("expected grinder g-1, got %+v", resp.Grinder)
+	}
+}
+
+func TestCreate_GrindSizeOffScale(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	for _, size := range []string{"3.3", "12"} {
+		body := `{"coffee_id": "c-1", "grinder_id": "g-1", "grind_size": ` + size + `}`
+		req := authRequest(http.MethodPost, "/api/v1/brews", body)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("grind_size %s: expected 400, got %d: %s", size, w.Code, w.Body.String())
+		}
+
+		var resp api.ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "grind_size" {
+			t.Errorf("grind_size %s: expected grind_size error, got %v", size, resp.Error.Details)
+		}
+	}
+}
+
+func TestUpdate_DeletedGrinder(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 0, Max: 40, Stepless: true}
+	repo.deletedEquipment["g-1"] = true
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "grinder_id": "g-1"}`
+	req := authRequest(http.MethodPut, "/api/v1/brews/b-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "grinder_id" {
+		t.Errorf("expected grinder_id detail, got %v", resp.Error.Details)
+	}
+}
+
+// --- Brew Method Tests ---
+
+func TestCreate_DefaultsToPourOver(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/brews", `{"coffee_id": "c-1"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Method != MethodPourOver {
+		t.Errorf("expected method pour_over, got %q", resp.Method)
+	}
+}
+
+func TestCreate_Espresso(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "method": "espresso", "coffee_weight": 18, "ratio": 2,
+		"yield_weight": 36, "pressure": 9, "preinfusion_time": 5, "total_brew_time": 28, "tds": 9}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp["method"] != "espresso" {
+		t.Errorf("expected method espresso, got %v", resp["method"])
+	}
+	if resp["yield_weight"] != 36.0 || resp["pressure"] != 9.0 || resp["preinfusion_time"] != 5.0 {
+		t.Errorf("expected espresso params in response, got %v", resp)
+	}
+	if resp["water_weight"] != nil {
+		t.Errorf("expected no water_weight for espresso, got %v", resp["water_weight"])
+	}
+	if resp["extraction_yield"] != 18.0 {
+		t.Errorf("expected extraction_yield 18, got %v", resp["extraction_yield"])
+	}
+}
+
+func TestCreate_ColdBrew(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "method": "cold_brew", "coffee_weight": 100, "ratio": 8,
+		"steep_hours": 16, "dilution": 1}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.SteepHours == nil || *resp.SteepHours != 16 || resp.Dilution == nil || *resp.Dilution != 1 {
+		t.Errorf("expected cold brew params, got steep_hours=%v dilution=%v", resp.SteepHours, resp.Dilution)
+	}
+}
+
+func TestCreate_MethodValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"unknown method", `{"coffee_id": "c-1", "method": "siphon"}`, "method"},
+		{"espresso param on pour over", `{"coffee_id": "c-1", "yield_weight": 36}`, "yield_weight"},
+		{"immersion param on espresso", `{"coffee_id": "c-1", "method": "espresso", "steep_time": 240}`, "steep_time"},
+		{"cold brew param on immersion", `{"coffee_id": "c-1", "method": "immersion", "dilution": 1}`, "dilution"},
+		{"pours on espresso", `{"coffee_id": "c-1", "method": "espresso", "pours": [{"pour_number": 1, "water_amount": 40}]}`, "pours"},
+		{"implausible pressure", `{"coffee_id": "c-1", "method": "espresso", "pressure": 90}`, "pressure"},
+		{"unknown agitation", `{"coffee_id": "c-1", "method": "immersion", "agitation": "shake"}`, "agitation"},
+		{"negative steep time", `{"coffee_id": "c-1", "method": "immersion", "steep_time": -1}`, "steep_time"},
+		{"zero steep hours", `{"coffee_id": "c-1", "method": "cold_brew", "steep_hours": 0}`, "steep_hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+			h := NewHandler(repo)
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/brews", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp struct {
+				Error struct {
+					Details []api.FieldError `json:"details"`
+				} `json:"error"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.Error.Details) == 0 || resp.Error.Details[0].Field != tt.field {
+				t.Errorf("expected %s error, got %v", tt.field, resp.Error.Details)
+			}
+		})
+	}
+}
+
+func TestUpdate_ChangeMethod(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id": "c-1", "method": "immersion", "steep_time": 240, "agitation": "stir", "plunge": true}`
+	req := authRequest(http.MethodPut, "/api/v1/brews/b-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Method != MethodImmersion {
+		t.Errorf("expected method immersion, got %q", resp.Method)
+	}
+	if resp.SteepTime == nil || *resp.SteepTime != 240 || resp.Agitation == nil || *resp.Agitation != "stir" || resp.Plunge == nil || !*resp.Plunge {
+		t.Errorf("expected immersion params, got %+v", resp.MethodParams)
+	}
+}
+
+func TestList_FilterByMethod(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	b := seedBrew(repo, "b-2", "user-123", "c-1", "2026-01-16", nil)
+	b.Method = MethodEspresso
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews?method=espresso", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []Brew `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].ID != "b-2" {
+		t.Errorf("expected only b-2, got %d brews", len(resp.Items))
+	}
+}
+
+func TestList_FilterByDripperAndFilterPaper(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	a := seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil)
+	a.Dripper = &Dripper{ID: "d-1", Name: "V60"}
+	a.FilterPaper = &FilterPaper{ID: "fp-1", Name: "Abaca"}
+	b := seedBrew(repo, "b-2", "user-123", "c-1", "2026-01-16", nil)
+	b.Dripper = &Dripper{ID: "d-1", Name: "V60"}
+	b.FilterPaper = &FilterPaper{ID: "fp-2", Name: "Tabbed"}
+	seedBrew(repo, "b-3", "user-123", "c-1", "2026-01-17", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews?dripper_id=d-1&filter_paper_id=fp-2", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []Brew `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].ID != "b-2" {
+		t.Errorf("expected only b-2, got %d brews", len(resp.Items))
+	}
+}
+
+// --- Suggestion Tests ---
+
+func getSuggestion(t *testing.T, repo *mockRepo, coffeeID string) SuggestionResponse {
+	t.Helper()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/"+coffeeID+"/suggestion", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp SuggestionResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func findChange(changes []SuggestedChange, field string) *SuggestedChange {
+	for i := range changes {
+		if changes[i].Field == field {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestGetSuggestion_UnderExtractedGrindsFiner(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("coffee-1", "user-123", "Kenya AA", "Roaster", nil)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	b := seedBrew(repo, "brew-1", "user-123", "coffee-1", "2026-01-05", intPtr(6))
+	b.CoffeeWeight = floatPtr(15)
+	b.Ratio = floatPtr(15)
+	b.GrindSize = floatPtr(6)
+	b.Grinder = &Grinder{ID: "g-1", Name: "Comandante"}
+	b.WaterTemperature = floatPtr(94)
+	b.CoffeeMl = floatPtr(200)
+	b.TDS = floatPtr(1.3)
+	b.ImprovementNotes = strPtr("A bit sour on the finish")
+
+	resp := getSuggestion(t, repo, "coffee-1")
+
+	if resp.Diagnosis != DiagnosisUnderExtracted {
+		t.Errorf("expected under_extracted, got %q", resp.Diagnosis)
+	}
+	if resp.Source != SuggestionSourceLatest || resp.BasedOnBrewID == nil || *resp.BasedOnBrewID != "brew-1" {
+		t.Errorf("expected latest brew-1 as basis, got %q %v", resp.Source, resp.BasedOnBrewID)
+	}
+	c := findChange(resp.Changes, "grind_size")
+	if c == nil || *c.To != 5.5 {
+		t.Fatalf("expected grind_size 6 -> 5.5, got %+v", resp.Changes)
+	}
+	if !strings.Contains(c.Rationale, "finer") || !strings.Contains(c.Rationale, `"sour"`) {
+		t.Errorf("unexpected rationale: %q", c.Rationale)
+	}
+	if findChange(resp.Changes, "water_temperature") != nil {
+		t.Error("expected temperature unchanged when the grind can move")
+	}
+	if resp.Brew == nil || *resp.Brew.GrindSize != 5.5 || *resp.Brew.WaterTemperature != 94 {
+		t.Fatalf("expected brew template with new grind, got %+v", resp.Brew)
+	}
+	if resp.Brew.ID != "" || resp.Brew.TDS != nil || resp.Brew.OverallScore != nil || resp.Brew.ImprovementNotes != nil {
+		t.Error("expected template without identity or outcome")
+	}
+}
+
+func TestGetSuggestion_SnapsGrindToFractionalStep(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("coffee-1", "user-123", "Kenya AA", "Roaster", nil)
+	scale := validate.GrindScale{Min: 1, Max: 10, Step: floatPtr(0.333)}
+	repo.grinders["g-1"] = scale
+	b := seedBrew(repo, "brew-1", "user-123", "coffee-1", "2026-01-05", intPtr(6))
+	b.GrindSize = floatPtr(4.33)
+	b.Grinder = &Grinder{ID: "g-1", Name: "Timemore C2"}
+	b.ImprovementNotes = strPtr("Sour and thin")
+
+	resp := getSuggestion(t, repo, "coffee-1")
+
+	c := findChange(resp.Changes, "grind_size")
+	if c == nil || *c.To != 3.997 {
+		t.Fatalf("expected grind_size 4.33 -> 3.997, got %+v", resp.Changes)
+	}
+	if !scale.OnStep(*c.To) {
+		t.Errorf("expected suggested grind %v on the grinder's steps", *c.To)
+	}
+}
+
+func TestNextGrind_SnapsOffStepSettings(t *testing.T) {
+	scale := &validate.GrindScale{Min: 1, Max: 10, Step: floatPtr(0.333)}
+
+	// 5.1 is between steps; one step coarser lands on the nearest one.
+	if got := nextGrind(5.1, scale, false); got != 5.329 {
+		t.Errorf("expected 5.329, got %v", got)
+	}
+	// The top step is 9.991; coarser than that stays on it.
+	if got := nextGrind(9.991, scale, false); got != 9.991 {
+		t.Errorf("expected 9.991 at the coarse limit, got %v", got)
+	}
+}
+
+func TestGetSuggestion_OverExtractedAtGrindLimitLowersTemperature(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("coffee-1", "user-123", "Kenya AA", "Roaster", nil)
+	repo.grinders["g-1"] = validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	b := seedBrew(repo, "brew-1", "user-123", "coffee-1", "2026-01-05", intPtr(5))
+	b.GrindSize = floatPtr(11)
+	b.Grinder = &Grinder{ID: "g-1", Name: "Comandante"}
+	b.WaterTemperature = floatPtr(94)
+	b.ImprovementNotes = strPtr("Bitter and harsh")
+
+	resp := getSuggestion(t, repo, "coffee-1")
+
+	if resp.Diagnosis != DiagnosisOverExtracted {
+		t.Errorf("expected over_extracted, got %q", resp.Diagnosis)
+	}
+	if findChange(resp.Changes, "grind_size") != nil {
+		t.Error("expected no grind change at the coarsest setting")
+	}
+	c := findChange(resp.Changes, "water_temperature")
+	if c == nil || *c.To != 92 {
+		t.Fatalf("expected water_temperature 94 -> 92, got %+v", resp.Changes)
+	}
+}
+
+func TestGetSuggestion_WeakBrewTightensRatio(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("coffee-1", "user-123", "Kenya AA", "Roaster", nil)
+	b := seedBrew(repo, "brew-1", "user-123", "coffee-1", "2026-01-05", intPtr(6))
+	b.CoffeeWeight = floatPtr(15)
+	b.Ratio = floatPtr(17)
+	b.CoffeeMl = floatPtr(250)
+	b.TDS = floatPtr(1.1)
+	b.Pours = []Pour{
+		{PourNumber: 1, WaterAmount: floatPtr(50)},
+		{PourNumber: 2, WaterAmount: floatPtr(205)},
+	}
+
+	resp := getSuggestion(t, repo, "coffee-1")
+
+	if resp.Diagnosis != DiagnosisBalanced {
+		t.Errorf("expected balanced extraction, got %q", resp.Diagnosis)
+	}
+	c := findChange(resp.Changes, "ratio")
+	if c == nil || *c.To != 16 {
+		t.Fatalf("expected ratio 17 -> 16, got %+v", resp.Changes)
+	}
+	if *resp.Brew.WaterWeight != 240 {
+		t.Errorf("expected water weight 240, got %v", *resp.Brew.WaterWeight)
+	}
+	if got := *resp.Brew.Pours[0].WaterAmount; got != 47.1 {
+		t.Errorf("expected first pour scaled to 47.1, got %v", got)
+	}
+	if *b.Pours[0].WaterAmount != 50 {
+		t.Error("expected the original brew's pours untouched")
+	}
+}
+
+func TestGetSuggestion_StartsFromBestWhenLatestFellShort(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("coffee-1", "user-123", "Kenya AA", "Roaster", nil)
+	best := seedBrew(repo, "brew-1", "user-123", "coffee-1", "2026-01-01", intPtr(8))
+	best.GrindSize = floatPtr(4)
+	latest := seedBrew(repo, "brew-2", "user-123", "coffee-1", "2026-01-05", intPtr(5))
+	latest.GrindSize = floatPtr(7)
+
+	resp := getSuggestion(t, repo, "coffee-1")
+
+	if resp.Source != SuggestionSourceBest || *resp.BasedOnBrewID != "brew-1" {
+		t.Errorf("expected best brew-1 as basis, got %q %v", resp.Source, resp.BasedOnBrewID)
+	}
+	if *resp.Brew.GrindSize != 4 {
+		t.Errorf("expected grind size from best brew, got %v", *resp.Brew.GrindSize)
+	}
+	if resp.Diagnosis != DiagnosisInsufficientData || len(resp.Changes) != 0 {
+		t.Errorf("expected no changes without outcome data, got %q %+v", resp.Diagnosis, resp.Changes)
+	}
+}
+
+func TestGetSuggestion_NoBrews(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("coffee-1", "user-123", "Kenya AA", "Roaster", nil)
+
+	resp := getSuggestion(t, repo, "coffee-1")
+
+	if resp.Brew != nil || resp.BasedOnBrewID != nil {
+		t.Errorf("expected no brew, got %+v", resp.Brew)
+	}
+	if resp.Diagnosis != DiagnosisInsufficientData {
+		t.Errorf("expected insufficient_data, got %q", resp.Diagnosis)
+	}
+}
+
+func TestGetSuggestion_CoffeeNotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/nonexistent/suggestion", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestGetSuggestion_RepoError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/coffee-1/suggestion", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+// --- Control Chart Tests ---
+
+func seedMeasuredBrew(repo *mockRepo, id, brewDate, method string, coffeeWeight, tds float64) *Brew {
+	b := seedBrew(repo, id, "user-123", "c-1", brewDate, intPtr(7))
+	b.Method = method
+	b.CoffeeWeight = floatPtr(coffeeWeight)
+	b.TDS = floatPtr(tds)
+	return b
+}
+
+func TestControlChart_ClassifiesBrews(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	under := seedMeasuredBrew(repo, "b-1", "2026-01-10", MethodPourOver, 15, 1.3)
+	under.CoffeeMl = floatPtr(200) // EY 17.33
+	espresso := seedMeasuredBrew(repo, "b-2", "2026-01-05", MethodEspresso, 18, 10)
+	espresso.YieldWeight = floatPtr(36) // EY 20
+	seedBrew(repo, "b-3", "user-123", "c-1", "2026-01-07", intPtr(6))
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews/control-chart", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ControlChartResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.SCA != SCATargets || resp.Targets != SCATargets {
+		t.Errorf("expected SCA box for both, got %+v and %+v", resp.SCA, resp.Targets)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("expected 2 measured brews, got %d", len(resp.Items))
+	}
+
+	first, second := resp.Items[0], resp.Items[1]
+	if first.BrewID != "b-2" || first.ExtractionClass != ExtractionIdeal || first.StrengthClass != nil {
+		t.Errorf("expected espresso first, ideal, without strength class, got %+v", first)
+	}
+	if second.BrewID != "b-1" || second.ExtractionClass != ExtractionUnder {
+		t.Errorf("expected pour-over under-extracted, got %+v", second)
+	}
+	if second.StrengthClass == nil || *second.StrengthClass != StrengthIdeal {
+		t.Errorf("expected ideal strength, got %v", second.StrengthClass)
+	}
+	if second.ExtractionYield != 17.33 || second.TDS != 1.3 {
+		t.Errorf("expected EY 17.33 at TDS 1.3, got %v at %v", second.ExtractionYield, second.TDS)
+	}
+}
+
+func TestControlChart_UsesUserTargets(t *testing.T) {
+	repo := newMockRepo()
+	repo.targets = Targets{EYMin: 17, EYMax: 21, TDSMin: 1.4, TDSMax: 1.6}
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	b := seedMeasuredBrew(repo, "b-1", "2026-01-10", MethodPourOver, 15, 1.3)
+	b.CoffeeMl = floatPtr(200)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews/control-chart?method=pour_over", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp ControlChartResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Targets.EYMin != 17 || resp.SCA.EYMin != 18 {
+		t.Errorf("expected user and SCA boxes, got %+v and %+v", resp.Targets, resp.SCA)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].ExtractionClass != ExtractionIdeal || *resp.Items[0].StrengthClass != StrengthWeak {
+		t.Errorf("expected ideal extraction and weak strength against user targets, got %+v", resp.Items)
+	}
+}
+
+func TestControlChart_FiltersByMethod(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedMeasuredBrew(repo, "b-1", "2026-01-10", MethodPourOver, 15, 1.3)
+	espresso := seedMeasuredBrew(repo, "b-2", "2026-01-05", MethodEspresso, 18, 10)
+	espresso.YieldWeight = floatPtr(36)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews/control-chart?method=espresso", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ControlChartResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].BrewID != "b-2" {
+		t.Errorf("expected only the espresso brew, got %+v", resp.Items)
+	}
+}
+
+func TestControlChart_InvalidFilters(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews/control-chart?score_lte=8.5", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestControlChart_RepoError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/brews/control-chart", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestCreate_IncludesClassification(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_id":"c-1","coffee_weight":15,"ratio":15,"coffee_ml":200,"tds":1.5}`
+	req := authRequest(http.MethodPost, "/api/v1/brews", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Brew
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.ExtractionClass == nil || *resp.ExtractionClass != ExtractionIdeal {
+		t.Errorf("expected ideal extraction at EY 20, got %v", resp.ExtractionClass)
+	}
+	if resp.StrengthClass == nil || *resp.StrengthClass != StrengthStrong {
+		t.Errorf("expected strong at TDS 1.5, got %v", resp.StrengthClass)
+	}
+}
+
+func TestClassify_NoMeasurements(t *testing.T) {
+	b := &Brew{Method: MethodPourOver}
+	b.Classify(SCATargets)
+	if b.ExtractionClass != nil || b.StrengthClass != nil {
+		t.Errorf("expected no classes without TDS, got %v and %v", b.ExtractionClass, b.StrengthClass)
+	}
+}
+
+func TestList_FilterByTags(t *testing.T) {
+	repo := newMockRepo()
+	repo.addCoffee("c-1", "user-123", "Kiamaina", "Cata", nil)
+	seedBrew(repo, "b-1", "user-123", "c-1", "2026-01-15", nil).Tags = []string{"Competition", "guest"}
+	seedBrew(repo, "b-2", "user-123", "c-1", "2026-01-16", nil).Tags = []string{"guest"}
+	seedBrew(repo, "b-3", "user-123", "c-1", "2026-01-17", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"tags=competition,guest", 2},
+		{"tags=competition,guest&tag_match=all", 1},
+		{"tags=unknown", 0},
+	}
+	for _, tt := range tests {
+		req := authRequest(http.MethodGet, "/api/v1/brews?"+tt.query, "")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Items []Brew `json:"items"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Items) != tt.want {
+			t.Errorf("%s: expected %d brews, got %d", tt.query, tt.want, len(resp.Items))
+		}
+	}
+}
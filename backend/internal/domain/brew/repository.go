@@ -2,18 +2,21 @@ package brew
 
 import (
 	"context"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/brewfilter"
+	"github.com/poimgs/coffee-tracker/backend/internal/grind"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
 )
 
+// ListFilters select the brews to list. They are shared with the stats
+// endpoints.
+type ListFilters = brewfilter.Filters
+
 type ListParams struct {
-	Page     int
-	PerPage  int
-	Sort     string
-	CoffeeID string
-	ScoreGTE *int
-	ScoreLTE *int
-	HasTDS   *bool
-	DateFrom *string
-	DateTo   *string
+	Page    int
+	PerPage int
+	Sort    string
+	ListFilters
 }
 
 type Repository interface {
@@ -24,4 +27,8 @@ type Repository interface {
 	Update(ctx context.Context, userID, id string, req UpdateRequest) (*Brew, error)
 	Delete(ctx context.Context, userID, id string) error
 	GetReference(ctx context.Context, userID, coffeeID string) (*Brew, string, error)
+	TranslateGrind(ctx context.Context, userID, fromGrinderID, toGrinderID string, setting float64) (*grind.Translation, error)
+	SuggestionHistory(ctx context.Context, userID, coffeeID string) ([]Brew, error)
+	GrindScale(ctx context.Context, userID, grinderID string) (*validate.GrindScale, error)
+	ControlChart(ctx context.Context, userID string, params ListParams) (*ControlChartResponse, error)
 }
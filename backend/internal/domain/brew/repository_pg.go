@@ -9,6 +9,12 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/brewfilter"
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/grind"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
 )
 
 type PgRepository struct {
@@ -19,18 +25,24 @@ func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
 	return &PgRepository{pool: pool}
 }
 
-const brewColumns = `b.id, b.user_id, b.coffee_id, b.brew_date, b.days_off_roast,
-	b.coffee_weight, b.ratio, b.grind_size, b.water_temperature, b.filter_paper_id, b.dripper_id,
+var brewColumns = `b.id, b.user_id, b.coffee_id, b.brew_date, b.days_off_roast,
+	b.coffee_weight, b.ratio, b.grind_size, b.water_temperature, b.filter_paper_id, b.dripper_id, b.recipe_id,
 	b.total_brew_time, b.technique_notes,
+	b.method, b.yield_weight, b.pressure, b.preinfusion_time,
+	b.steep_time, b.agitation, b.plunge, b.steep_hours, b.dilution,
 	b.coffee_ml, b.tds,
 	b.aroma_intensity, b.body_intensity, b.sweetness_intensity,
 	b.brightness_intensity, b.complexity_intensity, b.aftertaste_intensity,
 	b.overall_score, b.overall_notes, b.improvement_notes,
 	b.created_at, b.updated_at,
-	c.name AS coffee_name, c.roaster AS coffee_roaster, c.tasting_notes AS coffee_tasting_notes,
+	c.name AS coffee_name, ro.name AS coffee_roaster, c.tasting_notes AS coffee_tasting_notes,
 	c.reference_brew_id AS coffee_reference_brew_id,
 	fp.id AS fp_id, fp.name AS fp_name, fp.brand AS fp_brand,
-	d.id AS d_id, d.name AS d_name, d.brand AS d_brand`
+	d.id AS d_id, d.name AS d_name, d.brand AS d_brand,
+	g.id AS g_id, g.name AS g_name, g.brand AS g_brand,
+	wp.id AS wp_id, wp.name AS wp_name,
+	bt.ey_min, bt.ey_max, bt.tds_min, bt.tds_max,
+	` + tags.Brews.NamesSQL("b.id") + ` AS tags`
 
 func scanBrew(row pgx.Row) (*Brew, error) {
 	var b Brew
@@ -43,10 +55,18 @@ func scanBrew(row pgx.Row) (*Brew, error) {
 	var dID *string
 	var dName *string
 	var dBrand *string
+	var gID *string
+	var gName *string
+	var gBrand *string
+	var wpID *string
+	var wpName *string
+	var targets targetBounds
 	err := row.Scan(
 		&b.ID, &b.UserID, &b.CoffeeID, &brewDate, &b.DaysOffRoast,
-		&b.CoffeeWeight, &b.Ratio, &b.GrindSize, &b.WaterTemperature, &filterPaperID, &dripperID,
+		&b.CoffeeWeight, &b.Ratio, &b.GrindSize, &b.WaterTemperature, &filterPaperID, &dripperID, &b.RecipeID,
 		&b.TotalBrewTime, &b.TechniqueNotes,
+		&b.Method, &b.YieldWeight, &b.Pressure, &b.PreinfusionTime,
+		&b.SteepTime, &b.Agitation, &b.Plunge, &b.SteepHours, &b.Dilution,
 		&b.CoffeeMl, &b.TDS,
 		&b.AromaIntensity, &b.BodyIntensity, &b.SweetnessIntensity,
 		&b.BrightnessIntensity, &b.ComplexityIntensity, &b.AftertasteIntensity,
@@ -56,6 +76,10 @@ func scanBrew(row pgx.Row) (*Brew, error) {
 		&b.CoffeeReferenceBrewID,
 		&fpID, &fpName, &fpBrand,
 		&dID, &dName, &dBrand,
+		&gID, &gName, &gBrand,
+		&wpID, &wpName,
+		&targets.EYMin, &targets.EYMax, &targets.TDSMin, &targets.TDSMax,
+		&b.Tags,
 	)
 	if err != nil {
 		return nil, err
@@ -79,8 +103,24 @@ func scanBrew(row pgx.Row) (*Brew, error) {
 		}
 	}
 
-	b.WaterWeight = ComputeWaterWeight(b.CoffeeWeight, b.Ratio)
-	b.ExtractionYield = ComputeExtractionYield(b.CoffeeMl, b.TDS, b.CoffeeWeight)
+	if gID != nil && *gID != "" {
+		b.Grinder = &Grinder{
+			ID:    *gID,
+			Name:  derefStr(gName),
+			Brand: gBrand,
+		}
+	}
+
+	if wpID != nil && *wpID != "" {
+		b.WaterProfile = &WaterProfile{
+			ID:   *wpID,
+			Name: derefStr(wpName),
+		}
+	}
+
+	b.WaterWeight = ComputeWaterWeight(&b)
+	b.ExtractionYield = ComputeExtractionYield(&b)
+	b.Classify(targets.resolve())
 
 	return &b, nil
 }
@@ -95,8 +135,12 @@ func derefStr(s *string) string {
 const brewSelectBase = `SELECT %s
 	FROM brews b
 	JOIN coffees c ON c.id = b.coffee_id
+	JOIN roasters ro ON ro.id = c.roaster_id
 	LEFT JOIN filter_papers fp ON fp.id = b.filter_paper_id
-	LEFT JOIN drippers d ON d.id = b.dripper_id`
+	LEFT JOIN drippers d ON d.id = b.dripper_id
+	LEFT JOIN grinders g ON g.id = b.grinder_id
+	LEFT JOIN water_profiles wp ON wp.id = b.water_profile_id
+	LEFT JOIN brew_targets bt ON bt.user_id = b.user_id`
 
 func (r *PgRepository) loadPours(ctx context.Context, brewID string) ([]Pour, error) {
 	rows, err := r.pool.Query(ctx,
@@ -177,6 +221,128 @@ func (r *PgRepository) savePours(ctx context.Context, tx pgx.Tx, brewID string,
 	return nil
 }
 
+// loadRecipe fetches a user's recipe and its pours within the given transaction.
+func loadRecipe(ctx context.Context, tx pgx.Tx, userID, recipeID string) (*recipeTemplate, error) {
+	var rec recipeTemplate
+	err := tx.QueryRow(ctx,
+		`SELECT coffee_weight, ratio, grind_size, water_temperature, filter_paper_id, dripper_id
+		 FROM recipes WHERE id = $1 AND user_id = $2`,
+		recipeID, userID,
+	).Scan(&rec.CoffeeWeight, &rec.Ratio, &rec.GrindSize, &rec.WaterTemperature, &rec.FilterPaperID, &rec.DripperID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrRecipeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx,
+		`SELECT pour_number, water_amount, pour_style, wait_time
+		 FROM recipe_pours WHERE recipe_id = $1 ORDER BY pour_number`,
+		recipeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p PourRequest
+		if err := rows.Scan(&p.PourNumber, &p.WaterAmount, &p.PourStyle, &p.WaitTime); err != nil {
+			return nil, err
+		}
+		rec.Pours = append(rec.Pours, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &rec, nil
+}
+
+// verifyRecipe checks that the referenced recipe belongs to the user.
+func verifyRecipe(ctx context.Context, tx pgx.Tx, userID, recipeID string) error {
+	var exists bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM recipes WHERE id::text = $1 AND user_id = $2)`,
+		recipeID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrRecipeNotFound
+	}
+	return nil
+}
+
+// verifyEquipment checks that the referenced filter paper, dripper and water
+// profile belong to the user and have not been soft-deleted.
+func verifyEquipment(ctx context.Context, tx pgx.Tx, userID string, filterPaperID, dripperID, waterProfileID *string) error {
+	if filterPaperID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "filter_papers", userID, *filterPaperID, ErrFilterPaperNotFound); err != nil {
+			return err
+		}
+	}
+	if dripperID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "drippers", userID, *dripperID, ErrDripperNotFound); err != nil {
+			return err
+		}
+	}
+	if waterProfileID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "water_profiles", userID, *waterProfileID, ErrWaterProfileNotFound); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyGrinder checks that the referenced grinder belongs to the user, is not
+// soft-deleted, and accepts the requested grind size.
+func verifyGrinder(ctx context.Context, tx pgx.Tx, userID string, grinderID *string, grindSize *float64) error {
+	if grinderID == nil {
+		return nil
+	}
+
+	var scale validate.GrindScale
+	err := tx.QueryRow(ctx,
+		`SELECT scale_min, scale_max, scale_step, stepless, micron_min, micron_max
+		 FROM grinders WHERE id::text = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		*grinderID, userID,
+	).Scan(&scale.Min, &scale.Max, &scale.Step, &scale.Stepless, &scale.MicronMin, &scale.MicronMax)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrGrinderNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return validate.GrindSizeError("grind_size", grindSize, scale)
+}
+
+// adjustRemaining adds grams to a coffee's remaining inventory; brews pass a
+// negative dose to debit the bag. Coffees without a bag weight have no balance
+// and are left alone.
+func adjustRemaining(ctx context.Context, tx pgx.Tx, userID, coffeeID string, grams *float64) error {
+	if grams == nil {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`UPDATE coffees SET remaining_grams = remaining_grams + $1
+		 WHERE id = $2 AND user_id = $3 AND remaining_grams IS NOT NULL`,
+		*grams, coffeeID, userID,
+	)
+	return err
+}
+
+func negate(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	n := -*v
+	return &n
+}
+
 func parseSort(sort string) string {
 	if sort == "" {
 		return "b.brew_date DESC, b.created_at DESC"
@@ -207,38 +373,7 @@ func parseSort(sort string) string {
 }
 
 func (r *PgRepository) List(ctx context.Context, userID string, params ListParams) ([]Brew, int, error) {
-	conditions := []string{"b.user_id = $1"}
-	args := []interface{}{userID}
-	argIdx := 2
-
-	if params.CoffeeID != "" {
-		conditions = append(conditions, fmt.Sprintf("b.coffee_id = $%d", argIdx))
-		args = append(args, params.CoffeeID)
-		argIdx++
-	}
-	if params.ScoreGTE != nil {
-		conditions = append(conditions, fmt.Sprintf("b.overall_score >= $%d", argIdx))
-		args = append(args, *params.ScoreGTE)
-		argIdx++
-	}
-	if params.ScoreLTE != nil {
-		conditions = append(conditions, fmt.Sprintf("b.overall_score <= $%d", argIdx))
-		args = append(args, *params.ScoreLTE)
-		argIdx++
-	}
-	if params.HasTDS != nil && *params.HasTDS {
-		conditions = append(conditions, "b.tds IS NOT NULL")
-	}
-	if params.DateFrom != nil {
-		conditions = append(conditions, fmt.Sprintf("b.brew_date >= $%d", argIdx))
-		args = append(args, *params.DateFrom)
-		argIdx++
-	}
-	if params.DateTo != nil {
-		conditions = append(conditions, fmt.Sprintf("b.brew_date <= $%d", argIdx))
-		args = append(args, *params.DateTo)
-		argIdx++
-	}
+	conditions, args, argIdx := brewfilter.Conditions(userID, params.ListFilters)
 
 	where := strings.Join(conditions, " AND ")
 
@@ -309,10 +444,18 @@ func scanBrewFromRows(rows pgx.Rows) (*Brew, error) {
 	var dID *string
 	var dName *string
 	var dBrand *string
+	var gID *string
+	var gName *string
+	var gBrand *string
+	var wpID *string
+	var wpName *string
+	var targets targetBounds
 	err := rows.Scan(
 		&b.ID, &b.UserID, &b.CoffeeID, &brewDate, &b.DaysOffRoast,
-		&b.CoffeeWeight, &b.Ratio, &b.GrindSize, &b.WaterTemperature, &filterPaperID, &dripperID,
+		&b.CoffeeWeight, &b.Ratio, &b.GrindSize, &b.WaterTemperature, &filterPaperID, &dripperID, &b.RecipeID,
 		&b.TotalBrewTime, &b.TechniqueNotes,
+		&b.Method, &b.YieldWeight, &b.Pressure, &b.PreinfusionTime,
+		&b.SteepTime, &b.Agitation, &b.Plunge, &b.SteepHours, &b.Dilution,
 		&b.CoffeeMl, &b.TDS,
 		&b.AromaIntensity, &b.BodyIntensity, &b.SweetnessIntensity,
 		&b.BrightnessIntensity, &b.ComplexityIntensity, &b.AftertasteIntensity,
@@ -322,6 +465,10 @@ func scanBrewFromRows(rows pgx.Rows) (*Brew, error) {
 		&b.CoffeeReferenceBrewID,
 		&fpID, &fpName, &fpBrand,
 		&dID, &dName, &dBrand,
+		&gID, &gName, &gBrand,
+		&wpID, &wpName,
+		&targets.EYMin, &targets.EYMax, &targets.TDSMin, &targets.TDSMax,
+		&b.Tags,
 	)
 	if err != nil {
 		return nil, err
@@ -345,8 +492,24 @@ func scanBrewFromRows(rows pgx.Rows) (*Brew, error) {
 		}
 	}
 
-	b.WaterWeight = ComputeWaterWeight(b.CoffeeWeight, b.Ratio)
-	b.ExtractionYield = ComputeExtractionYield(b.CoffeeMl, b.TDS, b.CoffeeWeight)
+	if gID != nil && *gID != "" {
+		b.Grinder = &Grinder{
+			ID:    *gID,
+			Name:  derefStr(gName),
+			Brand: gBrand,
+		}
+	}
+
+	if wpID != nil && *wpID != "" {
+		b.WaterProfile = &WaterProfile{
+			ID:   *wpID,
+			Name: derefStr(wpName),
+		}
+	}
+
+	b.WaterWeight = ComputeWaterWeight(&b)
+	b.ExtractionYield = ComputeExtractionYield(&b)
+	b.Classify(targets.resolve())
 
 	return &b, nil
 }
@@ -423,6 +586,26 @@ func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequ
 	}
 	defer tx.Rollback(ctx)
 
+	// Pre-populate unset fields from the recipe
+	if req.RecipeID != nil {
+		rec, err := loadRecipe(ctx, tx, userID, *req.RecipeID)
+		if err != nil {
+			return nil, err
+		}
+		req.applyRecipe(*rec)
+		if err := validateMerged(req); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := verifyEquipment(ctx, tx, userID, req.FilterPaperID, req.DripperID, req.WaterProfileID); err != nil {
+		return nil, err
+	}
+
+	if err := verifyGrinder(ctx, tx, userID, req.GrinderID, req.GrindSize); err != nil {
+		return nil, err
+	}
+
 	// Compute days_off_roast
 	var daysOffRoast *int
 	if req.BrewDate != nil {
@@ -432,7 +615,7 @@ func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequ
 			req.CoffeeID, userID,
 		).Scan(&roastDate)
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, fmt.Errorf("coffee not found")
+			return nil, ErrCoffeeNotFound
 		}
 		if err != nil {
 			return nil, err
@@ -470,7 +653,7 @@ func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequ
 		return nil, err
 	}
 	if !coffeeExists {
-		return nil, fmt.Errorf("coffee not found")
+		return nil, ErrCoffeeNotFound
 	}
 
 	// Insert brew
@@ -479,46 +662,65 @@ func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequ
 	if brewDate == nil {
 		err = tx.QueryRow(ctx,
 			`INSERT INTO brews (user_id, coffee_id, days_off_roast,
-				coffee_weight, ratio, grind_size, water_temperature, filter_paper_id, dripper_id,
+				coffee_weight, ratio, grind_size, water_temperature, filter_paper_id, dripper_id, recipe_id,
 				total_brew_time, technique_notes, coffee_ml, tds,
 				aroma_intensity, body_intensity, sweetness_intensity,
 				brightness_intensity, complexity_intensity, aftertaste_intensity,
-				overall_score, overall_notes, improvement_notes)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+				overall_score, overall_notes, improvement_notes, grinder_id, water_profile_id,
+				method, yield_weight, pressure, preinfusion_time,
+				steep_time, agitation, plunge, steep_hours, dilution)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25,
+				$26, $27, $28, $29, $30, $31, $32, $33, $34)
 			 RETURNING id`,
 			userID, req.CoffeeID, daysOffRoast,
-			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID,
+			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID, req.RecipeID,
 			req.TotalBrewTime, req.TechniqueNotes, req.CoffeeMl, req.TDS,
 			req.AromaIntensity, req.BodyIntensity, req.SweetnessIntensity,
 			req.BrightnessIntensity, req.ComplexityIntensity, req.AftertasteIntensity,
-			req.OverallScore, req.OverallNotes, req.ImprovementNotes,
+			req.OverallScore, req.OverallNotes, req.ImprovementNotes, req.GrinderID, req.WaterProfileID,
+			req.Method, req.YieldWeight, req.Pressure, req.PreinfusionTime,
+			req.SteepTime, req.Agitation, req.Plunge, req.SteepHours, req.Dilution,
 		).Scan(&brewID)
 	} else {
 		err = tx.QueryRow(ctx,
 			`INSERT INTO brews (user_id, coffee_id, brew_date, days_off_roast,
-				coffee_weight, ratio, grind_size, water_temperature, filter_paper_id, dripper_id,
+				coffee_weight, ratio, grind_size, water_temperature, filter_paper_id, dripper_id, recipe_id,
 				total_brew_time, technique_notes, coffee_ml, tds,
 				aroma_intensity, body_intensity, sweetness_intensity,
 				brightness_intensity, complexity_intensity, aftertaste_intensity,
-				overall_score, overall_notes, improvement_notes)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+				overall_score, overall_notes, improvement_notes, grinder_id, water_profile_id,
+				method, yield_weight, pressure, preinfusion_time,
+				steep_time, agitation, plunge, steep_hours, dilution)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26,
+				$27, $28, $29, $30, $31, $32, $33, $34, $35)
 			 RETURNING id`,
 			userID, req.CoffeeID, *brewDate, daysOffRoast,
-			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID,
+			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID, req.RecipeID,
 			req.TotalBrewTime, req.TechniqueNotes, req.CoffeeMl, req.TDS,
 			req.AromaIntensity, req.BodyIntensity, req.SweetnessIntensity,
 			req.BrightnessIntensity, req.ComplexityIntensity, req.AftertasteIntensity,
-			req.OverallScore, req.OverallNotes, req.ImprovementNotes,
+			req.OverallScore, req.OverallNotes, req.ImprovementNotes, req.GrinderID, req.WaterProfileID,
+			req.Method, req.YieldWeight, req.Pressure, req.PreinfusionTime,
+			req.SteepTime, req.Agitation, req.Plunge, req.SteepHours, req.Dilution,
 		).Scan(&brewID)
 	}
 	if err != nil {
-		return nil, err
+		return nil, domainerr.FromPg(err, pgErrors)
 	}
 
 	// Save pours
 	if err := r.savePours(ctx, tx, brewID, req.Pours); err != nil {
 		return nil, err
 	}
+	if len(req.Tags) > 0 {
+		if err := tags.Set(ctx, tx, tags.Brews, userID, brewID, req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := adjustRemaining(ctx, tx, userID, req.CoffeeID, negate(req.CoffeeWeight)); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
@@ -534,17 +736,33 @@ func (r *PgRepository) Update(ctx context.Context, userID, id string, req Update
 	}
 	defer tx.Rollback(ctx)
 
-	// Verify brew exists and belongs to user
-	var exists bool
+	// Verify brew exists and belongs to user, and lock it so the inventory
+	// credit below uses the dose being replaced
+	var oldCoffeeID string
+	var oldCoffeeWeight *float64
 	err = tx.QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM brews WHERE id = $1 AND user_id = $2)`,
+		`SELECT coffee_id, coffee_weight FROM brews WHERE id = $1 AND user_id = $2 FOR UPDATE`,
 		id, userID,
-	).Scan(&exists)
+	).Scan(&oldCoffeeID, &oldCoffeeWeight)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	if !exists {
-		return nil, nil
+
+	if req.RecipeID != nil {
+		if err := verifyRecipe(ctx, tx, userID, *req.RecipeID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := verifyEquipment(ctx, tx, userID, req.FilterPaperID, req.DripperID, req.WaterProfileID); err != nil {
+		return nil, err
+	}
+
+	if err := verifyGrinder(ctx, tx, userID, req.GrinderID, req.GrindSize); err != nil {
+		return nil, err
 	}
 
 	// Compute days_off_roast
@@ -574,48 +792,74 @@ func (r *PgRepository) Update(ctx context.Context, userID, id string, req Update
 	if req.BrewDate != nil {
 		tag = `UPDATE brews SET coffee_id = $1, brew_date = $2, days_off_roast = $3,
 			coffee_weight = $4, ratio = $5, grind_size = $6, water_temperature = $7, filter_paper_id = $8, dripper_id = $9,
-			total_brew_time = $10, technique_notes = $11, coffee_ml = $12, tds = $13,
-			aroma_intensity = $14, body_intensity = $15, sweetness_intensity = $16,
-			brightness_intensity = $17, complexity_intensity = $18, aftertaste_intensity = $19,
-			overall_score = $20, overall_notes = $21, improvement_notes = $22,
+			recipe_id = $10,
+			total_brew_time = $11, technique_notes = $12, coffee_ml = $13, tds = $14,
+			aroma_intensity = $15, body_intensity = $16, sweetness_intensity = $17,
+			brightness_intensity = $18, complexity_intensity = $19, aftertaste_intensity = $20,
+			overall_score = $21, overall_notes = $22, improvement_notes = $23,
+			grinder_id = $24, water_profile_id = $25,
+			method = $26, yield_weight = $27, pressure = $28, preinfusion_time = $29,
+			steep_time = $30, agitation = $31, plunge = $32, steep_hours = $33, dilution = $34,
 			updated_at = NOW()
-			WHERE id = $23 AND user_id = $24`
+			WHERE id = $35 AND user_id = $36`
 		_, err = tx.Exec(ctx, tag,
 			req.CoffeeID, *req.BrewDate, daysOffRoast,
-			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID,
+			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID, req.RecipeID,
 			req.TotalBrewTime, req.TechniqueNotes, req.CoffeeMl, req.TDS,
 			req.AromaIntensity, req.BodyIntensity, req.SweetnessIntensity,
 			req.BrightnessIntensity, req.ComplexityIntensity, req.AftertasteIntensity,
-			req.OverallScore, req.OverallNotes, req.ImprovementNotes,
+			req.OverallScore, req.OverallNotes, req.ImprovementNotes, req.GrinderID, req.WaterProfileID,
+			req.Method, req.YieldWeight, req.Pressure, req.PreinfusionTime,
+			req.SteepTime, req.Agitation, req.Plunge, req.SteepHours, req.Dilution,
 			id, userID,
 		)
 	} else {
 		tag = `UPDATE brews SET coffee_id = $1, days_off_roast = $2,
 			coffee_weight = $3, ratio = $4, grind_size = $5, water_temperature = $6, filter_paper_id = $7, dripper_id = $8,
-			total_brew_time = $9, technique_notes = $10, coffee_ml = $11, tds = $12,
-			aroma_intensity = $13, body_intensity = $14, sweetness_intensity = $15,
-			brightness_intensity = $16, complexity_intensity = $17, aftertaste_intensity = $18,
-			overall_score = $19, overall_notes = $20, improvement_notes = $21,
+			recipe_id = $9,
+			total_brew_time = $10, technique_notes = $11, coffee_ml = $12, tds = $13,
+			aroma_intensity = $14, body_intensity = $15, sweetness_intensity = $16,
+			brightness_intensity = $17, complexity_intensity = $18, aftertaste_intensity = $19,
+			overall_score = $20, overall_notes = $21, improvement_notes = $22,
+			grinder_id = $23, water_profile_id = $24,
+			method = $25, yield_weight = $26, pressure = $27, preinfusion_time = $28,
+			steep_time = $29, agitation = $30, plunge = $31, steep_hours = $32, dilution = $33,
 			updated_at = NOW()
-			WHERE id = $22 AND user_id = $23`
+			WHERE id = $34 AND user_id = $35`
 		_, err = tx.Exec(ctx, tag,
 			req.CoffeeID, daysOffRoast,
-			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID,
+			req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature, req.FilterPaperID, req.DripperID, req.RecipeID,
 			req.TotalBrewTime, req.TechniqueNotes, req.CoffeeMl, req.TDS,
 			req.AromaIntensity, req.BodyIntensity, req.SweetnessIntensity,
 			req.BrightnessIntensity, req.ComplexityIntensity, req.AftertasteIntensity,
-			req.OverallScore, req.OverallNotes, req.ImprovementNotes,
+			req.OverallScore, req.OverallNotes, req.ImprovementNotes, req.GrinderID, req.WaterProfileID,
+			req.Method, req.YieldWeight, req.Pressure, req.PreinfusionTime,
+			req.SteepTime, req.Agitation, req.Plunge, req.SteepHours, req.Dilution,
 			id, userID,
 		)
 	}
 	if err != nil {
-		return nil, err
+		return nil, domainerr.FromPg(err, pgErrors)
 	}
 
 	// Replace pours
 	if err := r.savePours(ctx, tx, id, req.Pours); err != nil {
 		return nil, err
 	}
+	if req.Tags != nil {
+		if err := tags.Set(ctx, tx, tags.Brews, userID, id, req.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	// Return the old dose to its bag and take the new one, which may come
+	// from a different coffee
+	if err := adjustRemaining(ctx, tx, userID, oldCoffeeID, oldCoffeeWeight); err != nil {
+		return nil, err
+	}
+	if err := adjustRemaining(ctx, tx, userID, req.CoffeeID, negate(req.CoffeeWeight)); err != nil {
+		return nil, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
@@ -625,17 +869,30 @@ func (r *PgRepository) Update(ctx context.Context, userID, id string, req Update
 }
 
 func (r *PgRepository) Delete(ctx context.Context, userID, id string) error {
-	result, err := r.pool.Exec(ctx,
-		`DELETE FROM brews WHERE id = $1 AND user_id = $2`,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var coffeeID string
+	var coffeeWeight *float64
+	err = tx.QueryRow(ctx,
+		`DELETE FROM brews WHERE id = $1 AND user_id = $2 RETURNING coffee_id, coffee_weight`,
 		id, userID,
-	)
+	).Scan(&coffeeID, &coffeeWeight)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+
+	if err := adjustRemaining(ctx, tx, userID, coffeeID, coffeeWeight); err != nil {
+		return err
 	}
-	return nil
+
+	return tx.Commit(ctx)
 }
 
 func (r *PgRepository) GetReference(ctx context.Context, userID, coffeeID string) (*Brew, string, error) {
@@ -646,7 +903,7 @@ func (r *PgRepository) GetReference(ctx context.Context, userID, coffeeID string
 		coffeeID, userID,
 	).Scan(&refBrewID)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, "", fmt.Errorf("coffee not found")
+		return nil, "", ErrCoffeeNotFound
 	}
 	if err != nil {
 		return nil, "", err
@@ -683,3 +940,125 @@ func (r *PgRepository) GetReference(ctx context.Context, userID, coffeeID string
 
 	return b, "latest", nil
 }
+
+// TranslateGrind converts a setting between two of the user's grinders. It
+// returns nil when no translation is possible, such as when the source grinder
+// has been deleted or the grinders lack calibration data.
+func (r *PgRepository) TranslateGrind(ctx context.Context, userID, fromGrinderID, toGrinderID string, setting float64) (*grind.Translation, error) {
+	t, err := grind.TranslateStored(ctx, r.pool, userID, fromGrinderID, toGrinderID, setting)
+	switch {
+	case errors.Is(err, grind.ErrTargetNotFound):
+		return nil, ErrGrinderNotFound
+	case errors.Is(err, grind.ErrGrinderNotFound), errors.Is(err, domainerr.ErrInvalid):
+		return nil, nil
+	}
+	return t, err
+}
+
+// SuggestionHistory returns the coffee's most recent brews with their pours,
+// newest first.
+func (r *PgRepository) SuggestionHistory(ctx context.Context, userID, coffeeID string) ([]Brew, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM coffees WHERE id::text = $1 AND user_id = $2)`,
+		coffeeID, userID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrCoffeeNotFound
+	}
+
+	query := fmt.Sprintf(
+		`%s WHERE b.coffee_id = $1 AND b.user_id = $2 ORDER BY b.brew_date DESC, b.created_at DESC LIMIT $3`,
+		fmt.Sprintf(brewSelectBase, brewColumns),
+	)
+
+	rows, err := r.pool.Query(ctx, query, coffeeID, userID, SuggestionHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	brews := []Brew{}
+	var brewIDs []string
+	for rows.Next() {
+		b, err := scanBrewFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		brewIDs = append(brewIDs, b.ID)
+		brews = append(brews, *b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	poursMap, err := r.loadPoursForBrews(ctx, brewIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range brews {
+		if pours, ok := poursMap[brews[i].ID]; ok {
+			brews[i].Pours = pours
+		} else {
+			brews[i].Pours = []Pour{}
+		}
+	}
+
+	return brews, nil
+}
+
+// GrindScale returns the scale of one of the user's grinders, or nil if the
+// grinder has been deleted.
+func (r *PgRepository) GrindScale(ctx context.Context, userID, grinderID string) (*validate.GrindScale, error) {
+	g, err := grind.Load(ctx, r.pool, userID, grinderID)
+	if err != nil || g == nil {
+		return nil, err
+	}
+	return &g.Scale, nil
+}
+
+// ControlChart ignores pagination and sorting: every matching brew with a
+// TDS reading is returned, oldest first.
+func (r *PgRepository) ControlChart(ctx context.Context, userID string, params ListParams) (*ControlChartResponse, error) {
+	var bounds targetBounds
+	err := r.pool.QueryRow(ctx,
+		`SELECT ey_min, ey_max, tds_min, tds_max FROM brew_targets WHERE user_id = $1`,
+		userID,
+	).Scan(&bounds.EYMin, &bounds.EYMax, &bounds.TDSMin, &bounds.TDSMax)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	conditions, args, _ := brewfilter.Conditions(userID, params.ListFilters)
+	conditions = append(conditions, "b.tds IS NOT NULL")
+	query := fmt.Sprintf(
+		`%s WHERE %s ORDER BY b.brew_date, b.created_at`,
+		fmt.Sprintf(brewSelectBase, brewColumns),
+		strings.Join(conditions, " AND "),
+	)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resp := &ControlChartResponse{
+		SCA:     SCATargets,
+		Targets: bounds.resolve(),
+		Items:   []ControlChartPoint{},
+	}
+	for rows.Next() {
+		b, err := scanBrewFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if p := controlChartPoint(b); p != nil {
+			resp.Items = append(resp.Items, *p)
+		}
+	}
+	return resp, rows.Err()
+}
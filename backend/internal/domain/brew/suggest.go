@@ -0,0 +1,408 @@
+package brew
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// SuggestionHistoryLimit is how many of a coffee's recent brews the dial-in
+// suggestion looks at.
+const SuggestionHistoryLimit = 20
+
+// Dial-in step sizes. Extraction and strength are judged by the brew's
+// control chart classes, against the user's targets.
+const (
+	// farOffEY is how far outside the EY target a brew must be before the
+	// temperature moves along with the grind.
+	farOffEY = 2.0
+
+	temperatureStep         = 2.0
+	minSuggestedTemperature = 80.0
+	ratioStep               = 1.0
+
+	// A brew at least revertScoreGap points below the coffee's best brew is
+	// abandoned in favour of the best one.
+	revertScoreGap = 2
+
+	// Stepless grinders move by this fraction of their scale.
+	steplessGrindFraction = 20.0
+
+	highBrightness = 8
+	lowSweetness   = 4
+)
+
+// Diagnoses of a brew's extraction.
+const (
+	DiagnosisUnderExtracted   = "under_extracted"
+	DiagnosisOverExtracted    = "over_extracted"
+	DiagnosisMixed            = "mixed"
+	DiagnosisBalanced         = "balanced"
+	DiagnosisInsufficientData = "insufficient_data"
+)
+
+// Sources of the brew a suggestion starts from.
+const (
+	SuggestionSourceLatest = "latest"
+	SuggestionSourceBest   = "best"
+)
+
+// Keywords looked for in improvement notes. Words are matched whole, ignoring
+// case.
+var (
+	underKeywords  = []string{"sour", "acidic", "sharp", "thin", "grassy", "salty", "underextracted", "under-extracted"}
+	overKeywords   = []string{"bitter", "astringent", "harsh", "drying", "chalky", "overextracted", "over-extracted"}
+	weakKeywords   = []string{"weak", "watery"}
+	strongKeywords = []string{"strong", "concentrated"}
+)
+
+// SuggestedChange is one parameter the suggestion changes from the brew it
+// starts from.
+type SuggestedChange struct {
+	Field     string   `json:"field"`
+	From      *float64 `json:"from"`
+	To        *float64 `json:"to"`
+	Rationale string   `json:"rationale"`
+}
+
+// SuggestionResponse is the GET /coffees/:id/suggestion response. Brew has the
+// same shape as the reference brew so the brew form can prefill from it; only
+// its setup fields are filled in.
+type SuggestionResponse struct {
+	Brew          *Brew             `json:"brew"`
+	Source        string            `json:"source"`
+	BasedOnBrewID *string           `json:"based_on_brew_id"`
+	Diagnosis     string            `json:"diagnosis"`
+	Summary       string            `json:"summary"`
+	Changes       []SuggestedChange `json:"changes"`
+}
+
+// signals collects the evidence for each direction. Votes weigh measured
+// extraction over taste.
+type signals struct {
+	underVotes, overVotes int
+	under, over           []string
+	weak, strong          []string
+	measured              bool
+}
+
+// Suggest recommends the next recipe for a coffee from its brews, newest
+// first. It starts from the latest brew, or from the best-scoring brew when
+// the latest fell well short of it, and adjusts grind, temperature and ratio
+// with rule-based extraction heuristics. scale is the scale of the starting
+// brew's grinder, or nil when unknown.
+func Suggest(history []Brew, scale *validate.GrindScale) SuggestionResponse {
+	if len(history) == 0 {
+		return SuggestionResponse{
+			Diagnosis: DiagnosisInsufficientData,
+			Summary:   "Log a brew of this coffee to get a suggestion.",
+			Changes:   []SuggestedChange{},
+		}
+	}
+
+	basis, source, summary := pickBasis(history)
+	s := diagnose(basis)
+	next := suggestionTemplate(basis)
+
+	resp := SuggestionResponse{
+		Brew:          next,
+		Source:        source,
+		BasedOnBrewID: &basis.ID,
+		Changes:       []SuggestedChange{},
+	}
+
+	switch {
+	case s.underVotes > s.overVotes:
+		resp.Diagnosis = DiagnosisUnderExtracted
+		summary += fmt.Sprintf("It looks under-extracted: %s.", strings.Join(s.under, ", "))
+		resp.Changes = adjustExtraction(next, basis, scale, true, strings.Join(s.under, ", "))
+	case s.overVotes > s.underVotes:
+		resp.Diagnosis = DiagnosisOverExtracted
+		summary += fmt.Sprintf("It looks over-extracted: %s.", strings.Join(s.over, ", "))
+		resp.Changes = adjustExtraction(next, basis, scale, false, strings.Join(s.over, ", "))
+	case s.underVotes > 0:
+		resp.Diagnosis = DiagnosisMixed
+		summary += fmt.Sprintf("Signs point both ways (%s; %s), so extraction is left alone.",
+			strings.Join(s.under, ", "), strings.Join(s.over, ", "))
+	case s.measured || basis.ImprovementNotes != nil:
+		resp.Diagnosis = DiagnosisBalanced
+		summary += "Extraction looks on target."
+	default:
+		resp.Diagnosis = DiagnosisInsufficientData
+		summary += "Record TDS, sensory scores or improvement notes to get extraction advice."
+	}
+
+	if c := adjustStrength(next, basis, s); c != nil {
+		resp.Changes = append(resp.Changes, *c)
+	}
+
+	resp.Summary = summary
+	return resp
+}
+
+// pickBasis returns the latest brew, unless the coffee's best-scoring brew
+// beat it by revertScoreGap or more.
+func pickBasis(history []Brew) (Brew, string, string) {
+	latest := history[0]
+	best := latest
+	for _, b := range history[1:] {
+		if b.OverallScore != nil && (best.OverallScore == nil || *b.OverallScore > *best.OverallScore) {
+			best = b
+		}
+	}
+
+	if best.ID != latest.ID && latest.OverallScore != nil &&
+		*best.OverallScore-*latest.OverallScore >= revertScoreGap {
+		return best, SuggestionSourceBest, fmt.Sprintf(
+			"Starting from your brew of %s, which scored %d against %d for the latest. ",
+			best.BrewDate, *best.OverallScore, *latest.OverallScore)
+	}
+	return latest, SuggestionSourceLatest, fmt.Sprintf("Starting from your latest brew of %s. ", latest.BrewDate)
+}
+
+func diagnose(b Brew) signals {
+	var s signals
+
+	if b.ExtractionClass != nil {
+		s.measured = true
+		switch *b.ExtractionClass {
+		case ExtractionUnder:
+			s.underVotes += 2
+			s.under = append(s.under, fmt.Sprintf("extraction yield %.1f%% is below %g%%", *b.ExtractionYield, b.targets.EYMin))
+		case ExtractionOver:
+			s.overVotes += 2
+			s.over = append(s.over, fmt.Sprintf("extraction yield %.1f%% is above %g%%", *b.ExtractionYield, b.targets.EYMax))
+		}
+	}
+
+	if b.BrightnessIntensity != nil && b.SweetnessIntensity != nil {
+		s.measured = true
+		if *b.BrightnessIntensity >= highBrightness && *b.SweetnessIntensity <= lowSweetness {
+			s.underVotes++
+			s.under = append(s.under, "high brightness with little sweetness")
+		}
+	}
+
+	if b.ImprovementNotes != nil {
+		words := noteWords(*b.ImprovementNotes)
+		if found := matchKeywords(words, underKeywords); len(found) > 0 {
+			s.underVotes++
+			s.under = append(s.under, "notes mention "+quoteAll(found))
+		}
+		if found := matchKeywords(words, overKeywords); len(found) > 0 {
+			s.overVotes++
+			s.over = append(s.over, "notes mention "+quoteAll(found))
+		}
+		if found := matchKeywords(words, weakKeywords); len(found) > 0 {
+			s.weak = append(s.weak, "notes mention "+quoteAll(found))
+		}
+		if found := matchKeywords(words, strongKeywords); len(found) > 0 {
+			s.strong = append(s.strong, "notes mention "+quoteAll(found))
+		}
+	}
+
+	if b.StrengthClass != nil {
+		s.measured = true
+		switch *b.StrengthClass {
+		case StrengthWeak:
+			s.weak = append(s.weak, fmt.Sprintf("TDS %.2f%% is below %g%%", *b.TDS, b.targets.TDSMin))
+		case StrengthStrong:
+			s.strong = append(s.strong, fmt.Sprintf("TDS %.2f%% is above %g%%", *b.TDS, b.targets.TDSMax))
+		}
+	}
+
+	return s
+}
+
+// adjustExtraction moves the grind one step towards more (or less) extraction.
+// The temperature moves as well when the grind can't, or when the measured
+// extraction is far off target. Cold brew temperature is left alone.
+func adjustExtraction(next *Brew, basis Brew, scale *validate.GrindScale, more bool, why string) []SuggestedChange {
+	changes := []SuggestedChange{}
+
+	grindMoved := false
+	if basis.GrindSize != nil {
+		to := nextGrind(*basis.GrindSize, scale, more)
+		if to != *basis.GrindSize {
+			direction := "coarser"
+			if more {
+				direction = "finer"
+			}
+			next.GrindSize = &to
+			changes = append(changes, SuggestedChange{
+				Field:     "grind_size",
+				From:      basis.GrindSize,
+				To:        &to,
+				Rationale: fmt.Sprintf("Grind %s because %s.", direction, why),
+			})
+			grindMoved = true
+		}
+	}
+
+	farOff := basis.ExtractionClass != nil &&
+		(*basis.ExtractionYield < basis.targets.EYMin-farOffEY || *basis.ExtractionYield > basis.targets.EYMax+farOffEY)
+	if basis.WaterTemperature != nil && basis.Method != MethodColdBrew && (!grindMoved || farOff) {
+		from := *basis.WaterTemperature
+		to := math.Max(from-temperatureStep, minSuggestedTemperature)
+		direction := "Lower"
+		if more {
+			to = math.Min(from+temperatureStep, validate.MaxWaterTemperature)
+			direction = "Raise"
+		}
+		if to != from {
+			next.WaterTemperature = &to
+			reason := "the grind can't move further"
+			if grindMoved {
+				reason = "extraction is far off target"
+			}
+			changes = append(changes, SuggestedChange{
+				Field:     "water_temperature",
+				From:      basis.WaterTemperature,
+				To:        &to,
+				Rationale: fmt.Sprintf("%s the temperature since %s.", direction, reason),
+			})
+		}
+	}
+
+	return changes
+}
+
+// adjustStrength moves the ratio one step when a filter brew tastes or
+// measures too weak or too strong, scaling the pours to the new water weight.
+func adjustStrength(next *Brew, basis Brew, s signals) *SuggestedChange {
+	if !judgesStrength(basis.Method) || basis.Ratio == nil {
+		return nil
+	}
+
+	from := *basis.Ratio
+	var to float64
+	var rationale string
+	switch {
+	case len(s.weak) > 0 && len(s.strong) == 0:
+		to = math.Max(from-ratioStep, validate.MinRatio)
+		rationale = fmt.Sprintf("Use less water per gram for a stronger cup, since %s.", strings.Join(s.weak, ", "))
+	case len(s.strong) > 0 && len(s.weak) == 0:
+		to = math.Min(from+ratioStep, validate.MaxRatio)
+		rationale = fmt.Sprintf("Use more water per gram for a lighter cup, since %s.", strings.Join(s.strong, ", "))
+	default:
+		return nil
+	}
+	if to == from {
+		return nil
+	}
+
+	next.Ratio = &to
+	next.WaterWeight = ComputeWaterWeight(next)
+	for i := range next.Pours {
+		if amount := next.Pours[i].WaterAmount; amount != nil {
+			scaled := math.Round(*amount*to/from*10) / 10
+			next.Pours[i].WaterAmount = &scaled
+		}
+	}
+	if len(next.Pours) > 0 {
+		rationale += " Pour amounts are scaled to match."
+	}
+
+	return &SuggestedChange{Field: "ratio", From: basis.Ratio, To: &to, Rationale: rationale}
+}
+
+// nextGrind returns the setting one step finer (more) or coarser than from.
+// Lower settings are finer unless the grinder's micron mapping says
+// otherwise. Without a known scale the setting moves by one. On a stepped
+// scale the result is snapped to a step, so it passes grind size validation
+// even when from was off the scale's steps.
+func nextGrind(from float64, scale *validate.GrindScale, more bool) float64 {
+	step, finer := 1.0, -1.0
+	lo, hi := 0.0, math.Inf(1)
+	stepped := false
+	if scale != nil {
+		lo, hi = scale.Min, scale.Max
+		if !scale.Stepless && scale.Step != nil && *scale.Step > 0 {
+			step = *scale.Step
+			stepped = true
+		} else {
+			step = (scale.Max - scale.Min) / steplessGrindFraction
+		}
+		if scale.MicronMin != nil && scale.MicronMax != nil && *scale.MicronMin > *scale.MicronMax {
+			finer = 1
+		}
+	}
+
+	dir := finer
+	if !more {
+		dir = -finer
+	}
+	to := math.Min(math.Max(from+dir*step, lo), hi)
+	if !stepped {
+		return math.Round(to*100) / 100
+	}
+	to = lo + math.Round((to-lo)/step)*step
+	if to > hi {
+		to -= step
+	}
+	return math.Round(to*1000) / 1000
+}
+
+// suggestionTemplate copies the setup of b for the next brew, leaving out
+// its identity, date and outcome.
+func suggestionTemplate(b Brew) *Brew {
+	next := Brew{
+		CoffeeID:              b.CoffeeID,
+		CoffeeName:            b.CoffeeName,
+		CoffeeRoaster:         b.CoffeeRoaster,
+		CoffeeTastingNotes:    b.CoffeeTastingNotes,
+		CoffeeReferenceBrewID: b.CoffeeReferenceBrewID,
+		Method:                b.Method,
+		CoffeeWeight:          b.CoffeeWeight,
+		Ratio:                 b.Ratio,
+		WaterWeight:           b.WaterWeight,
+		GrindSize:             b.GrindSize,
+		WaterTemperature:      b.WaterTemperature,
+		FilterPaper:           b.FilterPaper,
+		Dripper:               b.Dripper,
+		Grinder:               b.Grinder,
+		WaterProfile:          b.WaterProfile,
+		RecipeID:              b.RecipeID,
+		TotalBrewTime:         b.TotalBrewTime,
+		MethodParams:          b.MethodParams,
+		TechniqueNotes:        b.TechniqueNotes,
+		Pours:                 make([]Pour, len(b.Pours)),
+	}
+	copy(next.Pours, b.Pours)
+	return &next
+}
+
+func judgesStrength(method string) bool {
+	return method == MethodPourOver || method == MethodImmersion
+}
+
+func noteWords(notes string) map[string]bool {
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(notes), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+func matchKeywords(words map[string]bool, keywords []string) []string {
+	var found []string
+	for _, k := range keywords {
+		if words[k] {
+			found = append(found, k)
+		}
+	}
+	return found
+}
+
+func quoteAll(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = fmt.Sprintf("%q", w)
+	}
+	return strings.Join(quoted, " and ")
+}
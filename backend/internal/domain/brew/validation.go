@@ -0,0 +1,114 @@
+package brew
+
+import (
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// Bounds for brew outcome fields. Setup field bounds are shared with recipes
+// and defaults in the validate package.
+const (
+	maxCoffeeMl  = 10000
+	minTDS       = 0
+	maxTDS       = 25
+	minIntensity = 1
+	maxIntensity = 10
+)
+
+// Bounds for method-specific parameters.
+const (
+	maxYieldWeight = 1000
+	maxPressure    = 20
+	maxSteepHours  = 72
+	maxDilution    = 10
+)
+
+var validAgitations = map[string]bool{"none": true, "stir": true, "swirl": true}
+
+// validateRequest checks a brew create or update request and returns one
+// FieldError per invalid field.
+func validateRequest(req CreateRequest) []api.FieldError {
+	var errs validate.Errors
+
+	if req.CoffeeID == "" {
+		errs.Add("coffee_id", "Coffee is required")
+	}
+	errs.Date("brew_date", req.BrewDate)
+
+	if !validMethods[req.Method] {
+		errs.Add("method", "must be one of pour_over, immersion, espresso, cold_brew")
+	}
+	errs.Setup(req.CoffeeWeight, req.Ratio, req.WaterTemperature)
+	errs.NonNegative("total_brew_time", req.TotalBrewTime)
+	errs.Positive("coffee_ml", req.CoffeeMl, maxCoffeeMl)
+	errs.FloatRange("tds", req.TDS, minTDS, maxTDS)
+
+	errs.IntRange("aroma_intensity", req.AromaIntensity, minIntensity, maxIntensity)
+	errs.IntRange("body_intensity", req.BodyIntensity, minIntensity, maxIntensity)
+	errs.IntRange("sweetness_intensity", req.SweetnessIntensity, minIntensity, maxIntensity)
+	errs.IntRange("brightness_intensity", req.BrightnessIntensity, minIntensity, maxIntensity)
+	errs.IntRange("complexity_intensity", req.ComplexityIntensity, minIntensity, maxIntensity)
+	errs.IntRange("aftertaste_intensity", req.AftertasteIntensity, minIntensity, maxIntensity)
+	errs.IntRange("overall_score", req.OverallScore, minIntensity, maxIntensity)
+
+	validateMethodParams(&errs, req.Method, req.MethodParams)
+	if req.Method == MethodEspresso && len(req.Pours) > 0 {
+		errs.Add("pours", "Pours don't apply to espresso brews")
+	}
+
+	pours := make([]validate.Pour, len(req.Pours))
+	for i, p := range req.Pours {
+		pours[i] = validate.Pour(p)
+	}
+	errs.Pours("pours", pours, req.CoffeeWeight, req.Ratio)
+	tags.Validate(&errs, "tags", req.Tags)
+
+	return errs
+}
+
+// validateMerged re-checks a create request after a recipe has filled it in.
+// The handler only saw what the client sent, so recipe pours could still
+// exceed the water weight or land on an espresso brew.
+func validateMerged(req CreateRequest) error {
+	if errs := validateRequest(req); len(errs) > 0 {
+		return domainerr.Invalid(errs[0].Field, errs[0].Message)
+	}
+	return nil
+}
+
+// validateMethodParams range-checks the method-specific parameters and rejects
+// any that belong to a different method than the brew's.
+func validateMethodParams(errs *validate.Errors, method string, p MethodParams) {
+	errs.Positive("yield_weight", p.YieldWeight, maxYieldWeight)
+	errs.FloatRange("pressure", p.Pressure, 0, maxPressure)
+	errs.NonNegative("preinfusion_time", p.PreinfusionTime)
+	errs.NonNegative("steep_time", p.SteepTime)
+	if p.Agitation != nil && !validAgitations[*p.Agitation] {
+		errs.Add("agitation", "must be one of none, stir, swirl")
+	}
+	errs.Positive("steep_hours", p.SteepHours, maxSteepHours)
+	errs.FloatRange("dilution", p.Dilution, 0, maxDilution)
+
+	if method != MethodEspresso {
+		onlyFor(errs, "yield_weight", p.YieldWeight != nil, "espresso")
+		onlyFor(errs, "pressure", p.Pressure != nil, "espresso")
+		onlyFor(errs, "preinfusion_time", p.PreinfusionTime != nil, "espresso")
+	}
+	if method != MethodImmersion {
+		onlyFor(errs, "steep_time", p.SteepTime != nil, "immersion")
+		onlyFor(errs, "agitation", p.Agitation != nil, "immersion")
+		onlyFor(errs, "plunge", p.Plunge != nil, "immersion")
+	}
+	if method != MethodColdBrew {
+		onlyFor(errs, "steep_hours", p.SteepHours != nil, "cold brew")
+		onlyFor(errs, "dilution", p.Dilution != nil, "cold brew")
+	}
+}
+
+func onlyFor(errs *validate.Errors, field string, set bool, method string) {
+	if set {
+		errs.Add(field, "Only applies to "+method+" brews")
+	}
+}
@@ -0,0 +1,48 @@
+package coffee
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// AutoArchiver periodically applies users' auto-archive rules.
+type AutoArchiver struct {
+	repo     Repository
+	interval time.Duration
+}
+
+func NewAutoArchiver(repo Repository, interval time.Duration) *AutoArchiver {
+	return &AutoArchiver{repo: repo, interval: interval}
+}
+
+// Run applies the rules immediately and then once per interval until ctx is
+// cancelled.
+func (a *AutoArchiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("error auto-archiving coffees: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce applies the rules a single time and returns the coffees archived.
+func (a *AutoArchiver) RunOnce(ctx context.Context) ([]ArchiveEvent, error) {
+	events, err := a.repo.AutoArchive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		log.Printf("auto-archived %d coffees", len(events))
+	}
+	return events, nil
+}
@@ -1,60 +1,158 @@
 package coffee
 
 import (
+	"math"
 	"time"
 )
 
 type Coffee struct {
-	ID              string     `json:"id"`
-	UserID          string     `json:"-"`
-	Roaster         string     `json:"roaster"`
-	Name            string     `json:"name"`
-	Country         *string    `json:"country"`
-	Region          *string    `json:"region"`
-	Farm            *string    `json:"farm"`
-	Varietal        *string    `json:"varietal"`
-	Elevation       *string    `json:"elevation"`
-	Process         *string    `json:"process"`
-	RoastLevel      *string    `json:"roast_level"`
-	TastingNotes    *string    `json:"tasting_notes"`
-	RoastDate       *string    `json:"roast_date"`
-	Notes           *string    `json:"notes"`
-	ReferenceBrewID *string    `json:"reference_brew_id"`
-	ArchivedAt      *time.Time `json:"archived_at"`
-	BrewCount       int        `json:"brew_count"`
-	LastBrewed      *time.Time `json:"last_brewed"`
-	CreatedAt       time.Time  `json:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at"`
+	ID                      string     `json:"id"`
+	UserID                  string     `json:"-"`
+	RoasterID               string     `json:"roaster_id"`
+	Roaster                 string     `json:"roaster"`
+	Name                    string     `json:"name"`
+	Country                 *string    `json:"country"`
+	Region                  *string    `json:"region"`
+	Farm                    *string    `json:"farm"`
+	Varietal                *string    `json:"varietal"`
+	Elevation               *string    `json:"elevation"`
+	Process                 *string    `json:"process"`
+	RoastLevel              *string    `json:"roast_level"`
+	TastingNotes            *string    `json:"tasting_notes"`
+	RoastDate               *string    `json:"roast_date"`
+	Notes                   *string    `json:"notes"`
+	ReferenceBrewID         *string    `json:"reference_brew_id"`
+	BagWeight               *float64   `json:"bag_weight"`
+	PurchaseDate            *string    `json:"purchase_date"`
+	Price                   *float64   `json:"price"`
+	Currency                *string    `json:"currency"`
+	RemainingGrams          *float64   `json:"remaining_grams"`
+	EstimatedBrewsRemaining *int       `json:"estimated_brews_remaining"`
+	ArchivedAt              *time.Time `json:"archived_at"`
+	BrewCount               int        `json:"brew_count"`
+	LastBrewed              *time.Time `json:"last_brewed"`
+	RestStatus              *string    `json:"rest_status"`
+	Tags                    []string   `json:"tags"`
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
 }
 
+// CreateRequest names the roaster either by RoasterID or by Roaster. A name
+// resolves to the user's roaster of that name, ignoring case, and creates one
+// if none exists. RoasterID wins when both are given. Tags resolve the same
+// way.
 type CreateRequest struct {
-	Roaster      string  `json:"roaster"`
-	Name         string  `json:"name"`
-	Country      *string `json:"country"`
-	Region       *string `json:"region"`
-	Farm         *string `json:"farm"`
-	Varietal     *string `json:"varietal"`
-	Elevation    *string `json:"elevation"`
-	Process      *string `json:"process"`
-	RoastLevel   *string `json:"roast_level"`
-	TastingNotes *string `json:"tasting_notes"`
-	RoastDate    *string `json:"roast_date"`
-	Notes        *string `json:"notes"`
+	RoasterID    *string  `json:"roaster_id"`
+	Roaster      string   `json:"roaster"`
+	Name         string   `json:"name"`
+	Country      *string  `json:"country"`
+	Region       *string  `json:"region"`
+	Farm         *string  `json:"farm"`
+	Varietal     *string  `json:"varietal"`
+	Elevation    *string  `json:"elevation"`
+	Process      *string  `json:"process"`
+	RoastLevel   *string  `json:"roast_level"`
+	TastingNotes *string  `json:"tasting_notes"`
+	RoastDate    *string  `json:"roast_date"`
+	Notes        *string  `json:"notes"`
+	BagWeight    *float64 `json:"bag_weight"`
+	PurchaseDate *string  `json:"purchase_date"`
+	Price        *float64 `json:"price"`
+	Currency     *string  `json:"currency"`
+	Tags         []string `json:"tags"`
 }
 
+// UpdateRequest replaces every field of a coffee, except that omitting Tags
+// leaves the tags unchanged; an empty list removes them.
 type UpdateRequest struct {
-	Roaster      string  `json:"roaster"`
-	Name         string  `json:"name"`
-	Country      *string `json:"country"`
-	Region       *string `json:"region"`
-	Farm         *string `json:"farm"`
-	Varietal     *string `json:"varietal"`
-	Elevation    *string `json:"elevation"`
-	Process      *string `json:"process"`
-	RoastLevel   *string `json:"roast_level"`
-	TastingNotes *string `json:"tasting_notes"`
-	RoastDate    *string `json:"roast_date"`
-	Notes        *string `json:"notes"`
+	RoasterID    *string  `json:"roaster_id"`
+	Roaster      string   `json:"roaster"`
+	Name         string   `json:"name"`
+	Country      *string  `json:"country"`
+	Region       *string  `json:"region"`
+	Farm         *string  `json:"farm"`
+	Varietal     *string  `json:"varietal"`
+	Elevation    *string  `json:"elevation"`
+	Process      *string  `json:"process"`
+	RoastLevel   *string  `json:"roast_level"`
+	TastingNotes *string  `json:"tasting_notes"`
+	RoastDate    *string  `json:"roast_date"`
+	Notes        *string  `json:"notes"`
+	BagWeight    *float64 `json:"bag_weight"`
+	PurchaseDate *string  `json:"purchase_date"`
+	Price        *float64 `json:"price"`
+	Currency     *string  `json:"currency"`
+	Tags         []string `json:"tags"`
+}
+
+// Inventory estimates. A coffee is running low when fewer than RunningLowBrews
+// brews are left in the bag.
+const (
+	DefaultDose     = 15
+	RunningLowBrews = 3
+)
+
+// estimateBrews sets EstimatedBrewsRemaining by dividing the remaining grams by
+// the average dose of the coffee's brews, or by DefaultDose before the first
+// brew. avgDose is nil when no brew records a dose.
+func (c *Coffee) estimateBrews(avgDose *float64) {
+	if c.RemainingGrams == nil {
+		c.EstimatedBrewsRemaining = nil
+		return
+	}
+	dose := float64(DefaultDose)
+	if avgDose != nil && *avgDose > 0 {
+		dose = *avgDose
+	}
+	n := 0
+	if *c.RemainingGrams > 0 {
+		n = int(math.Floor(*c.RemainingGrams / dose))
+	}
+	c.EstimatedBrewsRemaining = &n
+}
+
+// Adjustment reasons for manual inventory changes.
+var validAdjustmentReasons = map[string]bool{
+	"spill":      true,
+	"gift":       true,
+	"correction": true,
+	"other":      true,
+}
+
+// Adjustment is a manual change to a coffee's remaining grams, such as a spill
+// or beans given away. Amount is signed: negative removes coffee.
+type Adjustment struct {
+	ID             string    `json:"id"`
+	CoffeeID       string    `json:"coffee_id"`
+	Amount         float64   `json:"amount"`
+	Reason         string    `json:"reason"`
+	Notes          *string   `json:"notes"`
+	RemainingAfter float64   `json:"remaining_after"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Reasons recorded on an automatic archive.
+const (
+	ArchiveReasonLowRemaining = "low_remaining"
+	ArchiveReasonIdle         = "idle"
+)
+
+// ArchiveEvent records a coffee archived by the user's auto-archive rule.
+// ReversedAt is set once the user unarchives it.
+type ArchiveEvent struct {
+	ID             string     `json:"id"`
+	CoffeeID       string     `json:"coffee_id"`
+	Reason         string     `json:"reason"`
+	RemainingGrams *float64   `json:"remaining_grams"`
+	LastBrewed     *string    `json:"last_brewed"`
+	ArchivedAt     time.Time  `json:"archived_at"`
+	ReversedAt     *time.Time `json:"reversed_at"`
+}
+
+type AdjustmentRequest struct {
+	Amount *float64 `json:"amount"`
+	Reason string   `json:"reason"`
+	Notes  *string  `json:"notes"`
 }
 
 type SetReferenceRequest struct {
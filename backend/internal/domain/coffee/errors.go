@@ -0,0 +1,15 @@
+package coffee
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound        = domainerr.NotFound("Coffee not found")
+	ErrBrewNotOnCoffee = domainerr.Invalid("brew_id", "Brew does not belong to this coffee")
+	ErrNoBagWeight     = domainerr.Invalid("bag_weight", "Set a bag weight before adjusting inventory")
+	ErrRoasterNotFound = domainerr.InvalidReference("roaster_id", "Roaster not found")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"fk_coffees_reference_brew": ErrBrewNotOnCoffee,
+}
@@ -5,12 +5,14 @@ import (
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/peak"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
 )
 
 type Handler struct {
@@ -30,10 +32,14 @@ func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 		Page:         pagination.Page,
 		PerPage:      pagination.PerPage,
 		Search:       q.Get("search"),
+		RoasterID:    q.Get("roaster_id"),
 		Roaster:      q.Get("roaster"),
 		Country:      q.Get("country"),
 		Process:      q.Get("process"),
 		ArchivedOnly: q.Get("archived_only") == "true",
+		RunningLow:   q.Get("running_low") == "true",
+		Tags:         tags.Parse(q.Get("tags")),
+		TagMatch:     q.Get("tag_match"),
 	}
 
 	coffees, total, err := h.repo.List(r.Context(), userID, params)
@@ -83,21 +89,18 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	req.Roaster = strings.TrimSpace(req.Roaster)
 	req.Name = strings.TrimSpace(req.Name)
-
-	var fieldErrors []api.FieldError
-	if req.Roaster == "" {
-		fieldErrors = append(fieldErrors, api.FieldError{Field: "roaster", Message: "Roaster is required"})
-	}
-	if req.Name == "" {
-		fieldErrors = append(fieldErrors, api.FieldError{Field: "name", Message: "Name is required"})
-	}
-	if len(fieldErrors) > 0 {
+	req.Currency = normalizeCurrency(req.Currency)
+	req.Tags = tags.Normalize(req.Tags)
+	if fieldErrors := validateRequest(req); len(fieldErrors) > 0 {
 		api.ValidationError(w, fieldErrors)
 		return
 	}
 
 	coffee, err := h.repo.Create(r.Context(), userID, req)
 	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
 		log.Printf("error creating coffee: %v", err)
 		api.InternalError(w)
 		return
@@ -118,21 +121,18 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	req.Roaster = strings.TrimSpace(req.Roaster)
 	req.Name = strings.TrimSpace(req.Name)
-
-	var fieldErrors []api.FieldError
-	if req.Roaster == "" {
-		fieldErrors = append(fieldErrors, api.FieldError{Field: "roaster", Message: "Roaster is required"})
-	}
-	if req.Name == "" {
-		fieldErrors = append(fieldErrors, api.FieldError{Field: "name", Message: "Name is required"})
-	}
-	if len(fieldErrors) > 0 {
+	req.Currency = normalizeCurrency(req.Currency)
+	req.Tags = tags.Normalize(req.Tags)
+	if fieldErrors := validateRequest(CreateRequest(req)); len(fieldErrors) > 0 {
 		api.ValidationError(w, fieldErrors)
 		return
 	}
 
 	coffee, err := h.repo.Update(r.Context(), userID, id, req)
 	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
 		log.Printf("error updating coffee: %v", err)
 		api.InternalError(w)
 		return
@@ -151,8 +151,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err := h.repo.Delete(r.Context(), userID, id)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			api.NotFoundError(w, "Coffee not found")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error deleting coffee: %v", err)
@@ -211,8 +210,7 @@ func (h *Handler) SetReferenceBrew(w http.ResponseWriter, r *http.Request) {
 
 	coffee, err := h.repo.SetReferenceBrew(r.Context(), userID, id, req.BrewID)
 	if err != nil {
-		if isInvalidBrewError(err) {
-			api.ValidationError(w, []api.FieldError{{Field: "brew_id", Message: "Brew does not belong to this coffee"}})
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error setting reference brew: %v", err)
@@ -253,6 +251,144 @@ func (h *Handler) Suggestions(w http.ResponseWriter, r *http.Request) {
 	api.WriteJSON(w, http.StatusOK, SuggestionsResponse{Items: items})
 }
 
-func isInvalidBrewError(err error) bool {
-	return strings.Contains(err.Error(), "brew does not belong to this coffee")
+func (h *Handler) ListAdjustments(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	adjustments, err := h.repo.ListAdjustments(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error listing inventory adjustments: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]interface{}{"items": adjustments})
+}
+
+func (h *Handler) ListArchiveEvents(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	events, err := h.repo.ListArchiveEvents(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error listing archive events: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]interface{}{"items": events})
+}
+
+func (h *Handler) AdjustInventory(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req AdjustmentRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	if fieldErrors := validateAdjustment(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	adjustment, err := h.repo.AdjustInventory(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error adjusting inventory: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, adjustment)
+}
+
+// normalizeCurrency upper-cases a currency code so "sgd" and "SGD" match.
+func normalizeCurrency(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := strings.ToUpper(strings.TrimSpace(*s))
+	return &c
+}
+
+func (h *Handler) PeakWindow(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	coffee, err := h.repo.GetByID(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error getting coffee: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if coffee == nil {
+		api.NotFoundError(w, "Coffee not found")
+		return
+	}
+
+	samples, err := h.repo.RestSamples(r.Context(), userID)
+	if err != nil {
+		log.Printf("error loading rest samples: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	var points []peak.Point
+	for _, s := range samples {
+		if s.CoffeeID == coffee.ID {
+			points = append(points, peak.Point{DaysOffRoast: float64(s.DaysOffRoast), Score: float64(s.Score)})
+		}
+	}
+
+	resp := PeakWindowResponse{
+		CoffeeID:     coffee.ID,
+		SampleSize:   len(points),
+		Window:       peak.Estimate(points),
+		DaysOffRoast: daysOffRoast(coffee.RoastDate, time.Now().UTC()),
+	}
+	if resp.DaysOffRoast != nil {
+		if window, basis := estimateRest(samples).windowFor(coffee); window != nil {
+			status := window.Status(*resp.DaysOffRoast)
+			resp.RestStatus = &status
+			resp.RestBasis = &basis
+		}
+	}
+
+	api.WriteJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) PeakWindows(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = GroupByProcess
+	}
+	if !validPeakGroups[groupBy] {
+		api.ValidationError(w, []api.FieldError{{Field: "group_by", Message: "must be one of process, roast_level"}})
+		return
+	}
+
+	samples, err := h.repo.RestSamples(r.Context(), userID)
+	if err != nil {
+		log.Printf("error loading rest samples: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, PeakWindowGroupsResponse{
+		GroupBy: groupBy,
+		Items:   peakWindowGroups(samples, groupBy),
+	})
 }
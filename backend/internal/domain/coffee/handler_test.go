@@ -13,23 +13,39 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
 )
 
 const testSecret = "test-jwt-secret-key"
 
 // --- Mock Repository ---
 
+type mockRoaster struct {
+	userID string
+	name   string
+}
+
 type mockRepo struct {
-	coffees map[string]*Coffee
-	nextID  int
+	coffees       map[string]*Coffee
+	roasters      map[string]mockRoaster
+	adjustments   map[string][]Adjustment
+	archiveEvents map[string][]ArchiveEvent
+	archiveBelow  *float64 // auto-archive threshold applied to every coffee
+	restSamples   []RestSample
+	nextID        int
 }
 
 func newMockRepo() *mockRepo {
-	return &mockRepo{coffees: make(map[string]*Coffee), nextID: 1}
+	return &mockRepo{
+		coffees:       make(map[string]*Coffee),
+		roasters:      make(map[string]mockRoaster),
+		adjustments:   make(map[string][]Adjustment),
+		archiveEvents: make(map[string][]ArchiveEvent),
+		nextID:        1,
+	}
 }
 
 func (m *mockRepo) List(_ context.Context, userID string, params ListParams) ([]Coffee, int, error) {
@@ -53,7 +69,10 @@ func (m *mockRepo) List(_ context.Context, userID string, params ListParams) ([]
 				continue
 			}
 		}
-		if params.Roaster != "" && c.Roaster != params.Roaster {
+		if params.RoasterID != "" && c.RoasterID != params.RoasterID {
+			continue
+		}
+		if params.Roaster != "" && !strings.EqualFold(c.Roaster, params.Roaster) {
 			continue
 		}
 		if params.Country != "" && (c.Country == nil || *c.Country != params.Country) {
@@ -62,6 +81,12 @@ func (m *mockRepo) List(_ context.Context, userID string, params ListParams) ([]
 		if params.Process != "" && (c.Process == nil || *c.Process != params.Process) {
 			continue
 		}
+		if len(params.Tags) > 0 && !hasTags(c.Tags, params.Tags, params.TagMatch) {
+			continue
+		}
+		if params.RunningLow && (c.EstimatedBrewsRemaining == nil || *c.EstimatedBrewsRemaining >= RunningLowBrews) {
+			continue
+		}
 		result = append(result, *c)
 	}
 	total := len(result)
@@ -77,22 +102,67 @@ func (m *mockRepo) List(_ context.Context, userID string, params ListParams) ([]
 	return result[offset:end], total, nil
 }
 
+// hasTags mirrors tags.FilterSQL: any or all of want, ignoring case.
+func hasTags(have, want []string, match string) bool {
+	found := 0
+	for _, w := range want {
+		for _, h := range have {
+			if strings.EqualFold(h, w) {
+				found++
+				break
+			}
+		}
+	}
+	if match == tags.MatchAll {
+		return found == len(want)
+	}
+	return found > 0
+}
+
 func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*Coffee, error) {
 	c := m.coffees[id]
 	if c == nil || c.UserID != userID {
 		return nil, nil
 	}
+	c.setRestStatus(estimateRest(m.restSamples), time.Now().UTC())
 	return c, nil
 }
 
+// resolveRoaster mirrors the Postgres lookup: by ID, else by name ignoring
+// case, creating the roaster when it doesn't exist.
+func (m *mockRepo) resolveRoaster(userID string, roasterID *string, name string) (string, string, error) {
+	if roasterID != nil {
+		ro, ok := m.roasters[*roasterID]
+		if !ok || ro.userID != userID {
+			return "", "", ErrRoasterNotFound
+		}
+		return *roasterID, ro.name, nil
+	}
+	for id, ro := range m.roasters {
+		if ro.userID == userID && strings.EqualFold(ro.name, name) {
+			return id, ro.name, nil
+		}
+	}
+	m.nextID++
+	id := fmt.Sprintf("roaster-%d", m.nextID)
+	m.roasters[id] = mockRoaster{userID: userID, name: name}
+	return id, name, nil
+}
+
 func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (*Coffee, error) {
+	roasterID, roasterName, err := m.resolveRoaster(userID, req.RoasterID, req.Roaster)
+	if err != nil {
+		return nil, err
+	}
+
 	m.nextID++
 	id := fmt.Sprintf("coffee-%d", m.nextID)
 	now := time.Now()
 	c := &Coffee{
 		ID:           id,
 		UserID:       userID,
-		Roaster:      req.Roaster,
+		RoasterID:    roasterID,
+		Roaster:      roasterName,
 		Name:         req.Name,
 		Country:      req.Country,
 		Region:       req.Region,
@@ -104,10 +174,23 @@ func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (
 		TastingNotes: req.TastingNotes,
 		RoastDate:    req.RoastDate,
 		Notes:        req.Notes,
+		BagWeight:    req.BagWeight,
+		PurchaseDate: req.PurchaseDate,
+		Price:        req.Price,
+		Currency:     req.Currency,
+		Tags:         []string{},
 		BrewCount:    0,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
+	if req.Tags != nil {
+		c.Tags = req.Tags
+	}
+	if req.BagWeight != nil {
+		remaining := *req.BagWeight
+		c.RemainingGrams = &remaining
+	}
+	c.estimateBrews(nil)
 	m.coffees[id] = c
 	return c, nil
 }
@@ -117,7 +200,12 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 	if c == nil || c.UserID != userID {
 		return nil, nil
 	}
-	c.Roaster = req.Roaster
+	roasterID, roasterName, err := m.resolveRoaster(userID, req.RoasterID, req.Roaster)
+	if err != nil {
+		return nil, err
+	}
+	c.RoasterID = roasterID
+	c.Roaster = roasterName
 	c.Name = req.Name
 	c.Country = req.Country
 	c.Region = req.Region
@@ -129,6 +217,24 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 	c.TastingNotes = req.TastingNotes
 	c.RoastDate = req.RoastDate
 	c.Notes = req.Notes
+	switch {
+	case req.BagWeight == nil:
+		c.RemainingGrams = nil
+	case c.BagWeight == nil:
+		remaining := *req.BagWeight
+		c.RemainingGrams = &remaining
+	default:
+		remaining := *c.RemainingGrams + *req.BagWeight - *c.BagWeight
+		c.RemainingGrams = &remaining
+	}
+	c.BagWeight = req.BagWeight
+	c.PurchaseDate = req.PurchaseDate
+	c.Price = req.Price
+	c.Currency = req.Currency
+	if req.Tags != nil {
+		c.Tags = req.Tags
+	}
+	c.estimateBrews(nil)
 	c.UpdatedAt = time.Now()
 	return c, nil
 }
@@ -136,7 +242,7 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 func (m *mockRepo) Delete(_ context.Context, userID, id string) error {
 	c := m.coffees[id]
 	if c == nil || c.UserID != userID {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	delete(m.coffees, id)
 	return nil
@@ -160,6 +266,11 @@ func (m *mockRepo) Unarchive(_ context.Context, userID, id string) (*Coffee, err
 	}
 	c.ArchivedAt = nil
 	c.UpdatedAt = time.Now()
+	for i := range m.archiveEvents[id] {
+		if m.archiveEvents[id][i].ReversedAt == nil {
+			m.archiveEvents[id][i].ReversedAt = &c.UpdatedAt
+		}
+	}
 	return c, nil
 }
 
@@ -169,7 +280,7 @@ func (m *mockRepo) SetReferenceBrew(_ context.Context, userID, id string, brewID
 		return nil, nil
 	}
 	if brewID != nil && *brewID == "invalid-brew" {
-		return nil, errors.New("brew does not belong to this coffee")
+		return nil, ErrBrewNotOnCoffee
 	}
 	c.ReferenceBrewID = brewID
 	c.UpdatedAt = time.Now()
@@ -209,6 +320,79 @@ func (m *mockRepo) Suggestions(_ context.Context, userID, field, query string) (
 	return items, nil
 }
 
+func (m *mockRepo) AdjustInventory(_ context.Context, userID, id string, req AdjustmentRequest) (*Adjustment, error) {
+	c := m.coffees[id]
+	if c == nil || c.UserID != userID {
+		return nil, ErrNotFound
+	}
+	if c.RemainingGrams == nil {
+		return nil, ErrNoBagWeight
+	}
+	remaining := *c.RemainingGrams + *req.Amount
+	c.RemainingGrams = &remaining
+	c.estimateBrews(nil)
+
+	m.nextID++
+	a := Adjustment{
+		ID:             fmt.Sprintf("adj-%d", m.nextID),
+		CoffeeID:       id,
+		Amount:         *req.Amount,
+		Reason:         req.Reason,
+		Notes:          req.Notes,
+		RemainingAfter: remaining,
+		CreatedAt:      time.Now(),
+	}
+	m.adjustments[id] = append(m.adjustments[id], a)
+	return &a, nil
+}
+
+func (m *mockRepo) ListAdjustments(_ context.Context, userID, id string) ([]Adjustment, error) {
+	c := m.coffees[id]
+	if c == nil || c.UserID != userID {
+		return nil, ErrNotFound
+	}
+	adjustments := []Adjustment{}
+	return append(adjustments, m.adjustments[id]...), nil
+}
+
+func (m *mockRepo) ListArchiveEvents(_ context.Context, userID, id string) ([]ArchiveEvent, error) {
+	c := m.coffees[id]
+	if c == nil || c.UserID != userID {
+		return nil, ErrNotFound
+	}
+	events := []ArchiveEvent{}
+	return append(events, m.archiveEvents[id]...), nil
+}
+
+func (m *mockRepo) RestSamples(_ context.Context, _ string) ([]RestSample, error) {
+	return m.restSamples, nil
+}
+
+func (m *mockRepo) AutoArchive(_ context.Context) ([]ArchiveEvent, error) {
+	events := []ArchiveEvent{}
+	if m.archiveBelow == nil {
+		return events, nil
+	}
+	for id, c := range m.coffees {
+		if c.ArchivedAt != nil || c.RemainingGrams == nil || *c.RemainingGrams >= *m.archiveBelow {
+			continue
+		}
+		now := time.Now()
+		c.ArchivedAt = &now
+		m.nextID++
+		e := ArchiveEvent{
+			ID:             fmt.Sprintf("event-%d", m.nextID),
+			CoffeeID:       id,
+			Reason:         ArchiveReasonLowRemaining,
+			RemainingGrams: c.RemainingGrams,
+			ArchivedAt:     now,
+		}
+		m.archiveEvents[id] = append(m.archiveEvents[id], e)
+		events = append(events, e)
+	}
+	return events, nil
+}
+
 // Error-returning mock
 
 type errorRepo struct{}
@@ -240,6 +424,21 @@ func (e *errorRepo) SetReferenceBrew(_ context.Context, _, _ string, _ *string)
 func (e *errorRepo) Suggestions(_ context.Context, _, _, _ string) ([]string, error) {
 	return nil, errors.New("database error")
 }
+func (e *errorRepo) AdjustInventory(_ context.Context, _, _ string, _ AdjustmentRequest) (*Adjustment, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) ListAdjustments(_ context.Context, _, _ string) ([]Adjustment, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) ListArchiveEvents(_ context.Context, _, _ string) ([]ArchiveEvent, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) RestSamples(_ context.Context, _ string) ([]RestSample, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) AutoArchive(_ context.Context) ([]ArchiveEvent, error) {
+	return nil, errors.New("database error")
+}
 
 // --- Helpers ---
 
@@ -262,12 +461,17 @@ func setupRouter(h *Handler) *chi.Mux {
 		r.Get("/", h.List)
 		r.Post("/", h.Create)
 		r.Get("/suggestions", h.Suggestions)
+		r.Get("/peak-windows", h.PeakWindows)
 		r.Get("/{id}", h.GetByID)
 		r.Put("/{id}", h.Update)
 		r.Delete("/{id}", h.Delete)
 		r.Post("/{id}/archive", h.Archive)
 		r.Post("/{id}/unarchive", h.Unarchive)
 		r.Post("/{id}/reference-brew", h.SetReferenceBrew)
+		r.Get("/{id}/adjustments", h.ListAdjustments)
+		r.Post("/{id}/adjustments", h.AdjustInventory)
+		r.Get("/{id}/archive-events", h.ListArchiveEvents)
+		r.Get("/{id}/peak-window", h.PeakWindow)
 	})
 	return r
 }
@@ -299,7 +503,8 @@ func seedCoffee(repo *mockRepo, id, userID, roaster, name string) *Coffee {
 	return c
 }
 
-func strPtr(s string) *string { return &s }
+func strPtr(s string) *string     { return &s }
+func floatPtr(f float64) *float64 { return &f }
 
 // --- List Tests ---
 
@@ -1313,3 +1518,784 @@ func TestGetByID_WithoutRoastDate(t *testing.T) {
 		t.Errorf("expected nil roast_date, got %s", *resp.RoastDate)
 	}
 }
+
+// --- Inventory Tests ---
+
+func TestCreate_WithBag(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"roaster":"Cata Coffee","name":"Kiamaina","bag_weight":250,"purchase_date":"2026-01-02","price":24.5,"currency":"sgd"}`
+	req := authRequest(http.MethodPost, "/api/v1/coffees", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Coffee
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RemainingGrams == nil || *resp.RemainingGrams != 250 {
+		t.Errorf("expected remaining_grams 250, got %v", resp.RemainingGrams)
+	}
+	if resp.EstimatedBrewsRemaining == nil || *resp.EstimatedBrewsRemaining != 16 {
+		t.Errorf("expected 16 brews remaining at the default dose, got %v", resp.EstimatedBrewsRemaining)
+	}
+	if resp.Currency == nil || *resp.Currency != "SGD" {
+		t.Errorf("expected currency upper-cased to SGD, got %v", resp.Currency)
+	}
+	if resp.PurchaseDate == nil || *resp.PurchaseDate != "2026-01-02" {
+		t.Errorf("expected purchase_date 2026-01-02, got %v", resp.PurchaseDate)
+	}
+}
+
+func TestCreate_InventoryValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"zero bag weight", `{"roaster":"Cata","name":"Kiamaina","bag_weight":0}`, "bag_weight"},
+		{"negative price", `{"roaster":"Cata","name":"Kiamaina","price":-1,"currency":"SGD"}`, "price"},
+		{"price without currency", `{"roaster":"Cata","name":"Kiamaina","price":24}`, "currency"},
+		{"bad currency", `{"roaster":"Cata","name":"Kiamaina","currency":"dollars"}`, "currency"},
+		{"future purchase date", `{"roaster":"Cata","name":"Kiamaina","purchase_date":"2999-01-01"}`, "purchase_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(newMockRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/coffees", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp api.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.field {
+				t.Errorf("expected a single %s error, got %v", tt.field, resp.Error.Details)
+			}
+		})
+	}
+}
+
+func TestUpdate_BagWeightShiftsRemaining(t *testing.T) {
+	repo := newMockRepo()
+	c := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	c.BagWeight = floatPtr(250)
+	c.RemainingGrams = floatPtr(100)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"roaster":"Cata Coffee","name":"Kiamaina","bag_weight":200}`
+	req := authRequest(http.MethodPut, "/api/v1/coffees/c-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Coffee
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RemainingGrams == nil || *resp.RemainingGrams != 50 {
+		t.Errorf("expected remaining_grams 50 after correcting the bag weight, got %v", resp.RemainingGrams)
+	}
+}
+
+func TestList_RunningLow(t *testing.T) {
+	repo := newMockRepo()
+	low := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	low.RemainingGrams = floatPtr(30)
+	low.estimateBrews(nil)
+	full := seedCoffee(repo, "c-2", "user-123", "SEY", "Worka Sakaro")
+	full.RemainingGrams = floatPtr(250)
+	full.estimateBrews(nil)
+	seedCoffee(repo, "c-3", "user-123", "Onyx", "Untracked")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees?running_low=true", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Items []Coffee `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].ID != "c-1" {
+		t.Errorf("expected only c-1 to be running low, got %d coffees", len(resp.Items))
+	}
+}
+
+func TestEstimateBrews(t *testing.T) {
+	c := &Coffee{RemainingGrams: floatPtr(100)}
+	c.estimateBrews(floatPtr(18))
+	if c.EstimatedBrewsRemaining == nil || *c.EstimatedBrewsRemaining != 5 {
+		t.Errorf("expected 5 brews at 18g, got %v", c.EstimatedBrewsRemaining)
+	}
+
+	c.RemainingGrams = floatPtr(-4)
+	c.estimateBrews(floatPtr(18))
+	if c.EstimatedBrewsRemaining == nil || *c.EstimatedBrewsRemaining != 0 {
+		t.Errorf("expected 0 brews for an overdrawn bag, got %v", c.EstimatedBrewsRemaining)
+	}
+
+	c.RemainingGrams = nil
+	c.estimateBrews(floatPtr(18))
+	if c.EstimatedBrewsRemaining != nil {
+		t.Errorf("expected nil without a bag weight, got %v", *c.EstimatedBrewsRemaining)
+	}
+}
+
+func TestAdjustInventory_Success(t *testing.T) {
+	repo := newMockRepo()
+	c := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	c.BagWeight = floatPtr(250)
+	c.RemainingGrams = floatPtr(100)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"amount":-12.5,"reason":"spill","notes":"Knocked the hopper"}`
+	req := authRequest(http.MethodPost, "/api/v1/coffees/c-1/adjustments", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Adjustment
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Amount != -12.5 || resp.Reason != "spill" || resp.RemainingAfter != 87.5 {
+		t.Errorf("unexpected adjustment: %+v", resp)
+	}
+	if *c.RemainingGrams != 87.5 {
+		t.Errorf("expected coffee remaining_grams 87.5, got %v", *c.RemainingGrams)
+	}
+}
+
+func TestAdjustInventory_NoBagWeight(t *testing.T) {
+	repo := newMockRepo()
+	seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/coffees/c-1/adjustments", `{"amount":-10,"reason":"gift"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "bag_weight" {
+		t.Errorf("expected bag_weight error, got %v", resp.Error.Details)
+	}
+}
+
+func TestAdjustInventory_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"missing amount", `{"reason":"spill"}`, "amount"},
+		{"zero amount", `{"amount":0,"reason":"spill"}`, "amount"},
+		{"unknown reason", `{"amount":-5,"reason":"ate it"}`, "reason"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+			h := NewHandler(repo)
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/coffees/c-1/adjustments", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp api.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.field {
+				t.Errorf("expected a single %s error, got %v", tt.field, resp.Error.Details)
+			}
+		})
+	}
+}
+
+func TestAdjustInventory_NotFound(t *testing.T) {
+	repo := newMockRepo()
+	seedCoffee(repo, "c-1", "user-456", "Theirs", "Their Coffee")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/coffees/c-1/adjustments", `{"amount":-10,"reason":"gift"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListAdjustments_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	repo.adjustments["c-1"] = []Adjustment{{ID: "adj-1", CoffeeID: "c-1", Amount: 20, Reason: "correction", RemainingAfter: 120}}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/adjustments", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []Adjustment `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].Reason != "correction" {
+		t.Errorf("expected 1 correction, got %+v", resp.Items)
+	}
+}
+
+func TestListAdjustments_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/adjustments", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+}
+
+// --- Auto-archive Tests ---
+
+func TestAutoArchiver_RunOnce(t *testing.T) {
+	repo := newMockRepo()
+	repo.archiveBelow = floatPtr(10)
+	low := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	low.RemainingGrams = floatPtr(6)
+	full := seedCoffee(repo, "c-2", "user-123", "Cata Coffee", "El Calagual")
+	full.RemainingGrams = floatPtr(200)
+	seedCoffee(repo, "c-3", "user-123", "Cata Coffee", "No Bag")
+
+	events, err := NewAutoArchiver(repo, time.Hour).RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 1 || events[0].CoffeeID != "c-1" || events[0].Reason != ArchiveReasonLowRemaining {
+		t.Fatalf("expected c-1 archived for low_remaining, got %+v", events)
+	}
+	if low.ArchivedAt == nil {
+		t.Error("expected c-1 to be archived")
+	}
+	if full.ArchivedAt != nil {
+		t.Error("expected c-2 to stay active")
+	}
+}
+
+func TestAutoArchiver_RunOnceError(t *testing.T) {
+	if _, err := NewAutoArchiver(&errorRepo{}, time.Hour).RunOnce(context.Background()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestUnarchive_ReversesArchiveEvent(t *testing.T) {
+	repo := newMockRepo()
+	repo.archiveBelow = floatPtr(10)
+	c := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	c.RemainingGrams = floatPtr(6)
+	NewAutoArchiver(repo, time.Hour).RunOnce(context.Background())
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/coffees/c-1/unarchive", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = authRequest(http.MethodGet, "/api/v1/coffees/c-1/archive-events", "")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []ArchiveEvent `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 {
+		t.Fatalf("expected 1 archive event, got %d", len(resp.Items))
+	}
+	if resp.Items[0].ReversedAt == nil {
+		t.Error("expected reversed_at to be set after unarchive")
+	}
+}
+
+func TestListArchiveEvents_NotFound(t *testing.T) {
+	repo := newMockRepo()
+	seedCoffee(repo, "c-1", "user-456", "Theirs", "Their Coffee")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/archive-events", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListArchiveEvents_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/archive-events", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+}
+
+// --- Roaster Resolution Tests ---
+
+func TestCreate_ResolvesRoasterByName(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	var ids []string
+	for _, body := range []string{
+		`{"roaster":"Onyx Coffee Lab","name":"Southern Weather"}`,
+		`{"roaster":"onyx coffee lab","name":"Geometry"}`,
+	} {
+		req := authRequest(http.MethodPost, "/api/v1/coffees", body)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp Coffee
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Roaster != "Onyx Coffee Lab" {
+			t.Errorf("expected roaster name Onyx Coffee Lab, got %q", resp.Roaster)
+		}
+		ids = append(ids, resp.RoasterID)
+	}
+
+	if ids[0] == "" || ids[0] != ids[1] {
+		t.Errorf("expected both coffees on the same roaster, got %v", ids)
+	}
+	if len(repo.roasters) != 1 {
+		t.Errorf("expected 1 roaster, got %d", len(repo.roasters))
+	}
+}
+
+func TestCreate_WithRoasterID(t *testing.T) {
+	repo := newMockRepo()
+	repo.roasters["roaster-1"] = mockRoaster{userID: "user-123", name: "Cata Coffee"}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/coffees", `{"roaster_id":"roaster-1","name":"Kiamaina"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Coffee
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RoasterID != "roaster-1" || resp.Roaster != "Cata Coffee" {
+		t.Errorf("expected Cata Coffee (roaster-1), got %q (%s)", resp.Roaster, resp.RoasterID)
+	}
+}
+
+func TestCreate_UnknownRoasterID(t *testing.T) {
+	repo := newMockRepo()
+	repo.roasters["roaster-1"] = mockRoaster{userID: "user-456", name: "Theirs"}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/coffees", `{"roaster_id":"roaster-1","name":"Kiamaina"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "roaster_id") {
+		t.Errorf("expected error on roaster_id, got %s", w.Body.String())
+	}
+}
+
+func TestList_FilterByRoasterID(t *testing.T) {
+	repo := newMockRepo()
+	a := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	a.RoasterID = "roaster-1"
+	b := seedCoffee(repo, "c-2", "user-123", "Onyx", "Geometry")
+	b.RoasterID = "roaster-2"
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees?roaster_id=roaster-2", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items []Coffee `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].ID != "c-2" {
+		t.Errorf("expected only c-2, got %+v", resp.Items)
+	}
+}
+
+// --- Peak Window Tests ---
+
+// seedRestSamples records brews of coffeeID scoring 9 - (d-10)²/8 at each day
+// off roast, a curve that peaks on day 10 with a window of days 8-12.
+func seedRestSamples(repo *mockRepo, coffeeID string, process, roastLevel *string, days ...int) {
+	for _, d := range days {
+		score := 9 - (d-10)*(d-10)/8
+		repo.restSamples = append(repo.restSamples, RestSample{
+			CoffeeID: coffeeID, Process: process, RoastLevel: roastLevel,
+			DaysOffRoast: d, Score: score,
+		})
+	}
+}
+
+func roastedDaysAgo(days int) *string {
+	s := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02")
+	return &s
+}
+
+func TestPeakWindow_Success(t *testing.T) {
+	repo := newMockRepo()
+	c := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	c.RoastDate = roastedDaysAgo(10)
+	seedRestSamples(repo, "c-1", nil, nil, 2, 6, 10, 14, 18)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/peak-window", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp PeakWindowResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.SampleSize != 5 {
+		t.Errorf("expected sample_size 5, got %d", resp.SampleSize)
+	}
+	if resp.Window == nil {
+		t.Fatal("expected a window")
+	}
+	if resp.Window.PeakDay != 10 || resp.Window.OptimalStart > 10 || resp.Window.OptimalEnd < 10 {
+		t.Errorf("expected a window around day 10, got %+v", *resp.Window)
+	}
+	if resp.DaysOffRoast == nil || *resp.DaysOffRoast != 10 {
+		t.Errorf("expected days_off_roast 10, got %v", resp.DaysOffRoast)
+	}
+	if resp.RestStatus == nil || *resp.RestStatus != "in_window" {
+		t.Errorf("expected rest_status in_window, got %v", resp.RestStatus)
+	}
+	if resp.RestBasis == nil || *resp.RestBasis != RestBasisCoffee {
+		t.Errorf("expected rest_basis coffee, got %v", resp.RestBasis)
+	}
+}
+
+func TestPeakWindow_FallsBackToProcess(t *testing.T) {
+	repo := newMockRepo()
+	c := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	c.RoastDate = roastedDaysAgo(3)
+	c.Process = strPtr("Washed")
+	seedCoffee(repo, "c-2", "user-123", "Onyx", "Geometry")
+	seedRestSamples(repo, "c-1", strPtr("Washed"), nil, 5)
+	seedRestSamples(repo, "c-2", strPtr("washed "), nil, 2, 6, 10, 14, 18)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1/peak-window", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp PeakWindowResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.SampleSize != 1 || resp.Window != nil {
+		t.Errorf("expected 1 sample and no own window, got %d and %+v", resp.SampleSize, resp.Window)
+	}
+	if resp.RestStatus == nil || *resp.RestStatus != "resting" {
+		t.Errorf("expected rest_status resting, got %v", resp.RestStatus)
+	}
+	if resp.RestBasis == nil || *resp.RestBasis != RestBasisProcess {
+		t.Errorf("expected rest_basis process, got %v", resp.RestBasis)
+	}
+}
+
+func TestPeakWindow_NotFound(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/missing/peak-window", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestGetByID_RestStatus(t *testing.T) {
+	repo := newMockRepo()
+	c := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	c.RoastDate = roastedDaysAgo(30)
+	seedRestSamples(repo, "c-1", nil, nil, 2, 6, 10, 14, 18)
+	seedCoffee(repo, "c-2", "user-123", "Onyx", "Geometry")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/c-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp Coffee
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RestStatus == nil || *resp.RestStatus != "past_peak" {
+		t.Errorf("expected rest_status past_peak, got %v", resp.RestStatus)
+	}
+
+	// Without a roast date there is nothing to place in the window.
+	req = authRequest(http.MethodGet, "/api/v1/coffees/c-2", "")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	resp = Coffee{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RestStatus != nil {
+		t.Errorf("expected null rest_status, got %v", *resp.RestStatus)
+	}
+}
+
+func TestPeakWindows_GroupByProcess(t *testing.T) {
+	repo := newMockRepo()
+	seedRestSamples(repo, "c-1", strPtr("Washed"), strPtr("Light"), 2, 6, 10)
+	seedRestSamples(repo, "c-2", strPtr("washed"), strPtr("Light"), 14, 18)
+	seedRestSamples(repo, "c-3", strPtr("Natural"), strPtr("Medium"), 7)
+	seedRestSamples(repo, "c-4", nil, strPtr("Medium"), 7)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/peak-windows", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp PeakWindowGroupsResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.GroupBy != "process" {
+		t.Errorf("expected group_by process, got %q", resp.GroupBy)
+	}
+	if len(resp.Items) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", resp.Items)
+	}
+	washed := resp.Items[0]
+	if washed.Value != "Washed" || washed.CoffeeCount != 2 || washed.SampleSize != 5 || washed.Window == nil {
+		t.Errorf("unexpected washed group: %+v", washed)
+	}
+	natural := resp.Items[1]
+	if natural.SampleSize != 1 || natural.Window != nil {
+		t.Errorf("expected a single-sample natural group without a window, got %+v", natural)
+	}
+}
+
+func TestPeakWindows_GroupByRoastLevel(t *testing.T) {
+	repo := newMockRepo()
+	seedRestSamples(repo, "c-1", nil, strPtr("Light"), 2, 6, 10)
+	seedRestSamples(repo, "c-2", nil, strPtr("Medium"), 14)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/peak-windows?group_by=roast_level", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp PeakWindowGroupsResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.GroupBy != "roast_level" || len(resp.Items) != 2 {
+		t.Errorf("expected 2 roast level groups, got %+v", resp)
+	}
+}
+
+func TestPeakWindows_InvalidGroupBy(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/peak-windows?group_by=country", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestPeakWindows_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/coffees/peak-windows", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
+
+// --- Tag Tests ---
+
+func TestCreate_NormalizesTags(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/coffees",
+		`{"roaster":"Cata Coffee","name":"Kiamaina","tags":[" Blueberry","jasmine","blueberry",""]}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var coffee Coffee
+	json.Unmarshal(w.Body.Bytes(), &coffee)
+	if len(coffee.Tags) != 2 || coffee.Tags[0] != "Blueberry" || coffee.Tags[1] != "jasmine" {
+		t.Errorf("expected [Blueberry jasmine], got %q", coffee.Tags)
+	}
+}
+
+func TestCreate_InvalidTag(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/coffees",
+		`{"roaster":"Cata Coffee","name":"Kiamaina","tags":["ok","lemon, lime"]}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"field":"tags[1]"`) {
+		t.Errorf("expected error on tags[1], got %s", w.Body.String())
+	}
+}
+
+func TestUpdate_OmittedTagsUnchanged(t *testing.T) {
+	repo := newMockRepo()
+	c := seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina")
+	c.Tags = []string{"Blueberry"}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/coffees/c-1", `{"roaster":"Cata Coffee","name":"Kiamaina AA"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(repo.coffees["c-1"].Tags) != 1 {
+		t.Errorf("expected tags unchanged, got %q", repo.coffees["c-1"].Tags)
+	}
+
+	req = authRequest(http.MethodPut, "/api/v1/coffees/c-1", `{"roaster":"Cata Coffee","name":"Kiamaina AA","tags":[]}`)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if len(repo.coffees["c-1"].Tags) != 0 {
+		t.Errorf("expected tags cleared, got %q", repo.coffees["c-1"].Tags)
+	}
+}
+
+func TestList_FilterByTags(t *testing.T) {
+	repo := newMockRepo()
+	seedCoffee(repo, "c-1", "user-123", "Cata Coffee", "Kiamaina").Tags = []string{"Blueberry", "Jasmine"}
+	seedCoffee(repo, "c-2", "user-123", "Onyx", "Geometry").Tags = []string{"blueberry"}
+	seedCoffee(repo, "c-3", "user-123", "Onyx", "Southern Weather").Tags = []string{"Cacao"}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"tags=BLUEBERRY", 2},
+		{"tags=blueberry,cacao", 3},
+		{"tags=blueberry,jasmine&tag_match=all", 1},
+		{"tags=blueberry,cacao&tag_match=all", 0},
+	}
+	for _, tt := range tests {
+		req := authRequest(http.MethodGet, "/api/v1/coffees?"+tt.query, "")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Items []Coffee `json:"items"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Items) != tt.want {
+			t.Errorf("%s: expected %d coffees, got %d", tt.query, tt.want, len(resp.Items))
+		}
+	}
+}
@@ -8,10 +8,14 @@ type ListParams struct {
 	Page         int
 	PerPage      int
 	Search       string
+	RoasterID    string
 	Roaster      string
 	Country      string
 	Process      string
 	ArchivedOnly bool
+	RunningLow   bool
+	Tags         []string
+	TagMatch     string
 }
 
 type Repository interface {
@@ -24,4 +28,15 @@ type Repository interface {
 	Unarchive(ctx context.Context, userID, id string) (*Coffee, error)
 	SetReferenceBrew(ctx context.Context, userID, id string, brewID *string) (*Coffee, error)
 	Suggestions(ctx context.Context, userID, field, query string) ([]string, error)
+	AdjustInventory(ctx context.Context, userID, id string, req AdjustmentRequest) (*Adjustment, error)
+	ListAdjustments(ctx context.Context, userID, id string) ([]Adjustment, error)
+	ListArchiveEvents(ctx context.Context, userID, id string) ([]ArchiveEvent, error)
+
+	// RestSamples returns every brew of the user's that has both a score and
+	// days off roast, for fitting rest windows.
+	RestSamples(ctx context.Context, userID string) ([]RestSample, error)
+
+	// AutoArchive applies every user's auto-archive rule and returns the
+	// events for the coffees it archived.
+	AutoArchive(ctx context.Context) ([]ArchiveEvent, error)
 }
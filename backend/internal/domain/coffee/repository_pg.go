@@ -9,6 +9,9 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
 )
 
 type PgRepository struct {
@@ -19,33 +22,49 @@ func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
 	return &PgRepository{pool: pool}
 }
 
-const coffeeColumns = `c.id, c.user_id, c.roaster, c.name, c.country, c.region, c.farm,
+const roasterNameSubquery = `(SELECT name FROM roasters WHERE roasters.id = c.roaster_id)`
+
+var coffeeColumns = `c.id, c.user_id, c.roaster_id, ` + roasterNameSubquery + ` AS roaster,
+	c.name, c.country, c.region, c.farm,
 	c.varietal, c.elevation, c.process,
 	c.roast_level, c.tasting_notes, c.roast_date, c.notes, c.reference_brew_id,
-	c.archived_at, c.created_at, c.updated_at`
+	c.bag_weight, c.purchase_date, c.price, c.currency, c.remaining_grams,
+	c.archived_at, c.created_at, c.updated_at, ` + coffeeTagsSubquery + ` AS tags`
+
+var coffeeTagsSubquery = tags.Coffees.NamesSQL("c.id")
 
 func scanCoffee(row pgx.Row) (*Coffee, error) {
 	var c Coffee
-	var roastDate *time.Time
+	var roastDate, purchaseDate *time.Time
+	var avgDose *float64
 	err := row.Scan(
-		&c.ID, &c.UserID, &c.Roaster, &c.Name, &c.Country, &c.Region, &c.Farm,
+		&c.ID, &c.UserID, &c.RoasterID, &c.Roaster, &c.Name, &c.Country, &c.Region, &c.Farm,
 		&c.Varietal, &c.Elevation, &c.Process,
 		&c.RoastLevel, &c.TastingNotes, &roastDate, &c.Notes, &c.ReferenceBrewID,
-		&c.ArchivedAt, &c.CreatedAt, &c.UpdatedAt,
-		&c.BrewCount, &c.LastBrewed,
+		&c.BagWeight, &purchaseDate, &c.Price, &c.Currency, &c.RemainingGrams,
+		&c.ArchivedAt, &c.CreatedAt, &c.UpdatedAt, &c.Tags,
+		&c.BrewCount, &c.LastBrewed, &avgDose,
 	)
 	if err != nil {
 		return nil, err
 	}
-	if roastDate != nil {
-		s := roastDate.Format("2006-01-02")
-		c.RoastDate = &s
-	}
+	c.RoastDate = formatDate(roastDate)
+	c.PurchaseDate = formatDate(purchaseDate)
+	c.estimateBrews(avgDose)
 	return &c, nil
 }
 
+func formatDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format("2006-01-02")
+	return &s
+}
+
 const brewCountSubquery = `(SELECT COUNT(*) FROM brews WHERE brews.coffee_id = c.id)`
 const lastBrewedSubquery = `(SELECT MAX(brew_date) FROM brews WHERE brews.coffee_id = c.id)`
+const avgDoseSubquery = `(SELECT AVG(coffee_weight) FROM brews WHERE brews.coffee_id = c.id)`
 
 func (r *PgRepository) List(ctx context.Context, userID string, params ListParams) ([]Coffee, int, error) {
 	conditions := []string{"c.user_id = $1"}
@@ -59,13 +78,19 @@ func (r *PgRepository) List(ctx context.Context, userID string, params ListParam
 	}
 
 	if params.Search != "" {
-		conditions = append(conditions, fmt.Sprintf("(c.roaster ILIKE $%d OR c.name ILIKE $%d)", argIdx, argIdx))
+		conditions = append(conditions, fmt.Sprintf("(%s ILIKE $%d OR c.name ILIKE $%d)", roasterNameSubquery, argIdx, argIdx))
 		args = append(args, "%"+params.Search+"%")
 		argIdx++
 	}
 
+	if params.RoasterID != "" {
+		conditions = append(conditions, fmt.Sprintf("c.roaster_id = $%d", argIdx))
+		args = append(args, params.RoasterID)
+		argIdx++
+	}
+
 	if params.Roaster != "" {
-		conditions = append(conditions, fmt.Sprintf("c.roaster = $%d", argIdx))
+		conditions = append(conditions, fmt.Sprintf("LOWER(%s) = LOWER($%d)", roasterNameSubquery, argIdx))
 		args = append(args, params.Roaster)
 		argIdx++
 	}
@@ -82,6 +107,19 @@ func (r *PgRepository) List(ctx context.Context, userID string, params ListParam
 		argIdx++
 	}
 
+	if len(params.Tags) > 0 {
+		conditions = append(conditions, tags.Coffees.FilterSQL("c.id", params.TagMatch, argIdx))
+		args = append(args, params.Tags)
+		argIdx++
+	}
+
+	if params.RunningLow {
+		conditions = append(conditions, fmt.Sprintf(
+			"c.remaining_grams < $%d * COALESCE(%s, $%d)", argIdx, avgDoseSubquery, argIdx+1))
+		args = append(args, RunningLowBrews, DefaultDose)
+		argIdx += 2
+	}
+
 	where := strings.Join(conditions, " AND ")
 
 	// Count query
@@ -93,12 +131,12 @@ func (r *PgRepository) List(ctx context.Context, userID string, params ListParam
 
 	// Data query
 	query := fmt.Sprintf(
-		`SELECT %s, %s AS brew_count, %s AS last_brewed
+		`SELECT %s, %s AS brew_count, %s AS last_brewed, %s AS avg_dose
 		 FROM coffees c
 		 WHERE %s
 		 ORDER BY c.created_at DESC
 		 LIMIT $%d OFFSET $%d`,
-		coffeeColumns, brewCountSubquery, lastBrewedSubquery,
+		coffeeColumns, brewCountSubquery, lastBrewedSubquery, avgDoseSubquery,
 		where, argIdx, argIdx+1,
 	)
 	args = append(args, params.PerPage, (params.Page-1)*params.PerPage)
@@ -111,37 +149,38 @@ func (r *PgRepository) List(ctx context.Context, userID string, params ListParam
 
 	var coffees []Coffee
 	for rows.Next() {
-		var c Coffee
-		var roastDate *time.Time
-		if err := rows.Scan(
-			&c.ID, &c.UserID, &c.Roaster, &c.Name, &c.Country, &c.Region, &c.Farm,
-			&c.Varietal, &c.Elevation, &c.Process,
-			&c.RoastLevel, &c.TastingNotes, &roastDate, &c.Notes, &c.ReferenceBrewID,
-			&c.ArchivedAt, &c.CreatedAt, &c.UpdatedAt,
-			&c.BrewCount, &c.LastBrewed,
-		); err != nil {
+		c, err := scanCoffee(rows)
+		if err != nil {
 			return nil, 0, err
 		}
-		if roastDate != nil {
-			s := roastDate.Format("2006-01-02")
-			c.RoastDate = &s
-		}
-		coffees = append(coffees, c)
+		coffees = append(coffees, *c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
 	}
 
 	if coffees == nil {
 		coffees = []Coffee{}
 	}
 
+	ptrs := make([]*Coffee, len(coffees))
+	for i := range coffees {
+		ptrs[i] = &coffees[i]
+	}
+	if err := r.attachRestStatus(ctx, userID, ptrs...); err != nil {
+		return nil, 0, err
+	}
+
 	return coffees, total, nil
 }
 
 func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*Coffee, error) {
 	query := fmt.Sprintf(
-		`SELECT %s, %s AS brew_count, %s AS last_brewed
+		`SELECT %s, %s AS brew_count, %s AS last_brewed, %s AS avg_dose
 		 FROM coffees c
 		 WHERE c.id = $1 AND c.user_id = $2`,
-		coffeeColumns, brewCountSubquery, lastBrewedSubquery,
+		coffeeColumns, brewCountSubquery, lastBrewedSubquery, avgDoseSubquery,
 	)
 	c, err := scanCoffee(r.pool.QueryRow(ctx, query, id, userID))
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -150,52 +189,124 @@ func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*Coffee,
 	if err != nil {
 		return nil, err
 	}
+	if err := r.attachRestStatus(ctx, userID, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
+// resolveRoaster returns the roaster the request names. A roaster_id must be
+// one of the user's roasters; a name matches case-insensitively and creates
+// the roaster if the user has none by that name.
+func resolveRoaster(ctx context.Context, tx pgx.Tx, userID string, roasterID *string, name string) (string, error) {
+	var id string
+	if roasterID != nil {
+		err := tx.QueryRow(ctx,
+			`SELECT id FROM roasters WHERE id = $1 AND user_id = $2`,
+			*roasterID, userID,
+		).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrRoasterNotFound
+		}
+		return id, err
+	}
+
+	err := tx.QueryRow(ctx,
+		`INSERT INTO roasters (user_id, name) VALUES ($1, $2)
+		 ON CONFLICT (user_id, LOWER(name)) DO UPDATE SET name = roasters.name
+		 RETURNING id`,
+		userID, name,
+	).Scan(&id)
+	return id, err
+}
+
 func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequest) (*Coffee, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	roasterID, err := resolveRoaster(ctx, tx, userID, req.RoasterID, req.Roaster)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(
 		`WITH inserted AS (
-			INSERT INTO coffees (user_id, roaster, name, country, region, farm, varietal, elevation, process, roast_level, tasting_notes, roast_date, notes)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			INSERT INTO coffees (user_id, roaster_id, name, country, region, farm, varietal, elevation, process, roast_level, tasting_notes, roast_date, notes,
+				bag_weight, purchase_date, price, currency, remaining_grams)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $14)
 			RETURNING *
 		)
-		SELECT %s, %s AS brew_count, %s AS last_brewed
+		SELECT %s, %s AS brew_count, %s AS last_brewed, %s AS avg_dose
 		FROM inserted c`,
-		coffeeColumns, brewCountSubquery, lastBrewedSubquery,
+		coffeeColumns, brewCountSubquery, lastBrewedSubquery, avgDoseSubquery,
 	)
 
-	c, err := scanCoffee(r.pool.QueryRow(ctx, query,
-		userID, req.Roaster, req.Name, req.Country, req.Region, req.Farm,
+	c, err := scanCoffee(tx.QueryRow(ctx, query,
+		userID, roasterID, req.Name, req.Country, req.Region, req.Farm,
 		req.Varietal, req.Elevation, req.Process, req.RoastLevel, req.TastingNotes,
 		req.RoastDate, req.Notes,
+		req.BagWeight, req.PurchaseDate, req.Price, req.Currency,
 	))
 	if err != nil {
 		return nil, err
 	}
+	if err := setTags(ctx, tx, userID, c, req.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.attachRestStatus(ctx, userID, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
+// Update shifts remaining_grams by any change in bag weight so brews already
+// logged stay debited. Setting the bag weight for the first time starts the
+// balance at that weight; clearing it clears the balance.
 func (r *PgRepository) Update(ctx context.Context, userID, id string, req UpdateRequest) (*Coffee, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	roasterID, err := resolveRoaster(ctx, tx, userID, req.RoasterID, req.Roaster)
+	if err != nil {
+		return nil, err
+	}
+
 	query := fmt.Sprintf(
 		`WITH updated AS (
 			UPDATE coffees
-			SET roaster = $1, name = $2, country = $3, region = $4, farm = $5,
+			SET roaster_id = $1, name = $2, country = $3, region = $4, farm = $5,
 				varietal = $6, elevation = $7, process = $8,
 				roast_level = $9, tasting_notes = $10, roast_date = $11, notes = $12,
+				bag_weight = $13, purchase_date = $14, price = $15, currency = $16,
+				remaining_grams = CASE
+					WHEN $13::numeric IS NULL THEN NULL
+					WHEN bag_weight IS NULL THEN $13::numeric
+					ELSE remaining_grams + ($13::numeric - bag_weight)
+				END,
 				updated_at = NOW()
-			WHERE id = $13 AND user_id = $14
+			WHERE id = $17 AND user_id = $18
 			RETURNING *
 		)
-		SELECT %s, %s AS brew_count, %s AS last_brewed
+		SELECT %s, %s AS brew_count, %s AS last_brewed, %s AS avg_dose
 		FROM updated c`,
-		coffeeColumns, brewCountSubquery, lastBrewedSubquery,
+		coffeeColumns, brewCountSubquery, lastBrewedSubquery, avgDoseSubquery,
 	)
 
-	c, err := scanCoffee(r.pool.QueryRow(ctx, query,
-		req.Roaster, req.Name, req.Country, req.Region, req.Farm,
+	c, err := scanCoffee(tx.QueryRow(ctx, query,
+		roasterID, req.Name, req.Country, req.Region, req.Farm,
 		req.Varietal, req.Elevation, req.Process,
 		req.RoastLevel, req.TastingNotes, req.RoastDate, req.Notes,
+		req.BagWeight, req.PurchaseDate, req.Price, req.Currency,
 		id, userID,
 	))
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -204,9 +315,33 @@ func (r *PgRepository) Update(ctx context.Context, userID, id string, req Update
 	if err != nil {
 		return nil, err
 	}
+	if err := setTags(ctx, tx, userID, c, req.Tags); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	if err := r.attachRestStatus(ctx, userID, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
+// setTags replaces the coffee's tags when names is non-nil and reloads them,
+// since existing tags keep their own spelling.
+func setTags(ctx context.Context, tx pgx.Tx, userID string, c *Coffee, names []string) error {
+	if names == nil {
+		return nil
+	}
+	if err := tags.Set(ctx, tx, tags.Coffees, userID, c.ID, names); err != nil {
+		return err
+	}
+	var err error
+	c.Tags, err = tags.Names(ctx, tx, tags.Coffees, c.ID)
+	return err
+}
+
 func (r *PgRepository) Delete(ctx context.Context, userID, id string) error {
 	result, err := r.pool.Exec(ctx,
 		`DELETE FROM coffees WHERE id = $1 AND user_id = $2`,
@@ -216,7 +351,7 @@ func (r *PgRepository) Delete(ctx context.Context, userID, id string) error {
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	return nil
 }
@@ -228,9 +363,9 @@ func (r *PgRepository) Archive(ctx context.Context, userID, id string) (*Coffee,
 			WHERE id = $1 AND user_id = $2 AND archived_at IS NULL
 			RETURNING *
 		)
-		SELECT %s, %s AS brew_count, %s AS last_brewed
+		SELECT %s, %s AS brew_count, %s AS last_brewed, %s AS avg_dose
 		FROM updated c`,
-		coffeeColumns, brewCountSubquery, lastBrewedSubquery,
+		coffeeColumns, brewCountSubquery, lastBrewedSubquery, avgDoseSubquery,
 	)
 
 	c, err := scanCoffee(r.pool.QueryRow(ctx, query, id, userID))
@@ -240,6 +375,9 @@ func (r *PgRepository) Archive(ctx context.Context, userID, id string) (*Coffee,
 	if err != nil {
 		return nil, err
 	}
+	if err := r.attachRestStatus(ctx, userID, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -249,10 +387,13 @@ func (r *PgRepository) Unarchive(ctx context.Context, userID, id string) (*Coffe
 			UPDATE coffees SET archived_at = NULL, updated_at = NOW()
 			WHERE id = $1 AND user_id = $2 AND archived_at IS NOT NULL
 			RETURNING *
+		), reversed AS (
+			UPDATE coffee_archive_events SET reversed_at = NOW()
+			WHERE coffee_id IN (SELECT id FROM updated) AND reversed_at IS NULL
 		)
-		SELECT %s, %s AS brew_count, %s AS last_brewed
+		SELECT %s, %s AS brew_count, %s AS last_brewed, %s AS avg_dose
 		FROM updated c`,
-		coffeeColumns, brewCountSubquery, lastBrewedSubquery,
+		coffeeColumns, brewCountSubquery, lastBrewedSubquery, avgDoseSubquery,
 	)
 
 	c, err := scanCoffee(r.pool.QueryRow(ctx, query, id, userID))
@@ -262,6 +403,9 @@ func (r *PgRepository) Unarchive(ctx context.Context, userID, id string) (*Coffe
 	if err != nil {
 		return nil, err
 	}
+	if err := r.attachRestStatus(ctx, userID, c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -272,9 +416,9 @@ func (r *PgRepository) SetReferenceBrew(ctx context.Context, userID, id string,
 			WHERE id = $2 AND user_id = $3
 			RETURNING *
 		)
-		SELECT %s, %s AS brew_count, %s AS last_brewed
+		SELECT %s, %s AS brew_count, %s AS last_brewed, %s AS avg_dose
 		FROM updated c`,
-		coffeeColumns, brewCountSubquery, lastBrewedSubquery,
+		coffeeColumns, brewCountSubquery, lastBrewedSubquery, avgDoseSubquery,
 	)
 
 	c, err := scanCoffee(r.pool.QueryRow(ctx, query, brewID, id, userID))
@@ -282,13 +426,15 @@ func (r *PgRepository) SetReferenceBrew(ctx context.Context, userID, id string,
 		return nil, nil
 	}
 	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+	if err := r.attachRestStatus(ctx, userID, c); err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
 var allowedSuggestionFields = map[string]string{
-	"roaster":  "roaster",
 	"country":  "country",
 	"process":  "process",
 	"region":   "region",
@@ -296,19 +442,29 @@ var allowedSuggestionFields = map[string]string{
 	"varietal": "varietal",
 }
 
+// Suggestions draws roaster names from the roasters table, so each roaster
+// appears once however its coffees were entered.
 func (r *PgRepository) Suggestions(ctx context.Context, userID, field, query string) ([]string, error) {
-	col, ok := allowedSuggestionFields[field]
-	if !ok {
-		return nil, fmt.Errorf("invalid suggestion field: %s", field)
-	}
+	var sqlQuery string
+	if field == "roaster" {
+		sqlQuery = `SELECT name FROM roasters
+		 WHERE user_id = $1 AND name ILIKE $2
+		 ORDER BY name ASC
+		 LIMIT 20`
+	} else {
+		col, ok := allowedSuggestionFields[field]
+		if !ok {
+			return nil, fmt.Errorf("invalid suggestion field: %s", field)
+		}
 
-	sqlQuery := fmt.Sprintf(
-		`SELECT DISTINCT %s FROM coffees
-		 WHERE user_id = $1 AND %s ILIKE $2 AND %s IS NOT NULL
-		 ORDER BY %s ASC
-		 LIMIT 20`,
-		col, col, col, col,
-	)
+		sqlQuery = fmt.Sprintf(
+			`SELECT DISTINCT %s FROM coffees
+			 WHERE user_id = $1 AND %s ILIKE $2 AND %s IS NOT NULL
+			 ORDER BY %s ASC
+			 LIMIT 20`,
+			col, col, col, col,
+		)
+	}
 
 	rows, err := r.pool.Query(ctx, sqlQuery, userID, "%"+query+"%")
 	if err != nil {
@@ -331,3 +487,216 @@ func (r *PgRepository) Suggestions(ctx context.Context, userID, field, query str
 
 	return items, nil
 }
+
+// AdjustInventory applies a manual change to the coffee's remaining grams and
+// records it. The coffee must have a bag weight.
+func (r *PgRepository) AdjustInventory(ctx context.Context, userID, id string, req AdjustmentRequest) (*Adjustment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var remaining *float64
+	err = tx.QueryRow(ctx,
+		`UPDATE coffees SET remaining_grams = remaining_grams + $1, updated_at = NOW()
+		 WHERE id = $2 AND user_id = $3
+		 RETURNING remaining_grams`,
+		*req.Amount, id, userID,
+	).Scan(&remaining)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if remaining == nil {
+		return nil, ErrNoBagWeight
+	}
+
+	var a Adjustment
+	err = tx.QueryRow(ctx,
+		`INSERT INTO coffee_inventory_adjustments (coffee_id, amount, reason, notes, remaining_after)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, coffee_id, amount, reason, notes, remaining_after, created_at`,
+		id, *req.Amount, req.Reason, req.Notes, *remaining,
+	).Scan(&a.ID, &a.CoffeeID, &a.Amount, &a.Reason, &a.Notes, &a.RemainingAfter, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *PgRepository) ListAdjustments(ctx context.Context, userID, id string) ([]Adjustment, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM coffees WHERE id = $1 AND user_id = $2)`,
+		id, userID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, coffee_id, amount, reason, notes, remaining_after, created_at
+		 FROM coffee_inventory_adjustments WHERE coffee_id = $1 ORDER BY created_at DESC`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	adjustments := []Adjustment{}
+	for rows.Next() {
+		var a Adjustment
+		if err := rows.Scan(&a.ID, &a.CoffeeID, &a.Amount, &a.Reason, &a.Notes, &a.RemainingAfter, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, a)
+	}
+	return adjustments, rows.Err()
+}
+
+const archiveEventColumns = `id, coffee_id, reason, remaining_grams, last_brewed, archived_at, reversed_at`
+
+func scanArchiveEvents(rows pgx.Rows) ([]ArchiveEvent, error) {
+	defer rows.Close()
+
+	events := []ArchiveEvent{}
+	for rows.Next() {
+		var e ArchiveEvent
+		var lastBrewed *time.Time
+		if err := rows.Scan(&e.ID, &e.CoffeeID, &e.Reason, &e.RemainingGrams, &lastBrewed, &e.ArchivedAt, &e.ReversedAt); err != nil {
+			return nil, err
+		}
+		e.LastBrewed = formatDate(lastBrewed)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *PgRepository) ListArchiveEvents(ctx context.Context, userID, id string) ([]ArchiveEvent, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM coffees WHERE id = $1 AND user_id = $2)`,
+		id, userID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+archiveEventColumns+`
+		 FROM coffee_archive_events WHERE coffee_id = $1 ORDER BY archived_at DESC`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanArchiveEvents(rows)
+}
+
+func (r *PgRepository) RestSamples(ctx context.Context, userID string) ([]RestSample, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT b.coffee_id, c.process, c.roast_level, b.days_off_roast, b.overall_score
+		 FROM brews b
+		 JOIN coffees c ON c.id = b.coffee_id
+		 WHERE b.user_id = $1 AND b.days_off_roast IS NOT NULL AND b.overall_score IS NOT NULL
+		 ORDER BY b.brew_date, b.created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var samples []RestSample
+	for rows.Next() {
+		var s RestSample
+		if err := rows.Scan(&s.CoffeeID, &s.Process, &s.RoastLevel, &s.DaysOffRoast, &s.Score); err != nil {
+			return nil, err
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
+
+// attachRestStatus sets RestStatus on coffees from the user's rest samples.
+// The samples are only loaded when some coffee has a roast date.
+func (r *PgRepository) attachRestStatus(ctx context.Context, userID string, coffees ...*Coffee) error {
+	needed := false
+	for _, c := range coffees {
+		if c.RoastDate != nil {
+			needed = true
+			break
+		}
+	}
+	if !needed {
+		return nil
+	}
+
+	samples, err := r.RestSamples(ctx, userID)
+	if err != nil {
+		return err
+	}
+	estimates := estimateRest(samples)
+	today := time.Now().UTC()
+	for _, c := range coffees {
+		c.setRestStatus(estimates, today)
+	}
+	return nil
+}
+
+// AutoArchive archives every active coffee that meets its owner's rule in a
+// single statement. A coffee whose automatic archive was reversed is left
+// alone until it is brewed again, so unarchiving sticks.
+func (r *PgRepository) AutoArchive(ctx context.Context) ([]ArchiveEvent, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH candidates AS (
+			SELECT c.id, c.remaining_grams, b.last_brewed,
+				CASE
+					WHEN rule.remaining_below_grams IS NOT NULL
+						AND c.remaining_grams < rule.remaining_below_grams THEN 'low_remaining'
+					WHEN rule.idle_days IS NOT NULL
+						AND COALESCE(b.last_brewed, c.created_at::date) < CURRENT_DATE - rule.idle_days THEN 'idle'
+				END AS reason
+			FROM coffees c
+			JOIN auto_archive_rules rule ON rule.user_id = c.user_id
+			CROSS JOIN LATERAL (
+				SELECT MAX(brew_date) AS last_brewed, MAX(created_at) AS last_logged
+				FROM brews WHERE brews.coffee_id = c.id
+			) b
+			WHERE c.archived_at IS NULL
+			  AND NOT EXISTS (
+				SELECT 1 FROM coffee_archive_events e
+				WHERE e.coffee_id = c.id
+				  AND e.reversed_at > COALESCE(b.last_logged, '-infinity')
+			  )
+		), archived AS (
+			UPDATE coffees SET archived_at = NOW(), updated_at = NOW()
+			FROM candidates
+			WHERE coffees.id = candidates.id AND candidates.reason IS NOT NULL
+			  AND coffees.archived_at IS NULL
+			RETURNING coffees.id
+		)
+		INSERT INTO coffee_archive_events (coffee_id, reason, remaining_grams, last_brewed)
+		SELECT candidates.id, candidates.reason, candidates.remaining_grams, candidates.last_brewed
+		FROM candidates JOIN archived ON archived.id = candidates.id
+		RETURNING `+archiveEventColumns,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanArchiveEvents(rows)
+}
@@ -0,0 +1,196 @@
+package coffee
+
+import (
+	"strings"
+	"time"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/peak"
+)
+
+// Peak window groupings.
+const (
+	GroupByProcess    = "process"
+	GroupByRoastLevel = "roast_level"
+)
+
+var validPeakGroups = map[string]bool{
+	GroupByProcess:    true,
+	GroupByRoastLevel: true,
+}
+
+// Rest bases name which estimate a coffee's rest status came from.
+const (
+	RestBasisCoffee     = "coffee"
+	RestBasisProcess    = "process"
+	RestBasisRoastLevel = "roast_level"
+)
+
+// RestSample is one scored brew at its days off roast, carrying the coffee's
+// process and roast level so samples can be pooled across coffees.
+type RestSample struct {
+	CoffeeID     string
+	Process      *string
+	RoastLevel   *string
+	DaysOffRoast int
+	Score        int
+}
+
+// PeakWindowResponse is a coffee's own rest window. RestStatus may still be
+// set when Window is nil: it falls back to the window pooled across coffees
+// of the same process, then the same roast level, and RestBasis says which.
+type PeakWindowResponse struct {
+	CoffeeID     string       `json:"coffee_id"`
+	SampleSize   int          `json:"sample_size"`
+	Window       *peak.Window `json:"window"`
+	DaysOffRoast *int         `json:"days_off_roast"`
+	RestStatus   *string      `json:"rest_status"`
+	RestBasis    *string      `json:"rest_basis"`
+}
+
+type PeakWindowGroup struct {
+	Value       string       `json:"value"`
+	CoffeeCount int          `json:"coffee_count"`
+	SampleSize  int          `json:"sample_size"`
+	Window      *peak.Window `json:"window"`
+}
+
+type PeakWindowGroupsResponse struct {
+	GroupBy string            `json:"group_by"`
+	Items   []PeakWindowGroup `json:"items"`
+}
+
+// groupKey normalizes a free-text process or roast level so "Washed" and
+// "washed " pool together. It returns "" for a missing value.
+func groupKey(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(*v))
+}
+
+// restGroup collects the samples sharing one grouping value.
+type restGroup struct {
+	value   string
+	coffees map[string]bool
+	points  []peak.Point
+}
+
+// groupRestSamples pools samples by the field groupBy names, keeping groups
+// in the order first seen. Samples without a value are skipped.
+func groupRestSamples(samples []RestSample, groupBy string) []*restGroup {
+	var groups []*restGroup
+	byKey := map[string]*restGroup{}
+	for _, s := range samples {
+		field := s.Process
+		if groupBy == GroupByRoastLevel {
+			field = s.RoastLevel
+		}
+		key := groupKey(field)
+		if key == "" {
+			continue
+		}
+		g := byKey[key]
+		if g == nil {
+			g = &restGroup{value: strings.TrimSpace(*field), coffees: map[string]bool{}}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.coffees[s.CoffeeID] = true
+		g.points = append(g.points, peak.Point{DaysOffRoast: float64(s.DaysOffRoast), Score: float64(s.Score)})
+	}
+	return groups
+}
+
+// peakWindowGroups estimates a window for each group. Groups too sparse to
+// fit are listed with a nil window.
+func peakWindowGroups(samples []RestSample, groupBy string) []PeakWindowGroup {
+	items := []PeakWindowGroup{}
+	for _, g := range groupRestSamples(samples, groupBy) {
+		items = append(items, PeakWindowGroup{
+			Value:       g.value,
+			CoffeeCount: len(g.coffees),
+			SampleSize:  len(g.points),
+			Window:      peak.Estimate(g.points),
+		})
+	}
+	return items
+}
+
+// restEstimates holds the windows a coffee's rest status can draw on, keyed
+// by coffee ID and by normalized process and roast level.
+type restEstimates struct {
+	byCoffee     map[string]*peak.Window
+	byProcess    map[string]*peak.Window
+	byRoastLevel map[string]*peak.Window
+}
+
+func estimateRest(samples []RestSample) restEstimates {
+	e := restEstimates{
+		byCoffee:     map[string]*peak.Window{},
+		byProcess:    map[string]*peak.Window{},
+		byRoastLevel: map[string]*peak.Window{},
+	}
+	perCoffee := map[string][]peak.Point{}
+	for _, s := range samples {
+		perCoffee[s.CoffeeID] = append(perCoffee[s.CoffeeID], peak.Point{DaysOffRoast: float64(s.DaysOffRoast), Score: float64(s.Score)})
+	}
+	for id, points := range perCoffee {
+		if w := peak.Estimate(points); w != nil {
+			e.byCoffee[id] = w
+		}
+	}
+	for _, g := range groupRestSamples(samples, GroupByProcess) {
+		if w := peak.Estimate(g.points); w != nil {
+			e.byProcess[strings.ToLower(g.value)] = w
+		}
+	}
+	for _, g := range groupRestSamples(samples, GroupByRoastLevel) {
+		if w := peak.Estimate(g.points); w != nil {
+			e.byRoastLevel[strings.ToLower(g.value)] = w
+		}
+	}
+	return e
+}
+
+// windowFor returns the most specific window available for c and its basis.
+func (e restEstimates) windowFor(c *Coffee) (*peak.Window, string) {
+	if w := e.byCoffee[c.ID]; w != nil {
+		return w, RestBasisCoffee
+	}
+	if w := e.byProcess[groupKey(c.Process)]; w != nil {
+		return w, RestBasisProcess
+	}
+	if w := e.byRoastLevel[groupKey(c.RoastLevel)]; w != nil {
+		return w, RestBasisRoastLevel
+	}
+	return nil, ""
+}
+
+// daysOffRoast counts whole days from roastDate to today, or nil when the
+// roast date is unknown.
+func daysOffRoast(roastDate *string, today time.Time) *int {
+	if roastDate == nil {
+		return nil
+	}
+	roasted, err := time.Parse("2006-01-02", *roastDate)
+	if err != nil {
+		return nil
+	}
+	y, m, d := today.Date()
+	days := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(roasted).Hours() / 24)
+	return &days
+}
+
+// setRestStatus sets RestStatus from the coffee's days off roast and the best
+// window e has for it. It is nil without a roast date or a window.
+func (c *Coffee) setRestStatus(e restEstimates, today time.Time) {
+	c.RestStatus = nil
+	days := daysOffRoast(c.RoastDate, today)
+	if days == nil {
+		return
+	}
+	if w, _ := e.windowFor(c); w != nil {
+		status := w.Status(*days)
+		c.RestStatus = &status
+	}
+}
@@ -0,0 +1,64 @@
+package coffee
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// Bounds for bag inventory fields, in grams and the coffee's currency.
+const (
+	maxBagWeight        = 100000
+	maxPrice            = 1000000
+	maxAdjustmentAmount = 100000
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validateRequest checks a coffee create or update request and returns one
+// FieldError per invalid field.
+func validateRequest(req CreateRequest) []api.FieldError {
+	var errs validate.Errors
+
+	if req.RoasterID == nil && req.Roaster == "" {
+		errs.Add("roaster", "Roaster is required")
+	}
+	if req.Name == "" {
+		errs.Add("name", "Name is required")
+	}
+
+	errs.Positive("bag_weight", req.BagWeight, maxBagWeight)
+	errs.Date("purchase_date", req.PurchaseDate)
+	errs.FloatRange("price", req.Price, 0, maxPrice)
+	if req.Currency != nil && !currencyCode.MatchString(*req.Currency) {
+		errs.Add("currency", "must be a three-letter ISO 4217 code")
+	} else if req.Price != nil && req.Currency == nil {
+		errs.Add("currency", "Currency is required when price is set")
+	}
+	tags.Validate(&errs, "tags", req.Tags)
+
+	return errs
+}
+
+// validateAdjustment checks a manual inventory adjustment.
+func validateAdjustment(req AdjustmentRequest) []api.FieldError {
+	var errs validate.Errors
+
+	switch {
+	case req.Amount == nil:
+		errs.Add("amount", "Amount is required")
+	case *req.Amount == 0:
+		errs.Add("amount", "must not be zero")
+	case math.Abs(*req.Amount) >= maxAdjustmentAmount:
+		errs.Add("amount", fmt.Sprintf("must be less than %d grams either way", maxAdjustmentAmount))
+	}
+	if !validAdjustmentReasons[req.Reason] {
+		errs.Add("reason", "must be one of spill, gift, correction, other")
+	}
+
+	return errs
+}
@@ -9,6 +9,8 @@ type DefaultsResponse struct {
 	WaterTemperature *float64       `json:"water_temperature"`
 	FilterPaperID    *string        `json:"filter_paper_id"`
 	DripperID        *string        `json:"dripper_id"`
+	GrinderID        *string        `json:"grinder_id"`
+	WaterProfileID   *string        `json:"water_profile_id"`
 	PourDefaults     []PourDefault  `json:"pour_defaults"`
 }
 
@@ -28,6 +30,8 @@ type UpdateRequest struct {
 	WaterTemperature *float64             `json:"water_temperature"`
 	FilterPaperID    *string              `json:"filter_paper_id"`
 	DripperID        *string              `json:"dripper_id"`
+	GrinderID        *string              `json:"grinder_id"`
+	WaterProfileID   *string              `json:"water_profile_id"`
 	PourDefaults     []PourDefaultRequest `json:"pour_defaults"`
 }
 
@@ -47,9 +51,29 @@ var validFieldNames = map[string]bool{
 	"water_temperature": true,
 	"filter_paper_id":   true,
 	"dripper_id":        true,
+	"grinder_id":        true,
+	"water_profile_id":  true,
 }
 
 // IsValidFieldName checks if a field name is a valid default field.
 func IsValidFieldName(name string) bool {
 	return validFieldNames[name]
 }
+
+// AutoArchiveRule is the user's rule for archiving coffees without pressing
+// Archive. A coffee is archived when its remaining grams drop below
+// RemainingBelowGrams or it hasn't been brewed for IdleDays. Nil turns that
+// condition off.
+type AutoArchiveRule struct {
+	RemainingBelowGrams *float64 `json:"remaining_below_grams"`
+	IdleDays            *int     `json:"idle_days"`
+}
+
+// BrewTargets is the user's ideal box on the brewing control chart, in
+// percent. Nil bounds fall back to the SCA defaults.
+type BrewTargets struct {
+	EYMin  *float64 `json:"ey_min"`
+	EYMax  *float64 `json:"ey_max"`
+	TDSMin *float64 `json:"tds_min"`
+	TDSMax *float64 `json:"tds_max"`
+}
@@ -0,0 +1,11 @@
+package defaults
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound             = domainerr.NotFound("Default not set")
+	ErrFilterPaperNotFound  = domainerr.InvalidReference("filter_paper_id", "Filter paper not found")
+	ErrDripperNotFound      = domainerr.InvalidReference("dripper_id", "Dripper not found")
+	ErrGrinderNotFound      = domainerr.InvalidReference("grinder_id", "Grinder not found")
+	ErrWaterProfileNotFound = domainerr.InvalidReference("water_profile_id", "Water profile not found")
+)
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
@@ -41,35 +40,16 @@ func (h *Handler) Put(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate pour_defaults: pour_number must be >= 1
-	for i, pd := range req.PourDefaults {
-		if pd.PourNumber < 1 {
-			api.ValidationError(w, []api.FieldError{{
-				Field:   "pour_defaults",
-				Message: "pour_number must be >= 1",
-			}})
-			return
-		}
-		// Validate pour_style if provided
-		if pd.PourStyle != nil && *pd.PourStyle != "circular" && *pd.PourStyle != "center" {
-			api.ValidationError(w, []api.FieldError{{
-				Field:   "pour_defaults",
-				Message: "pour_style must be 'circular' or 'center'",
-			}})
-			return
-		}
-		// Ensure pour numbers are sequential starting from 1
-		if pd.PourNumber != i+1 {
-			api.ValidationError(w, []api.FieldError{{
-				Field:   "pour_defaults",
-				Message: "pour_number must be sequential starting from 1",
-			}})
-			return
-		}
+	if fieldErrors := validateRequest(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
 	}
 
 	defaults, err := h.repo.Put(r.Context(), userID, req)
 	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
 		log.Printf("error updating defaults: %v", err)
 		api.InternalError(w)
 		return
@@ -89,8 +69,7 @@ func (h *Handler) DeleteField(w http.ResponseWriter, r *http.Request) {
 
 	err := h.repo.DeleteField(r.Context(), userID, field)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			api.NotFoundError(w, "Default not set")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error deleting default field: %v", err)
@@ -100,3 +79,77 @@ func (h *Handler) DeleteField(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) GetAutoArchive(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	rule, err := h.repo.GetAutoArchiveRule(r.Context(), userID)
+	if err != nil {
+		log.Printf("error getting auto-archive rule: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, rule)
+}
+
+func (h *Handler) PutAutoArchive(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req AutoArchiveRule
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	if fieldErrors := validateAutoArchiveRule(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	rule, err := h.repo.PutAutoArchiveRule(r.Context(), userID, req)
+	if err != nil {
+		log.Printf("error updating auto-archive rule: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, rule)
+}
+
+func (h *Handler) GetBrewTargets(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	targets, err := h.repo.GetBrewTargets(r.Context(), userID)
+	if err != nil {
+		log.Printf("error getting brew targets: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, targets)
+}
+
+func (h *Handler) PutBrewTargets(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req BrewTargets
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	if fieldErrors := validateBrewTargets(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	targets, err := h.repo.PutBrewTargets(r.Context(), userID, req)
+	if err != nil {
+		log.Printf("error updating brew targets: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, targets)
+}
@@ -12,8 +12,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/jackc/pgx/v5"
 
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
 )
 
@@ -22,9 +22,12 @@ const testSecret = "test-jwt-secret-key"
 // --- Mock Repository ---
 
 type mockRepo struct {
-	defaults     map[string]string   // field_name -> value
-	pourDefaults []PourDefault
-	userID       string
+	defaults         map[string]string   // field_name -> value
+	pourDefaults     []PourDefault
+	userID           string
+	deletedEquipment map[string]bool     // IDs that are soft-deleted or not owned
+	autoArchive      AutoArchiveRule
+	brewTargets      BrewTargets
 }
 
 func newMockRepo() *mockRepo {
@@ -32,6 +35,8 @@ func newMockRepo() *mockRepo {
 		defaults:     make(map[string]string),
 		pourDefaults: []PourDefault{},
 		userID:       "user-123",
+
+		deletedEquipment: make(map[string]bool),
 	}
 }
 
@@ -53,6 +58,16 @@ func (m *mockRepo) Put(_ context.Context, userID string, req UpdateRequest) (*De
 		return &DefaultsResponse{PourDefaults: []PourDefault{}}, nil
 	}
 
+	if req.FilterPaperID != nil && m.deletedEquipment[*req.FilterPaperID] {
+		return nil, ErrFilterPaperNotFound
+	}
+	if req.DripperID != nil && m.deletedEquipment[*req.DripperID] {
+		return nil, ErrDripperNotFound
+	}
+	if req.WaterProfileID != nil && m.deletedEquipment[*req.WaterProfileID] {
+		return nil, ErrWaterProfileNotFound
+	}
+
 	// Clear and replace
 	m.defaults = make(map[string]string)
 	fields := buildFieldMap(req)
@@ -75,15 +90,45 @@ func (m *mockRepo) Put(_ context.Context, userID string, req UpdateRequest) (*De
 
 func (m *mockRepo) DeleteField(_ context.Context, userID, fieldName string) error {
 	if userID != m.userID {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	if _, ok := m.defaults[fieldName]; !ok {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	delete(m.defaults, fieldName)
 	return nil
 }
 
+func (m *mockRepo) GetAutoArchiveRule(_ context.Context, userID string) (*AutoArchiveRule, error) {
+	if userID != m.userID {
+		return &AutoArchiveRule{}, nil
+	}
+	rule := m.autoArchive
+	return &rule, nil
+}
+
+func (m *mockRepo) PutAutoArchiveRule(_ context.Context, userID string, rule AutoArchiveRule) (*AutoArchiveRule, error) {
+	if userID == m.userID {
+		m.autoArchive = rule
+	}
+	return &rule, nil
+}
+
+func (m *mockRepo) GetBrewTargets(_ context.Context, userID string) (*BrewTargets, error) {
+	if userID != m.userID {
+		return &BrewTargets{}, nil
+	}
+	t := m.brewTargets
+	return &t, nil
+}
+
+func (m *mockRepo) PutBrewTargets(_ context.Context, userID string, t BrewTargets) (*BrewTargets, error) {
+	if userID == m.userID {
+		m.brewTargets = t
+	}
+	return &t, nil
+}
+
 // Error-returning mock
 
 type errorRepo struct{}
@@ -94,6 +139,18 @@ func (e *errorRepo) Get(_ context.Context, _ string) (*DefaultsResponse, error)
 func (e *errorRepo) Put(_ context.Context, _ string, _ UpdateRequest) (*DefaultsResponse, error) {
 	return nil, errors.New("database error")
 }
+func (e *errorRepo) GetAutoArchiveRule(_ context.Context, _ string) (*AutoArchiveRule, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) PutAutoArchiveRule(_ context.Context, _ string, _ AutoArchiveRule) (*AutoArchiveRule, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) GetBrewTargets(_ context.Context, _ string) (*BrewTargets, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) PutBrewTargets(_ context.Context, _ string, _ BrewTargets) (*BrewTargets, error) {
+	return nil, errors.New("database error")
+}
 func (e *errorRepo) DeleteField(_ context.Context, _, _ string) error {
 	return errors.New("database error")
 }
@@ -118,6 +175,10 @@ func setupRouter(h *Handler) *chi.Mux {
 		r.Use(middleware.RequireAuth(testSecret))
 		r.Get("/", h.Get)
 		r.Put("/", h.Put)
+		r.Get("/auto-archive", h.GetAutoArchive)
+		r.Put("/auto-archive", h.PutAutoArchive)
+		r.Get("/brew-targets", h.GetBrewTargets)
+		r.Put("/brew-targets", h.PutBrewTargets)
 		r.Delete("/{field}", h.DeleteField)
 	})
 	return r
@@ -452,6 +513,96 @@ func TestPut_NonSequentialPourNumbers(t *testing.T) {
 	}
 }
 
+func TestPut_PourDefaultsExceedWaterWeight(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_weight": 15, "ratio": 15, "pour_defaults": [{"pour_number": 1, "water_amount": 100}, {"pour_number": 2, "water_amount": 150}]}`
+	req := authRequest(http.MethodPut, "/api/v1/defaults", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for pours exceeding water weight, got %d", w.Code)
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "pour_defaults" {
+		t.Errorf("expected pour_defaults error, got %v", resp.Error.Details)
+	}
+}
+
+func TestPut_DeletedDripper(t *testing.T) {
+	repo := newMockRepo()
+	repo.defaults["coffee_weight"] = "15"
+	repo.deletedEquipment["d-gone"] = true
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"coffee_weight": 16, "dripper_id": "d-gone"}`
+	req := authRequest(http.MethodPut, "/api/v1/defaults", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "dripper_id" {
+		t.Errorf("expected dripper_id detail, got %v", resp.Error.Details)
+	}
+	if repo.defaults["coffee_weight"] != "15" {
+		t.Errorf("expected existing defaults to be untouched, got %v", repo.defaults)
+	}
+}
+
+func TestPut_WaterProfileID(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"water_profile_id": "wp-1"}`
+	req := authRequest(http.MethodPut, "/api/v1/defaults", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp DefaultsResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.WaterProfileID == nil || *resp.WaterProfileID != "wp-1" {
+		t.Errorf("expected water_profile_id wp-1, got %v", resp.WaterProfileID)
+	}
+}
+
+func TestPut_DeletedWaterProfile(t *testing.T) {
+	repo := newMockRepo()
+	repo.deletedEquipment["wp-gone"] = true
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"water_profile_id": "wp-gone"}`
+	req := authRequest(http.MethodPut, "/api/v1/defaults", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "water_profile_id" {
+		t.Errorf("expected water_profile_id detail, got %v", resp.Error.Details)
+	}
+}
+
 func TestPut_NoPourDefaults(t *testing.T) {
 	repo := newMockRepo()
 	h := NewHandler(repo)
@@ -531,7 +682,7 @@ func TestDeleteField_Success(t *testing.T) {
 }
 
 func TestDeleteField_AllValidFields(t *testing.T) {
-	validFields := []string{"coffee_weight", "ratio", "grind_size", "water_temperature", "filter_paper_id", "dripper_id"}
+	validFields := []string{"coffee_weight", "ratio", "grind_size", "water_temperature", "filter_paper_id", "dripper_id", "grinder_id", "water_profile_id"}
 	for _, field := range validFields {
 		repo := newMockRepo()
 		repo.defaults[field] = "test"
@@ -676,3 +827,179 @@ func TestPut_PourDefaultsWithOptionalFields(t *testing.T) {
 		t.Error("expected wait_time nil")
 	}
 }
+
+func TestGetAutoArchive_Unset(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/defaults/auto-archive", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var raw map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &raw)
+	if raw["remaining_below_grams"] != nil || raw["idle_days"] != nil {
+		t.Errorf("expected both conditions null, got %v", raw)
+	}
+}
+
+func TestPutAutoArchive_Success(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/defaults/auto-archive", `{"remaining_below_grams":10,"idle_days":30}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp AutoArchiveRule
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.RemainingBelowGrams == nil || *resp.RemainingBelowGrams != 10 {
+		t.Errorf("expected remaining_below_grams 10, got %v", resp.RemainingBelowGrams)
+	}
+	if repo.autoArchive.IdleDays == nil || *repo.autoArchive.IdleDays != 30 {
+		t.Errorf("expected stored idle_days 30, got %v", repo.autoArchive.IdleDays)
+	}
+}
+
+func TestPutAutoArchive_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"zero grams", `{"remaining_below_grams":0}`, "remaining_below_grams"},
+		{"negative grams", `{"remaining_below_grams":-5}`, "remaining_below_grams"},
+		{"zero idle days", `{"idle_days":0}`, "idle_days"},
+		{"idle days too large", `{"idle_days":5000}`, "idle_days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(newMockRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPut, "/api/v1/defaults/auto-archive", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.field) {
+				t.Errorf("expected error on %s, got %s", tt.field, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPutAutoArchive_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/defaults/auto-archive", `{"idle_days":30}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestGetBrewTargets_Unset(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/defaults/brew-targets", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var raw map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &raw)
+	for _, field := range []string{"ey_min", "ey_max", "tds_min", "tds_max"} {
+		if v, ok := raw[field]; !ok || v != nil {
+			t.Errorf("expected %s null, got %v", field, raw)
+		}
+	}
+}
+
+func TestPutBrewTargets_Success(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/defaults/brew-targets", `{"ey_min":19,"tds_max":1.45}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp BrewTargets
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.EYMin == nil || *resp.EYMin != 19 || resp.EYMax != nil {
+		t.Errorf("expected ey_min 19 and ey_max null, got %v and %v", resp.EYMin, resp.EYMax)
+	}
+	if repo.brewTargets.TDSMax == nil || *repo.brewTargets.TDSMax != 1.45 {
+		t.Errorf("expected stored tds_max 1.45, got %v", repo.brewTargets.TDSMax)
+	}
+}
+
+func TestPutBrewTargets_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"negative bound", `{"ey_min":-1}`, "ey_min"},
+		{"ey too large", `{"ey_max":45}`, "ey_max"},
+		{"min above max", `{"tds_min":1.5,"tds_max":1.2}`, "tds_max"},
+		{"min above default max", `{"ey_min":23}`, "ey_max"},
+		{"max below default min", `{"tds_max":1.1}`, "tds_max"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(newMockRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPut, "/api/v1/defaults/brew-targets", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.field) {
+				t.Errorf("expected error on %s, got %s", tt.field, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestPutBrewTargets_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/defaults/brew-targets", `{"ey_min":19}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
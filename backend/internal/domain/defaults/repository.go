@@ -16,4 +16,20 @@ type Repository interface {
 
 	// DeleteField removes a single default by field name.
 	DeleteField(ctx context.Context, userID, fieldName string) error
+
+	// GetAutoArchiveRule returns the user's auto-archive rule, with both
+	// conditions nil when none is set.
+	GetAutoArchiveRule(ctx context.Context, userID string) (*AutoArchiveRule, error)
+
+	// PutAutoArchiveRule replaces the user's auto-archive rule. A rule with
+	// both conditions nil is removed.
+	PutAutoArchiveRule(ctx context.Context, userID string, rule AutoArchiveRule) (*AutoArchiveRule, error)
+
+	// GetBrewTargets returns the user's brew targets, with every bound nil
+	// when none is set.
+	GetBrewTargets(ctx context.Context, userID string) (*BrewTargets, error)
+
+	// PutBrewTargets replaces the user's brew targets. Targets with every
+	// bound nil are removed.
+	PutBrewTargets(ctx context.Context, userID string, targets BrewTargets) (*BrewTargets, error)
 }
@@ -2,10 +2,14 @@ package defaults
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
 )
 
 type PgRepository struct {
@@ -76,6 +80,40 @@ func (r *PgRepository) Put(ctx context.Context, userID string, req UpdateRequest
 	}
 	defer tx.Rollback(ctx)
 
+	// Verify referenced equipment belongs to the user and is not deleted
+	if req.FilterPaperID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "filter_papers", userID, *req.FilterPaperID, ErrFilterPaperNotFound); err != nil {
+			return nil, err
+		}
+	}
+	if req.DripperID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "drippers", userID, *req.DripperID, ErrDripperNotFound); err != nil {
+			return nil, err
+		}
+	}
+	if req.WaterProfileID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "water_profiles", userID, *req.WaterProfileID, ErrWaterProfileNotFound); err != nil {
+			return nil, err
+		}
+	}
+	if req.GrinderID != nil {
+		var scale validate.GrindScale
+		err := tx.QueryRow(ctx,
+			`SELECT scale_min, scale_max, scale_step, stepless, micron_min, micron_max
+			 FROM grinders WHERE id::text = $1 AND user_id = $2 AND deleted_at IS NULL`,
+			*req.GrinderID, userID,
+		).Scan(&scale.Min, &scale.Max, &scale.Step, &scale.Stepless, &scale.MicronMin, &scale.MicronMax)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGrinderNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := validate.GrindSizeError("grind_size", req.GrindSize, scale); err != nil {
+			return nil, err
+		}
+	}
+
 	// Delete all existing key-value defaults
 	if _, err := tx.Exec(ctx, `DELETE FROM user_defaults WHERE user_id = $1`, userID); err != nil {
 		return nil, err
@@ -124,11 +162,83 @@ func (r *PgRepository) DeleteField(ctx context.Context, userID, fieldName string
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	return nil
 }
 
+func (r *PgRepository) GetAutoArchiveRule(ctx context.Context, userID string) (*AutoArchiveRule, error) {
+	var rule AutoArchiveRule
+	err := r.pool.QueryRow(ctx,
+		`SELECT remaining_below_grams, idle_days FROM auto_archive_rules WHERE user_id = $1`,
+		userID,
+	).Scan(&rule.RemainingBelowGrams, &rule.IdleDays)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *PgRepository) PutAutoArchiveRule(ctx context.Context, userID string, rule AutoArchiveRule) (*AutoArchiveRule, error) {
+	if rule.RemainingBelowGrams == nil && rule.IdleDays == nil {
+		if _, err := r.pool.Exec(ctx, `DELETE FROM auto_archive_rules WHERE user_id = $1`, userID); err != nil {
+			return nil, err
+		}
+		return &rule, nil
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO auto_archive_rules (user_id, remaining_below_grams, idle_days)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET remaining_below_grams = EXCLUDED.remaining_below_grams,
+		     idle_days = EXCLUDED.idle_days,
+		     updated_at = NOW()`,
+		userID, rule.RemainingBelowGrams, rule.IdleDays,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *PgRepository) GetBrewTargets(ctx context.Context, userID string) (*BrewTargets, error) {
+	var t BrewTargets
+	err := r.pool.QueryRow(ctx,
+		`SELECT ey_min, ey_max, tds_min, tds_max FROM brew_targets WHERE user_id = $1`,
+		userID,
+	).Scan(&t.EYMin, &t.EYMax, &t.TDSMin, &t.TDSMax)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PgRepository) PutBrewTargets(ctx context.Context, userID string, t BrewTargets) (*BrewTargets, error) {
+	if t.EYMin == nil && t.EYMax == nil && t.TDSMin == nil && t.TDSMax == nil {
+		if _, err := r.pool.Exec(ctx, `DELETE FROM brew_targets WHERE user_id = $1`, userID); err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO brew_targets (user_id, ey_min, ey_max, tds_min, tds_max)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET ey_min = EXCLUDED.ey_min,
+		     ey_max = EXCLUDED.ey_max,
+		     tds_min = EXCLUDED.tds_min,
+		     tds_max = EXCLUDED.tds_max,
+		     updated_at = NOW()`,
+		userID, t.EYMin, t.EYMax, t.TDSMin, t.TDSMax,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // buildFieldMap converts the request fields into a map of field_name -> string value.
 // Only non-nil fields are included.
 func buildFieldMap(req UpdateRequest) map[string]string {
@@ -151,6 +261,12 @@ func buildFieldMap(req UpdateRequest) map[string]string {
 	if req.DripperID != nil {
 		fields["dripper_id"] = *req.DripperID
 	}
+	if req.GrinderID != nil {
+		fields["grinder_id"] = *req.GrinderID
+	}
+	if req.WaterProfileID != nil {
+		fields["water_profile_id"] = *req.WaterProfileID
+	}
 	return fields
 }
 
@@ -177,5 +293,9 @@ func applyFieldToResponse(resp *DefaultsResponse, fieldName, value string) {
 		resp.FilterPaperID = &value
 	case "dripper_id":
 		resp.DripperID = &value
+	case "grinder_id":
+		resp.GrinderID = &value
+	case "water_profile_id":
+		resp.WaterProfileID = &value
 	}
 }
@@ -0,0 +1,74 @@
+package defaults
+
+import (
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/brew"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// Bounds for the auto-archive rule.
+const (
+	maxAutoArchiveGrams = 1000
+	maxIdleDays         = 3650
+)
+
+// Upper limits for brew target bounds, matching DECIMAL(4,2) columns.
+const (
+	maxExtractionTarget = 30
+	maxStrengthTarget   = 20
+)
+
+// validateRequest applies the brew field rules to the default values and
+// pour templates so that defaults always produce a valid brew.
+func validateRequest(req UpdateRequest) []api.FieldError {
+	var errs validate.Errors
+
+	errs.Setup(req.CoffeeWeight, req.Ratio, req.WaterTemperature)
+
+	pours := make([]validate.Pour, len(req.PourDefaults))
+	for i, pd := range req.PourDefaults {
+		pours[i] = validate.Pour(pd)
+	}
+	errs.Pours("pour_defaults", pours, req.CoffeeWeight, req.Ratio)
+
+	return errs
+}
+
+// validateAutoArchiveRule checks that each enabled condition is positive.
+func validateAutoArchiveRule(rule AutoArchiveRule) []api.FieldError {
+	var errs validate.Errors
+
+	errs.Positive("remaining_below_grams", rule.RemainingBelowGrams, maxAutoArchiveGrams)
+	errs.IntRange("idle_days", rule.IdleDays, 1, maxIdleDays)
+
+	return errs
+}
+
+// validateBrewTargets checks each bound and that the ranges are not empty. A
+// nil bound stands for its SCA default, so each minimum must stay below its
+// maximum once the defaults are filled in.
+func validateBrewTargets(t BrewTargets) []api.FieldError {
+	var errs validate.Errors
+
+	errs.Positive("ey_min", t.EYMin, maxExtractionTarget)
+	errs.Positive("ey_max", t.EYMax, maxExtractionTarget)
+	errs.Positive("tds_min", t.TDSMin, maxStrengthTarget)
+	errs.Positive("tds_max", t.TDSMax, maxStrengthTarget)
+
+	sca := brew.SCATargets
+	if orDefault(t.EYMin, sca.EYMin) >= orDefault(t.EYMax, sca.EYMax) {
+		errs.Add("ey_max", "must be greater than ey_min")
+	}
+	if orDefault(t.TDSMin, sca.TDSMin) >= orDefault(t.TDSMax, sca.TDSMax) {
+		errs.Add("tds_max", "must be greater than tds_min")
+	}
+
+	return errs
+}
+
+func orDefault(v *float64, def float64) float64 {
+	if v == nil {
+		return def
+	}
+	return *v
+}
@@ -0,0 +1,13 @@
+package dripper
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound = domainerr.NotFound("Dripper not found")
+	ErrConflict = domainerr.Conflict("A dripper with this name already exists")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"idx_drippers_user_name": ErrConflict,
+}
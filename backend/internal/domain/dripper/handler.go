@@ -6,7 +6,6 @@ import (
 	"strings"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
@@ -78,8 +77,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	d, err := h.repo.Create(r.Context(), userID, req)
 	if err != nil {
-		if isDuplicateNameError(err) {
-			api.ConflictError(w, "A dripper with this name already exists")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error creating dripper: %v", err)
@@ -108,8 +106,7 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 
 	d, err := h.repo.Update(r.Context(), userID, id, req)
 	if err != nil {
-		if isDuplicateNameError(err) {
-			api.ConflictError(w, "A dripper with this name already exists")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error updating dripper: %v", err)
@@ -130,8 +127,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	err := h.repo.SoftDelete(r.Context(), userID, id)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			api.NotFoundError(w, "Dripper not found")
+		if api.DomainError(w, err) {
 			return
 		}
 		log.Printf("error deleting dripper: %v", err)
@@ -141,7 +137,3 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
-
-func isDuplicateNameError(err error) bool {
-	return strings.Contains(err.Error(), "idx_drippers_user_name")
-}
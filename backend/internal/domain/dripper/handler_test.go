@@ -12,7 +12,6 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
@@ -63,7 +62,7 @@ func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (
 	// Check for duplicate name
 	for _, d := range m.drippers {
 		if d.UserID == userID && d.Name == req.Name && d.DeletedAt == nil {
-			return nil, errors.New("duplicate key value violates unique constraint \"idx_drippers_user_name\"")
+			return nil, ErrConflict
 		}
 	}
 
@@ -92,7 +91,7 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 	// Check for duplicate name (excluding self)
 	for _, other := range m.drippers {
 		if other.UserID == userID && other.Name == req.Name && other.ID != id && other.DeletedAt == nil {
-			return nil, errors.New("duplicate key value violates unique constraint \"idx_drippers_user_name\"")
+			return nil, ErrConflict
 		}
 	}
 
@@ -106,7 +105,7 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 func (m *mockRepo) SoftDelete(_ context.Context, userID, id string) error {
 	d := m.drippers[id]
 	if d == nil || d.UserID != userID || d.DeletedAt != nil {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	now := time.Now()
 	d.DeletedAt = &now
@@ -8,6 +8,8 @@ import (
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
 )
 
 type PgRepository struct {
@@ -119,7 +121,7 @@ func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequ
 		userID, req.Name, req.Brand, req.Notes,
 	).Scan(&d.ID, &d.UserID, &d.Name, &d.Brand, &d.Notes, &d.CreatedAt, &d.UpdatedAt)
 	if err != nil {
-		return nil, err
+		return nil, domainerr.FromPg(err, pgErrors)
 	}
 	return &d, nil
 }
@@ -137,7 +139,7 @@ func (r *PgRepository) Update(ctx context.Context, userID, id string, req Update
 		return nil, nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, domainerr.FromPg(err, pgErrors)
 	}
 	return &d, nil
 }
@@ -152,7 +154,7 @@ func (r *PgRepository) SoftDelete(ctx context.Context, userID, id string) error
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	return nil
 }
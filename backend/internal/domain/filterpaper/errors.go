@@ -0,0 +1,13 @@
+package filterpaper
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound = domainerr.NotFound("Filter paper not found")
+	ErrConflict = domainerr.Conflict("A filter paper with this name already exists")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"idx_filter_papers_user_name": ErrConflict,
+}
@@ -12,7 +12,6 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/jackc/pgx/v5"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
@@ -63,7 +62,7 @@ func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (
 	// Check for duplicate name
 	for _, p := range m.papers {
 		if p.UserID == userID && p.Name == req.Name && p.DeletedAt == nil {
-			return nil, errors.New("duplicate key value violates unique constraint \"idx_filter_papers_user_name\"")
+			return nil, ErrConflict
 		}
 	}
 
@@ -92,7 +91,7 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 	// Check for duplicate name (excluding self)
 	for _, other := range m.papers {
 		if other.UserID == userID && other.Name == req.Name && other.ID != id && other.DeletedAt == nil {
-			return nil, errors.New("duplicate key value violates unique constraint \"idx_filter_papers_user_name\"")
+			return nil, ErrConflict
 		}
 	}
 
@@ -106,7 +105,7 @@ func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateReques
 func (m *mockRepo) SoftDelete(_ context.Context, userID, id string) error {
 	p := m.papers[id]
 	if p == nil || p.UserID != userID || p.DeletedAt != nil {
-		return pgx.ErrNoRows
+		return ErrNotFound
 	}
 	now := time.Now()
 	p.DeletedAt = &now
@@ -0,0 +1,60 @@
+package flavor
+
+import "github.com/poimgs/coffee-tracker/backend/internal/wheel"
+
+// Profile groupings.
+const (
+	GroupByCoffee = "coffee"
+	GroupByOrigin = "origin"
+)
+
+var validGroups = map[string]bool{
+	GroupByCoffee: true,
+	GroupByOrigin: true,
+}
+
+// Note is one piece of flavor text about a coffee: its tasting notes or one
+// of its tags, or, when BrewID is set, a tag on one of its brews.
+type Note struct {
+	CoffeeID string
+	Coffee   string
+	Roaster  string
+	Country  *string
+	BrewID   *string
+	Text     string
+}
+
+type WheelResponse struct {
+	Items []wheel.Category `json:"items"`
+}
+
+// Profile counts the flavors perceived in a coffee or in an origin's coffees
+// by wheel category. CoffeeID and Roaster are only set per coffee.
+type Profile struct {
+	CoffeeID     *string         `json:"coffee_id"`
+	Name         string          `json:"name"`
+	Roaster      *string         `json:"roaster"`
+	CoffeeCount  int             `json:"coffee_count"`
+	MentionCount int             `json:"mention_count"`
+	Categories   []CategoryCount `json:"categories"`
+}
+
+// CategoryCount is the mentions of one category. Share is its fraction of
+// the profile's mentions. Mentions of the category as a whole ("fruity")
+// count towards Count but no subcategory.
+type CategoryCount struct {
+	Name          string             `json:"name"`
+	Count         int                `json:"count"`
+	Share         float64            `json:"share"`
+	Subcategories []SubcategoryCount `json:"subcategories"`
+}
+
+type SubcategoryCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type ProfilesResponse struct {
+	GroupBy string    `json:"group_by"`
+	Items   []Profile `json:"items"`
+}
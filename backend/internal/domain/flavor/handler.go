@@ -0,0 +1,48 @@
+package flavor
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/wheel"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// Wheel returns the flavor wheel that notes are mapped onto.
+func (h *Handler) Wheel(w http.ResponseWriter, r *http.Request) {
+	api.WriteJSON(w, http.StatusOK, WheelResponse{Items: wheel.Categories()})
+}
+
+func (h *Handler) Profiles(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = GroupByCoffee
+	}
+	if !validGroups[groupBy] {
+		api.ValidationError(w, []api.FieldError{{Field: "group_by", Message: "must be one of coffee, origin"}})
+		return
+	}
+
+	notes, err := h.repo.Notes(r.Context(), userID)
+	if err != nil {
+		log.Printf("error loading flavor notes: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, ProfilesResponse{
+		GroupBy: groupBy,
+		Items:   profiles(notes, groupBy),
+	})
+}
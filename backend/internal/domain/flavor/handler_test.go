@@ -0,0 +1,249 @@
+package flavor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockRepo struct {
+	notes map[string][]Note
+}
+
+func (m *mockRepo) Notes(_ context.Context, userID string) ([]Note, error) {
+	return m.notes[userID], nil
+}
+
+type errorRepo struct{}
+
+func (e *errorRepo) Notes(_ context.Context, _ string) ([]Note, error) {
+	return nil, errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/flavors", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/wheel", h.Wheel)
+		r.Get("/profiles", h.Profiles)
+	})
+	return r
+}
+
+func authRequest(method, url string) *http.Request {
+	req := httptest.NewRequest(method, url, nil)
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func strPtr(s string) *string { return &s }
+
+// seedNotes gives user-123 two Kenyan coffees and an Ethiopian one.
+func seedNotes() *mockRepo {
+	kenya := strPtr("Kenya")
+	return &mockRepo{notes: map[string][]Note{
+		"user-123": {
+			{CoffeeID: "c-1", Coffee: "Kiamaina", Roaster: "Cata", Country: kenya, Text: "Blackcurrant, Lemon Sorbet, Raw Honey"},
+			{CoffeeID: "c-1", Coffee: "Kiamaina", Roaster: "Cata", Country: kenya, Text: "Lemon"},
+			{CoffeeID: "c-1", Coffee: "Kiamaina", Roaster: "Cata", Country: kenya, BrewID: strPtr("b-1"), Text: "lemon"},
+			{CoffeeID: "c-1", Coffee: "Kiamaina", Roaster: "Cata", Country: kenya, BrewID: strPtr("b-2"), Text: "Lemon"},
+			{CoffeeID: "c-2", Coffee: "Gatomboya", Roaster: "Tim Wendelboe", Country: strPtr(" kenya "), Text: "Grapefruit, dark chocolate"},
+			{CoffeeID: "c-3", Coffee: "Guji", Roaster: "Onyx", Country: strPtr("Ethiopia"), Text: "Jasmine, Peach"},
+			{CoffeeID: "c-4", Coffee: "House Blend", Roaster: "Onyx", Text: "Balanced"},
+		},
+		"other-user": {
+			{CoffeeID: "c-9", Coffee: "Other", Roaster: "Other", Country: kenya, Text: "Lemon"},
+		},
+	}}
+}
+
+// --- Tests ---
+
+func TestWheel(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/flavors/wheel")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var resp struct {
+		Items []struct {
+			Name          string `json:"name"`
+			Subcategories []struct {
+				Name        string   `json:"name"`
+				Descriptors []string `json:"descriptors"`
+			} `json:"subcategories"`
+		} `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 9 || resp.Items[1].Name != "Fruity" {
+		t.Fatalf("expected the 9 wheel categories, got %+v", resp.Items)
+	}
+	if resp.Items[1].Subcategories[0].Descriptors[2] != "Blueberry" {
+		t.Errorf("expected Fruity > Berry > Blueberry, got %+v", resp.Items[1].Subcategories[0])
+	}
+}
+
+func TestProfiles_ByCoffee(t *testing.T) {
+	h := NewHandler(seedNotes())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/flavors/profiles")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ProfilesResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.GroupBy != GroupByCoffee {
+		t.Errorf("expected group_by coffee, got %q", resp.GroupBy)
+	}
+	if len(resp.Items) != 3 {
+		t.Fatalf("expected 3 coffees with flavors, got %d", len(resp.Items))
+	}
+
+	p := resp.Items[0]
+	if p.CoffeeID == nil || *p.CoffeeID != "c-1" || p.Roaster == nil || *p.Roaster != "Cata" {
+		t.Fatalf("expected Kiamaina first, got %+v", p)
+	}
+	// Blackcurrant, Lemon and Honey from the coffee (its Lemon tag repeats
+	// the tasting notes), plus Lemon on each of two brews.
+	if p.MentionCount != 5 {
+		t.Errorf("expected 5 mentions, got %d", p.MentionCount)
+	}
+	if len(p.Categories) != 2 {
+		t.Fatalf("expected Fruity and Sweet, got %+v", p.Categories)
+	}
+	fruity := p.Categories[0]
+	if fruity.Name != "Fruity" || fruity.Count != 4 || fruity.Share != 0.8 {
+		t.Errorf("expected Fruity with 4 mentions (0.8), got %+v", fruity)
+	}
+	if len(fruity.Subcategories) != 2 || fruity.Subcategories[0].Name != "Citrus Fruit" || fruity.Subcategories[0].Count != 3 {
+		t.Errorf("expected Citrus Fruit (3) then Berry, got %+v", fruity.Subcategories)
+	}
+	if p.Categories[1].Name != "Sweet" || p.Categories[1].Share != 0.2 {
+		t.Errorf("expected Sweet at 0.2, got %+v", p.Categories[1])
+	}
+}
+
+func TestProfiles_ByOrigin(t *testing.T) {
+	h := NewHandler(seedNotes())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/flavors/profiles?group_by=origin")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp ProfilesResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 2 {
+		t.Fatalf("expected Kenya and Ethiopia, got %+v", resp.Items)
+	}
+	kenya := resp.Items[0]
+	if kenya.Name != "Kenya" || kenya.CoffeeCount != 2 || kenya.CoffeeID != nil {
+		t.Errorf("expected Kenya with 2 coffees, got %+v", kenya)
+	}
+	if kenya.MentionCount != 7 {
+		t.Errorf("expected 7 mentions, got %d", kenya.MentionCount)
+	}
+	if kenya.Categories[0].Name != "Fruity" || kenya.Categories[0].Count != 5 {
+		t.Errorf("expected Fruity with 5 mentions first, got %+v", kenya.Categories[0])
+	}
+	if resp.Items[1].Name != "Ethiopia" || resp.Items[1].Categories[0].Name != "Floral" {
+		t.Errorf("expected Ethiopia led by Floral on a tie, got %+v", resp.Items[1])
+	}
+}
+
+func TestProfiles_Empty(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/flavors/profiles")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if body := w.Body.String(); body != `{"group_by":"coffee","items":[]}`+"\n" {
+		t.Errorf("expected empty items, got %s", body)
+	}
+}
+
+func TestProfiles_InvalidGroupBy(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/flavors/profiles?group_by=roaster")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestProfiles_Unauthenticated(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/flavors/profiles", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w.Code)
+	}
+}
+
+func TestProfiles_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/flavors/profiles")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
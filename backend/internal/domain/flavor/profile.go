@@ -0,0 +1,131 @@
+package flavor
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/wheel"
+)
+
+// mentionKey identifies a wheel segment named by one coffee's own notes or
+// by one brew's tags.
+type mentionKey struct {
+	source string
+	match  wheel.Match
+}
+
+type profileGroup struct {
+	profile  Profile
+	coffees  map[string]bool
+	mentions map[mentionKey]bool
+}
+
+// profiles aggregates notes by coffee or by origin, keeping groups in the
+// order first seen. A segment counts once per coffee across its tasting notes
+// and tags, which overlap, and once per brew that was tagged with it. Origins
+// are countries matched ignoring case; coffees without one are left out.
+// Groups with no recognized flavors are dropped.
+func profiles(notes []Note, groupBy string) []Profile {
+	var groups []*profileGroup
+	byKey := map[string]*profileGroup{}
+	for _, n := range notes {
+		key := n.CoffeeID
+		if groupBy == GroupByOrigin {
+			if n.Country == nil || strings.TrimSpace(*n.Country) == "" {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(*n.Country))
+		}
+
+		matches := wheel.Map(n.Text)
+		if len(matches) == 0 {
+			continue
+		}
+
+		g := byKey[key]
+		if g == nil {
+			g = &profileGroup{coffees: map[string]bool{}, mentions: map[mentionKey]bool{}}
+			if groupBy == GroupByOrigin {
+				g.profile.Name = strings.TrimSpace(*n.Country)
+			} else {
+				id, roaster := n.CoffeeID, n.Roaster
+				g.profile.CoffeeID = &id
+				g.profile.Name = n.Coffee
+				g.profile.Roaster = &roaster
+			}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+
+		g.coffees[n.CoffeeID] = true
+		source := "coffee:" + n.CoffeeID
+		if n.BrewID != nil {
+			source = "brew:" + *n.BrewID
+		}
+		for _, m := range matches {
+			g.mentions[mentionKey{source: source, match: m}] = true
+		}
+	}
+
+	items := []Profile{}
+	for _, g := range groups {
+		p := g.profile
+		p.CoffeeCount = len(g.coffees)
+		p.MentionCount = len(g.mentions)
+		p.Categories = countCategories(g.mentions)
+		items = append(items, p)
+	}
+	return items
+}
+
+// countCategories tallies mentions by category and subcategory, most
+// mentioned first and in wheel order on ties.
+func countCategories(mentions map[mentionKey]bool) []CategoryCount {
+	byCategory := map[string]*CategoryCount{}
+	subcounts := map[string]map[string]int{}
+	for k := range mentions {
+		c := byCategory[k.match.Category]
+		if c == nil {
+			c = &CategoryCount{Name: k.match.Category, Subcategories: []SubcategoryCount{}}
+			byCategory[k.match.Category] = c
+			subcounts[k.match.Category] = map[string]int{}
+		}
+		c.Count++
+		if k.match.Subcategory != "" {
+			subcounts[k.match.Category][k.match.Subcategory]++
+		}
+	}
+
+	catOrder := map[string]int{}
+	subOrder := map[string]int{}
+	for i, cat := range wheel.Categories() {
+		catOrder[cat.Name] = i
+		for j, sub := range cat.Subcategories {
+			subOrder[sub.Name] = j
+		}
+	}
+
+	counts := []CategoryCount{}
+	for name, c := range byCategory {
+		for sub, n := range subcounts[name] {
+			c.Subcategories = append(c.Subcategories, SubcategoryCount{Name: sub, Count: n})
+		}
+		sort.Slice(c.Subcategories, func(i, j int) bool {
+			a, b := c.Subcategories[i], c.Subcategories[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return subOrder[a.Name] < subOrder[b.Name]
+		})
+		c.Share = math.Round(float64(c.Count)/float64(len(mentions))*100) / 100
+		counts = append(counts, *c)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return catOrder[counts[i].Name] < catOrder[counts[j].Name]
+	})
+	return counts
+}
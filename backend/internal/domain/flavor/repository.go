@@ -0,0 +1,9 @@
+package flavor
+
+import "context"
+
+type Repository interface {
+	// Notes returns the flavor text of the user's coffees and their brews,
+	// newest coffee first.
+	Notes(ctx context.Context, userID string) ([]Note, error)
+}
@@ -0,0 +1,54 @@
+package flavor
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+func (r *PgRepository) Notes(ctx context.Context, userID string) ([]Note, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT c.id, c.name, ro.name, c.country, n.brew_id, n.text
+		 FROM coffees c
+		 JOIN roasters ro ON ro.id = c.roaster_id
+		 CROSS JOIN LATERAL (
+			SELECT NULL::uuid AS brew_id, c.tasting_notes AS text
+			WHERE c.tasting_notes IS NOT NULL
+			UNION ALL
+			SELECT NULL::uuid, t.name
+			FROM coffee_tags ct JOIN tags t ON t.id = ct.tag_id
+			WHERE ct.coffee_id = c.id
+			UNION ALL
+			SELECT b.id, t.name
+			FROM brews b
+			JOIN brew_tags bt ON bt.brew_id = b.id
+			JOIN tags t ON t.id = bt.tag_id
+			WHERE b.coffee_id = c.id
+		 ) n
+		 WHERE c.user_id = $1
+		 ORDER BY c.created_at DESC, c.id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []Note
+	for rows.Next() {
+		var n Note
+		if err := rows.Scan(&n.CoffeeID, &n.Coffee, &n.Roaster, &n.Country, &n.BrewID, &n.Text); err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
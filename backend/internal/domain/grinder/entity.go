@@ -0,0 +1,85 @@
+package grinder
+
+import (
+	"time"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// Grinder is a user's grinder together with the setting scale it accepts.
+// ScaleStep is nil for stepless grinders. MicronMin and MicronMax optionally
+// map the ends of the scale to an approximate particle size.
+type Grinder struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"-"`
+	Name      string     `json:"name"`
+	Brand     *string    `json:"brand"`
+	Notes     *string    `json:"notes"`
+	ScaleMin  float64    `json:"scale_min"`
+	ScaleMax  float64    `json:"scale_max"`
+	ScaleStep *float64   `json:"scale_step"`
+	Stepless  bool       `json:"stepless"`
+	MicronMin *int       `json:"micron_min"`
+	MicronMax *int       `json:"micron_max"`
+	DeletedAt *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Scale returns the grinder's setting scale.
+func (g *Grinder) Scale() validate.GrindScale {
+	return validate.GrindScale{
+		Min:       g.ScaleMin,
+		Max:       g.ScaleMax,
+		Step:      g.ScaleStep,
+		Stepless:  g.Stepless,
+		MicronMin: g.MicronMin,
+		MicronMax: g.MicronMax,
+	}
+}
+
+type CreateRequest struct {
+	Name      string   `json:"name"`
+	Brand     *string  `json:"brand"`
+	Notes     *string  `json:"notes"`
+	ScaleMin  float64  `json:"scale_min"`
+	ScaleMax  float64  `json:"scale_max"`
+	ScaleStep *float64 `json:"scale_step"`
+	Stepless  bool     `json:"stepless"`
+	MicronMin *int     `json:"micron_min"`
+	MicronMax *int     `json:"micron_max"`
+}
+
+type UpdateRequest struct {
+	Name      string   `json:"name"`
+	Brand     *string  `json:"brand"`
+	Notes     *string  `json:"notes"`
+	ScaleMin  float64  `json:"scale_min"`
+	ScaleMax  float64  `json:"scale_max"`
+	ScaleStep *float64 `json:"scale_step"`
+	Stepless  bool     `json:"stepless"`
+	MicronMin *int     `json:"micron_min"`
+	MicronMax *int     `json:"micron_max"`
+}
+
+// CalibrationPoint ties a setting on the grinder either to a measured
+// particle size or to the setting on another grinder that produced an
+// equivalent brew.
+type CalibrationPoint struct {
+	ID              string    `json:"id"`
+	GrinderID       string    `json:"grinder_id"`
+	Setting         float64   `json:"setting"`
+	Microns         *int      `json:"microns"`
+	PairedGrinderID *string   `json:"paired_grinder_id"`
+	PairedSetting   *float64  `json:"paired_setting"`
+	Notes           *string   `json:"notes"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type CalibrationRequest struct {
+	Setting         *float64 `json:"setting"`
+	Microns         *int     `json:"microns"`
+	PairedGrinderID *string  `json:"paired_grinder_id"`
+	PairedSetting   *float64 `json:"paired_setting"`
+	Notes           *string  `json:"notes"`
+}
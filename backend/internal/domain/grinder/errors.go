@@ -0,0 +1,15 @@
+package grinder
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound              = domainerr.NotFound("Grinder not found")
+	ErrConflict              = domainerr.Conflict("A grinder with this name already exists")
+	ErrCalibrationNotFound   = domainerr.NotFound("Calibration point not found")
+	ErrPairedGrinderNotFound = domainerr.InvalidReference("paired_grinder_id", "Grinder not found")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"idx_grinders_user_name": ErrConflict,
+}
@@ -0,0 +1,286 @@
+package grinder
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	pagination := api.ParsePagination(r)
+	sort := r.URL.Query().Get("sort")
+
+	grinders, total, err := h.repo.List(r.Context(), userID, pagination.Page, pagination.PerPage, sort)
+	if err != nil {
+		log.Printf("error listing grinders: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, api.PaginatedResponse{
+		Items: grinders,
+		Pagination: api.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      total,
+			TotalPages: api.TotalPages(total, pagination.PerPage),
+		},
+	})
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	g, err := h.repo.GetByID(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error getting grinder: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if g == nil {
+		api.NotFoundError(w, "Grinder not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, g)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req CreateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	g, err := h.repo.Create(r.Context(), userID, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error creating grinder: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, g)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req UpdateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(CreateRequest(req)); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	g, err := h.repo.Update(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error updating grinder: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if g == nil {
+		api.NotFoundError(w, "Grinder not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, g)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	err := h.repo.SoftDelete(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error deleting grinder: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListCalibration(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	points, err := h.repo.ListCalibration(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error listing calibration points: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]interface{}{"items": points})
+}
+
+func (h *Handler) AddCalibration(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req CalibrationRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	if fieldErrors := validateCalibration(id, req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	p, err := h.repo.AddCalibration(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error adding calibration point: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, p)
+}
+
+func (h *Handler) DeleteCalibration(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+	pointID := chi.URLParam(r, "pointId")
+
+	err := h.repo.DeleteCalibration(r.Context(), userID, id, pointID)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error deleting calibration point: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Translate converts a setting on this grinder to the equivalent setting on
+// the grinder given by the "to" query parameter.
+func (h *Handler) Translate(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+	q := r.URL.Query()
+
+	var errs validate.Errors
+	// ParseFloat accepts NaN and Inf, which can't be translated or encoded
+	// as JSON.
+	setting, err := strconv.ParseFloat(q.Get("setting"), 64)
+	if err != nil || math.IsNaN(setting) || math.IsInf(setting, 0) {
+		errs.Add("setting", "must be a number")
+	}
+	to := q.Get("to")
+	if to == "" {
+		errs.Add("to", "Target grinder is required")
+	}
+	if len(errs) > 0 {
+		api.ValidationError(w, errs)
+		return
+	}
+
+	t, err := h.repo.Translate(r.Context(), userID, id, to, setting)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error translating grind setting: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, t)
+}
+
+// validateRequest checks the name and that the scale is usable. The step is
+// ignored for stepless grinders.
+func validateRequest(req CreateRequest) []api.FieldError {
+	var errs validate.Errors
+	if req.Name == "" {
+		errs.Add("name", "Name is required")
+	}
+	errs.GrindScale(validate.GrindScale{
+		Min:       req.ScaleMin,
+		Max:       req.ScaleMax,
+		Step:      req.ScaleStep,
+		Stepless:  req.Stepless,
+		MicronMin: req.MicronMin,
+		MicronMax: req.MicronMax,
+	})
+	return errs
+}
+
+// validateCalibration checks that a calibration point carries either a
+// micron measurement or a paired setting on another grinder, but not both.
+// Settings are checked against the grinders' scales by the repository.
+func validateCalibration(grinderID string, req CalibrationRequest) []api.FieldError {
+	var errs validate.Errors
+	if req.Setting == nil {
+		errs.Add("setting", "Setting is required")
+	}
+
+	paired := req.PairedGrinderID != nil || req.PairedSetting != nil
+	switch {
+	case req.Microns == nil && !paired:
+		errs.Add("microns", "Provide microns or a paired grinder setting")
+	case req.Microns != nil && paired:
+		errs.Add("microns", "Provide microns or a paired grinder setting, not both")
+	case req.Microns != nil && *req.Microns <= 0:
+		errs.Add("microns", "must be a positive number")
+	case paired && req.PairedGrinderID == nil:
+		errs.Add("paired_grinder_id", "Paired grinder is required with paired_setting")
+	case paired && req.PairedSetting == nil:
+		errs.Add("paired_setting", "Paired setting is required with paired_grinder_id")
+	case paired && *req.PairedGrinderID == grinderID:
+		errs.Add("paired_grinder_id", "Cannot pair a grinder with itself")
+	}
+	return errs
+}
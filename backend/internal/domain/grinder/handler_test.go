@@ -0,0 +1,830 @@
+package grinder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/grind"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockRepo struct {
+	grinders    map[string]*Grinder
+	calibration []CalibrationPoint
+	nextID      int
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{grinders: make(map[string]*Grinder), nextID: 1}
+}
+
+func (m *mockRepo) List(_ context.Context, userID string, page, perPage int, sort string) ([]Grinder, int, error) {
+	var result []Grinder
+	for _, g := range m.grinders {
+		if g.UserID == userID && g.DeletedAt == nil {
+			result = append(result, *g)
+		}
+	}
+	total := len(result)
+
+	offset := (page - 1) * perPage
+	if offset >= len(result) {
+		return []Grinder{}, total, nil
+	}
+	end := offset + perPage
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*Grinder, error) {
+	g := m.grinders[id]
+	if g == nil || g.UserID != userID || g.DeletedAt != nil {
+		return nil, nil
+	}
+	return g, nil
+}
+
+func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (*Grinder, error) {
+	// Check for duplicate name
+	for _, g := range m.grinders {
+		if g.UserID == userID && g.Name == req.Name && g.DeletedAt == nil {
+			return nil, ErrConflict
+		}
+	}
+
+	m.nextID++
+	id := fmt.Sprintf("g-%d", m.nextID)
+	now := time.Now()
+	g := &Grinder{
+		ID:        id,
+		UserID:    userID,
+		Name:      req.Name,
+		Brand:     req.Brand,
+		Notes:     req.Notes,
+		ScaleMin:  req.ScaleMin,
+		ScaleMax:  req.ScaleMax,
+		ScaleStep: req.ScaleStep,
+		Stepless:  req.Stepless,
+		MicronMin: req.MicronMin,
+		MicronMax: req.MicronMax,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.grinders[id] = g
+	return g, nil
+}
+
+func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateRequest) (*Grinder, error) {
+	g := m.grinders[id]
+	if g == nil || g.UserID != userID || g.DeletedAt != nil {
+		return nil, nil
+	}
+
+	// Check for duplicate name (excluding self)
+	for _, other := range m.grinders {
+		if other.UserID == userID && other.Name == req.Name && other.ID != id && other.DeletedAt == nil {
+			return nil, ErrConflict
+		}
+	}
+
+	g.Name = req.Name
+	g.Brand = req.Brand
+	g.Notes = req.Notes
+	g.ScaleMin = req.ScaleMin
+	g.ScaleMax = req.ScaleMax
+	g.ScaleStep = req.ScaleStep
+	g.Stepless = req.Stepless
+	g.MicronMin = req.MicronMin
+	g.MicronMax = req.MicronMax
+	g.UpdatedAt = time.Now()
+	return g, nil
+}
+
+func (m *mockRepo) SoftDelete(_ context.Context, userID, id string) error {
+	g := m.grinders[id]
+	if g == nil || g.UserID != userID || g.DeletedAt != nil {
+		return ErrNotFound
+	}
+	now := time.Now()
+	g.DeletedAt = &now
+	return nil
+}
+
+func (m *mockRepo) owned(userID, id string) *Grinder {
+	g := m.grinders[id]
+	if g == nil || g.UserID != userID || g.DeletedAt != nil {
+		return nil
+	}
+	return g
+}
+
+func (m *mockRepo) ListCalibration(_ context.Context, userID, grinderID string) ([]CalibrationPoint, error) {
+	if m.owned(userID, grinderID) == nil {
+		return nil, ErrNotFound
+	}
+	points := []CalibrationPoint{}
+	for _, p := range m.calibration {
+		if p.GrinderID == grinderID {
+			points = append(points, p)
+		}
+	}
+	return points, nil
+}
+
+func (m *mockRepo) AddCalibration(_ context.Context, userID, grinderID string, req CalibrationRequest) (*CalibrationPoint, error) {
+	g := m.owned(userID, grinderID)
+	if g == nil {
+		return nil, ErrNotFound
+	}
+	if err := validate.GrindSizeError("setting", req.Setting, g.Scale()); err != nil {
+		return nil, err
+	}
+	if req.PairedGrinderID != nil {
+		paired := m.owned(userID, *req.PairedGrinderID)
+		if paired == nil {
+			return nil, ErrPairedGrinderNotFound
+		}
+		if err := validate.GrindSizeError("paired_setting", req.PairedSetting, paired.Scale()); err != nil {
+			return nil, err
+		}
+	}
+
+	m.nextID++
+	p := CalibrationPoint{
+		ID:              fmt.Sprintf("cal-%d", m.nextID),
+		GrinderID:       grinderID,
+		Setting:         *req.Setting,
+		Microns:         req.Microns,
+		PairedGrinderID: req.PairedGrinderID,
+		PairedSetting:   req.PairedSetting,
+		Notes:           req.Notes,
+		CreatedAt:       time.Now(),
+	}
+	m.calibration = append(m.calibration, p)
+	return &p, nil
+}
+
+func (m *mockRepo) DeleteCalibration(_ context.Context, userID, grinderID, pointID string) error {
+	if m.owned(userID, grinderID) == nil {
+		return ErrCalibrationNotFound
+	}
+	for i, p := range m.calibration {
+		if p.ID == pointID && p.GrinderID == grinderID {
+			m.calibration = append(m.calibration[:i], m.calibration[i+1:]...)
+			return nil
+		}
+	}
+	return ErrCalibrationNotFound
+}
+
+func (m *mockRepo) Translate(_ context.Context, userID, fromID, toID string, setting float64) (*grind.Translation, error) {
+	from := m.owned(userID, fromID)
+	if from == nil {
+		return nil, grind.ErrGrinderNotFound
+	}
+	if err := validate.GrindSizeError("setting", &setting, from.Scale()); err != nil {
+		return nil, err
+	}
+	to := m.owned(userID, toID)
+	if to == nil {
+		return nil, grind.ErrTargetNotFound
+	}
+
+	load := func(g *Grinder) grind.Grinder {
+		out := grind.Grinder{ID: g.ID, Scale: g.Scale()}
+		for _, p := range m.calibration {
+			if p.GrinderID == g.ID && p.Microns != nil {
+				out.MicronPoints = append(out.MicronPoints, grind.Point{X: p.Setting, Y: float64(*p.Microns)})
+			}
+		}
+		return out
+	}
+	var pairs []grind.Pair
+	for _, p := range m.calibration {
+		if p.PairedGrinderID != nil {
+			pairs = append(pairs, grind.Pair{
+				FromGrinderID: p.GrinderID, FromSetting: p.Setting,
+				ToGrinderID: *p.PairedGrinderID, ToSetting: *p.PairedSetting,
+			})
+		}
+	}
+	return grind.Translate(load(from), load(to), pairs, setting)
+}
+
+// Error-returning mock
+
+type errorRepo struct{}
+
+func (e *errorRepo) List(_ context.Context, _ string, _, _ int, _ string) ([]Grinder, int, error) {
+	return nil, 0, errors.New("database error")
+}
+func (e *errorRepo) GetByID(_ context.Context, _, _ string) (*Grinder, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Create(_ context.Context, _ string, _ CreateRequest) (*Grinder, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Update(_ context.Context, _, _ string, _ UpdateRequest) (*Grinder, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) SoftDelete(_ context.Context, _, _ string) error {
+	return errors.New("database error")
+}
+func (e *errorRepo) ListCalibration(_ context.Context, _, _ string) ([]CalibrationPoint, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) AddCalibration(_ context.Context, _, _ string, _ CalibrationRequest) (*CalibrationPoint, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) DeleteCalibration(_ context.Context, _, _, _ string) error {
+	return errors.New("database error")
+}
+func (e *errorRepo) Translate(_ context.Context, _, _, _ string, _ float64) (*grind.Translation, error) {
+	return nil, errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/grinders", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Get("/{id}", h.GetByID)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+		r.Get("/{id}/calibration", h.ListCalibration)
+		r.Post("/{id}/calibration", h.AddCalibration)
+		r.Delete("/{id}/calibration/{pointId}", h.DeleteCalibration)
+		r.Get("/{id}/translate", h.Translate)
+	})
+	return r
+}
+
+func authRequest(method, url string, body string) *http.Request {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, url, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func seedGrinder(repo *mockRepo, id, userID, name string, brand *string) *Grinder {
+	now := time.Now()
+	g := &Grinder{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		Brand:     brand,
+		ScaleMin:  1,
+		ScaleMax:  11,
+		ScaleStep: floatPtr(0.5),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	repo.grinders[id] = g
+	return g
+}
+
+func strPtr(s string) *string     { return &s }
+func floatPtr(f float64) *float64 { return &f }
+
+// --- List Tests ---
+
+func TestList_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", strPtr("Fellow"))
+	seedGrinder(repo, "g-2", "user-123", "Comandante C40", strPtr("Comandante"))
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items      []Grinder          `json:"items"`
+		Pagination api.PaginationMeta `json:"pagination"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 2 {
+		t.Errorf("expected 2 grinders, got %d", len(resp.Items))
+	}
+	if resp.Pagination.Total != 2 {
+		t.Errorf("expected total 2, got %d", resp.Pagination.Total)
+	}
+}
+
+func TestList_ExcludesSoftDeleted(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	deleted := seedGrinder(repo, "g-2", "user-123", "Old Grinder", nil)
+	now := time.Now()
+	deleted.DeletedAt = &now
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Items []Grinder `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].Name != "Ode 2" {
+		t.Errorf("expected only Ode 2, got %v", resp.Items)
+	}
+}
+
+func TestList_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+// --- GetByID Tests ---
+
+func TestGetByID_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", strPtr("Fellow"))
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders/g-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var g Grinder
+	json.Unmarshal(w.Body.Bytes(), &g)
+	if g.Name != "Ode 2" || g.ScaleMin != 1 || g.ScaleMax != 11 {
+		t.Errorf("unexpected grinder: %+v", g)
+	}
+	if g.ScaleStep == nil || *g.ScaleStep != 0.5 {
+		t.Errorf("expected scale_step 0.5, got %v", g.ScaleStep)
+	}
+}
+
+func TestGetByID_OtherUserGrinder(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "other-user", "Ode 2", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders/g-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Create Tests ---
+
+func TestCreate_Stepped(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":" Ode 2 ","brand":"Fellow","scale_min":1,"scale_max":11,"scale_step":0.333,"micron_min":250,"micron_max":1550}`
+	req := authRequest(http.MethodPost, "/api/v1/grinders", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var g Grinder
+	json.Unmarshal(w.Body.Bytes(), &g)
+	if g.Name != "Ode 2" {
+		t.Errorf("expected trimmed name, got %q", g.Name)
+	}
+	if g.Stepless {
+		t.Error("expected stepped grinder")
+	}
+	if g.MicronMin == nil || *g.MicronMin != 250 || g.MicronMax == nil || *g.MicronMax != 1550 {
+		t.Errorf("expected micron mapping 250-1550, got %v-%v", g.MicronMin, g.MicronMax)
+	}
+}
+
+func TestCreate_Stepless(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":"Niche Zero","scale_min":0,"scale_max":50,"stepless":true}`
+	req := authRequest(http.MethodPost, "/api/v1/grinders", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var g Grinder
+	json.Unmarshal(w.Body.Bytes(), &g)
+	if !g.Stepless || g.ScaleStep != nil {
+		t.Errorf("expected stepless grinder without step, got %+v", g)
+	}
+}
+
+func TestCreate_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{"empty name", `{"name":"","scale_min":1,"scale_max":11,"scale_step":1}`, "name"},
+		{"missing step", `{"name":"Ode 2","scale_min":1,"scale_max":11}`, "scale_step"},
+		{"inverted range", `{"name":"Ode 2","scale_min":11,"scale_max":1,"scale_step":1}`, "scale_max"},
+		{"half micron mapping", `{"name":"Ode 2","scale_min":1,"scale_max":11,"scale_step":1,"micron_max":1200}`, "micron_min"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(newMockRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/grinders", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp api.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.wantField {
+				t.Errorf("expected %s error, got %v", tt.wantField, resp.Error.Details)
+			}
+		})
+	}
+}
+
+func TestCreate_DuplicateName(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":"Ode 2","scale_min":1,"scale_max":11,"scale_step":1}`
+	req := authRequest(http.MethodPost, "/api/v1/grinders", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCreate_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	body := `{"name":"Ode 2","scale_min":1,"scale_max":11,"scale_step":1}`
+	req := authRequest(http.MethodPost, "/api/v1/grinders", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+// --- Update Tests ---
+
+func TestUpdate_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":"Ode 2 (SSP burrs)","scale_min":1,"scale_max":11,"scale_step":0.333}`
+	req := authRequest(http.MethodPut, "/api/v1/grinders/g-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var g Grinder
+	json.Unmarshal(w.Body.Bytes(), &g)
+	if g.Name != "Ode 2 (SSP burrs)" || g.ScaleStep == nil || *g.ScaleStep != 0.333 {
+		t.Errorf("unexpected grinder: %+v", g)
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	body := `{"name":"Ode 2","scale_min":1,"scale_max":11,"scale_step":1}`
+	req := authRequest(http.MethodPut, "/api/v1/grinders/missing", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestUpdate_InvalidScale(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":"Ode 2","scale_min":1,"scale_max":11,"scale_step":-1}`
+	req := authRequest(http.MethodPut, "/api/v1/grinders/g-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+}
+
+// --- Delete Tests ---
+
+func TestDelete_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/grinders/g-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if repo.grinders["g-1"].DeletedAt == nil {
+		t.Error("expected grinder to be soft-deleted")
+	}
+}
+
+func TestDelete_AlreadyDeleted(t *testing.T) {
+	repo := newMockRepo()
+	g := seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	now := time.Now()
+	g.DeletedAt = &now
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/grinders/g-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Calibration Tests ---
+
+func TestAddCalibration_Microns(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodPost, "/api/v1/grinders/g-1/calibration", `{"setting":4.5,"microns":620}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = authRequest(http.MethodGet, "/api/v1/grinders/g-1/calibration", "")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Items []CalibrationPoint `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].Microns == nil || *resp.Items[0].Microns != 620 {
+		t.Errorf("expected one 620µm point, got %+v", resp.Items)
+	}
+}
+
+func TestAddCalibration_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{"missing setting", `{"microns":600}`, "setting"},
+		{"no calibration value", `{"setting":4}`, "microns"},
+		{"both kinds", `{"setting":4,"microns":600,"paired_grinder_id":"g-2","paired_setting":10}`, "microns"},
+		{"half pair", `{"setting":4,"paired_grinder_id":"g-2"}`, "paired_setting"},
+		{"self pair", `{"setting":4,"paired_grinder_id":"g-1","paired_setting":4}`, "paired_grinder_id"},
+		{"off scale", `{"setting":4.2,"microns":600}`, "setting"},
+		{"paired off scale", `{"setting":4,"paired_grinder_id":"g-2","paired_setting":99}`, "paired_setting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+			seedGrinder(repo, "g-2", "user-123", "Comandante", nil)
+			router := setupRouter(NewHandler(repo))
+
+			req := authRequest(http.MethodPost, "/api/v1/grinders/g-1/calibration", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp api.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.wantField {
+				t.Errorf("expected %s error, got %v", tt.wantField, resp.Error.Details)
+			}
+		})
+	}
+}
+
+func TestAddCalibration_OtherUserPairedGrinder(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	seedGrinder(repo, "g-2", "other-user", "Comandante", nil)
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodPost, "/api/v1/grinders/g-1/calibration", `{"setting":4,"paired_grinder_id":"g-2","paired_setting":5}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDeleteCalibration_NotFound(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodDelete, "/api/v1/grinders/g-1/calibration/cal-99", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Translate Tests ---
+
+func TestTranslate_PairedSettings(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	to := seedGrinder(repo, "g-2", "user-123", "Comandante", nil)
+	to.ScaleMin, to.ScaleMax, to.ScaleStep = 0, 40, floatPtr(1)
+	repo.calibration = []CalibrationPoint{
+		{ID: "cal-1", GrinderID: "g-1", Setting: 4, PairedGrinderID: strPtr("g-2"), PairedSetting: floatPtr(16)},
+		{ID: "cal-2", GrinderID: "g-2", Setting: 24, PairedGrinderID: strPtr("g-1"), PairedSetting: floatPtr(6)},
+	}
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders/g-1/translate?setting=5.5&to=g-2", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var tr grind.Translation
+	json.Unmarshal(w.Body.Bytes(), &tr)
+	if tr.Method != grind.MethodPaired || tr.Setting != 22 || tr.DialSetting != 22 {
+		t.Errorf("unexpected translation: %+v", tr)
+	}
+}
+
+func TestTranslate_NotCalibrated(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	seedGrinder(repo, "g-2", "user-123", "Comandante", nil)
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders/g-1/translate?setting=5&to=g-2", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTranslate_InvalidParams(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders/g-1/translate?setting=fine", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", w.Code)
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Error.Details) != 2 {
+		t.Errorf("expected setting and to errors, got %v", resp.Error.Details)
+	}
+}
+
+func TestTranslate_NonFiniteSetting(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	router := setupRouter(NewHandler(repo))
+
+	for _, setting := range []string{"NaN", "Inf", "-Inf", "+infinity"} {
+		req := authRequest(http.MethodGet, "/api/v1/grinders/g-1/translate?to=g-2&setting="+setting, "")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d: %s", setting, w.Code, w.Body.String())
+		}
+		var resp api.ErrorResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "setting" {
+			t.Errorf("%s: expected a setting error, got %v", setting, resp.Error.Details)
+		}
+	}
+}
+
+func TestTranslate_UnknownTarget(t *testing.T) {
+	repo := newMockRepo()
+	seedGrinder(repo, "g-1", "user-123", "Ode 2", nil)
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodGet, "/api/v1/grinders/g-1/translate?setting=5&to=g-9", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+}
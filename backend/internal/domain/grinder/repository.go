@@ -0,0 +1,19 @@
+package grinder
+
+import (
+	"context"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/grind"
+)
+
+type Repository interface {
+	List(ctx context.Context, userID string, page, perPage int, sort string) ([]Grinder, int, error)
+	GetByID(ctx context.Context, userID, id string) (*Grinder, error)
+	Create(ctx context.Context, userID string, req CreateRequest) (*Grinder, error)
+	Update(ctx context.Context, userID, id string, req UpdateRequest) (*Grinder, error)
+	SoftDelete(ctx context.Context, userID, id string) error
+	ListCalibration(ctx context.Context, userID, grinderID string) ([]CalibrationPoint, error)
+	AddCalibration(ctx context.Context, userID, grinderID string, req CalibrationRequest) (*CalibrationPoint, error)
+	DeleteCalibration(ctx context.Context, userID, grinderID, pointID string) error
+	Translate(ctx context.Context, userID, fromID, toID string, setting float64) (*grind.Translation, error)
+}
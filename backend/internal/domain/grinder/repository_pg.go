@@ -0,0 +1,301 @@
+package grinder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/grind"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+const grinderColumns = `id, user_id, name, brand, notes,
+	scale_min, scale_max, scale_step, stepless, micron_min, micron_max,
+	created_at, updated_at`
+
+var allowedSortFields = map[string]string{
+	"name":       "name",
+	"brand":      "brand",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func parseSortParam(sort string) string {
+	if sort == "" {
+		sort = "-created_at"
+	}
+
+	desc := false
+	field := sort
+	if strings.HasPrefix(sort, "-") {
+		desc = true
+		field = sort[1:]
+	}
+
+	col, ok := allowedSortFields[field]
+	if !ok {
+		col = "created_at"
+		desc = true
+	}
+
+	if desc {
+		return col + " DESC"
+	}
+	return col + " ASC"
+}
+
+func scanGrinder(row pgx.Row) (*Grinder, error) {
+	var g Grinder
+	err := row.Scan(
+		&g.ID, &g.UserID, &g.Name, &g.Brand, &g.Notes,
+		&g.ScaleMin, &g.ScaleMax, &g.ScaleStep, &g.Stepless, &g.MicronMin, &g.MicronMax,
+		&g.CreatedAt, &g.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
+
+func (r *PgRepository) List(ctx context.Context, userID string, page, perPage int, sort string) ([]Grinder, int, error) {
+	orderBy := parseSortParam(sort)
+	offset := (page - 1) * perPage
+
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM grinders WHERE user_id = $1 AND deleted_at IS NULL`,
+		userID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s
+		 FROM grinders
+		 WHERE user_id = $1 AND deleted_at IS NULL
+		 ORDER BY %s
+		 LIMIT $2 OFFSET $3`,
+		grinderColumns, orderBy,
+	)
+
+	rows, err := r.pool.Query(ctx, query, userID, perPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var grinders []Grinder
+	for rows.Next() {
+		g, err := scanGrinder(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		grinders = append(grinders, *g)
+	}
+
+	if grinders == nil {
+		grinders = []Grinder{}
+	}
+
+	return grinders, total, nil
+}
+
+func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*Grinder, error) {
+	g, err := scanGrinder(r.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT %s FROM grinders WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, grinderColumns),
+		id, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequest) (*Grinder, error) {
+	g, err := scanGrinder(r.pool.QueryRow(ctx,
+		fmt.Sprintf(
+			`INSERT INTO grinders (user_id, name, brand, notes,
+				scale_min, scale_max, scale_step, stepless, micron_min, micron_max)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			 RETURNING %s`,
+			grinderColumns,
+		),
+		userID, req.Name, req.Brand, req.Notes,
+		req.ScaleMin, req.ScaleMax, req.ScaleStep, req.Stepless, req.MicronMin, req.MicronMax,
+	))
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+	return g, nil
+}
+
+func (r *PgRepository) Update(ctx context.Context, userID, id string, req UpdateRequest) (*Grinder, error) {
+	g, err := scanGrinder(r.pool.QueryRow(ctx,
+		fmt.Sprintf(
+			`UPDATE grinders
+			 SET name = $1, brand = $2, notes = $3,
+				scale_min = $4, scale_max = $5, scale_step = $6, stepless = $7,
+				micron_min = $8, micron_max = $9, updated_at = NOW()
+			 WHERE id = $10 AND user_id = $11 AND deleted_at IS NULL
+			 RETURNING %s`,
+			grinderColumns,
+		),
+		req.Name, req.Brand, req.Notes,
+		req.ScaleMin, req.ScaleMax, req.ScaleStep, req.Stepless, req.MicronMin, req.MicronMax,
+		id, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+	return g, nil
+}
+
+func (r *PgRepository) SoftDelete(ctx context.Context, userID, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE grinders SET deleted_at = NOW(), updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const calibrationColumns = `id, grinder_id, setting, microns, paired_grinder_id, paired_setting, notes, created_at`
+
+func scanCalibrationPoint(row pgx.Row) (*CalibrationPoint, error) {
+	var p CalibrationPoint
+	err := row.Scan(
+		&p.ID, &p.GrinderID, &p.Setting, &p.Microns, &p.PairedGrinderID, &p.PairedSetting, &p.Notes, &p.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PgRepository) ListCalibration(ctx context.Context, userID, grinderID string) ([]CalibrationPoint, error) {
+	g, err := grind.Load(ctx, r.pool, userID, grinderID)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrNotFound
+	}
+
+	rows, err := r.pool.Query(ctx,
+		fmt.Sprintf(`SELECT %s FROM grinder_calibration_points WHERE grinder_id = $1 ORDER BY setting, created_at`, calibrationColumns),
+		grinderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []CalibrationPoint{}
+	for rows.Next() {
+		p, err := scanCalibrationPoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, *p)
+	}
+	return points, rows.Err()
+}
+
+// AddCalibration checks the setting against the grinder's scale, and a paired
+// setting against the paired grinder's scale, before saving the point.
+func (r *PgRepository) AddCalibration(ctx context.Context, userID, grinderID string, req CalibrationRequest) (*CalibrationPoint, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	g, err := grind.Load(ctx, tx, userID, grinderID)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrNotFound
+	}
+	if err := validate.GrindSizeError("setting", req.Setting, g.Scale); err != nil {
+		return nil, err
+	}
+
+	if req.PairedGrinderID != nil {
+		paired, err := grind.Load(ctx, tx, userID, *req.PairedGrinderID)
+		if err != nil {
+			return nil, err
+		}
+		if paired == nil {
+			return nil, ErrPairedGrinderNotFound
+		}
+		if err := validate.GrindSizeError("paired_setting", req.PairedSetting, paired.Scale); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := scanCalibrationPoint(tx.QueryRow(ctx,
+		fmt.Sprintf(
+			`INSERT INTO grinder_calibration_points (grinder_id, setting, microns, paired_grinder_id, paired_setting, notes)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 RETURNING %s`,
+			calibrationColumns,
+		),
+		grinderID, req.Setting, req.Microns, req.PairedGrinderID, req.PairedSetting, req.Notes,
+	))
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *PgRepository) DeleteCalibration(ctx context.Context, userID, grinderID, pointID string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM grinder_calibration_points p
+		 USING grinders g
+		 WHERE p.id::text = $1 AND p.grinder_id::text = $2
+		   AND g.id = p.grinder_id AND g.user_id = $3 AND g.deleted_at IS NULL`,
+		pointID, grinderID, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrCalibrationNotFound
+	}
+	return nil
+}
+
+func (r *PgRepository) Translate(ctx context.Context, userID, fromID, toID string, setting float64) (*grind.Translation, error) {
+	return grind.TranslateStored(ctx, r.pool, userID, fromID, toID, setting)
+}
@@ -0,0 +1,72 @@
+package recipe
+
+import (
+	"time"
+)
+
+// Recipe is a named, reusable brew template.
+type Recipe struct {
+	ID               string       `json:"id"`
+	UserID           string       `json:"-"`
+	Name             string       `json:"name"`
+	CoffeeWeight     *float64     `json:"coffee_weight"`
+	Ratio            *float64     `json:"ratio"`
+	GrindSize        *float64     `json:"grind_size"`
+	WaterTemperature *float64     `json:"water_temperature"`
+	FilterPaper      *FilterPaper `json:"filter_paper"`
+	Dripper          *Dripper     `json:"dripper"`
+	Pours            []Pour       `json:"pours"`
+	Notes            *string      `json:"notes"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+}
+
+type FilterPaper struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Brand *string `json:"brand"`
+}
+
+type Dripper struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Brand *string `json:"brand"`
+}
+
+type Pour struct {
+	PourNumber  int      `json:"pour_number"`
+	WaterAmount *float64 `json:"water_amount"`
+	PourStyle   *string  `json:"pour_style"`
+	WaitTime    *int     `json:"wait_time"`
+}
+
+type CreateRequest struct {
+	Name             string        `json:"name"`
+	CoffeeWeight     *float64      `json:"coffee_weight"`
+	Ratio            *float64      `json:"ratio"`
+	GrindSize        *float64      `json:"grind_size"`
+	WaterTemperature *float64      `json:"water_temperature"`
+	FilterPaperID    *string       `json:"filter_paper_id"`
+	DripperID        *string       `json:"dripper_id"`
+	Pours            []PourRequest `json:"pours"`
+	Notes            *string       `json:"notes"`
+}
+
+type UpdateRequest struct {
+	Name             string        `json:"name"`
+	CoffeeWeight     *float64      `json:"coffee_weight"`
+	Ratio            *float64      `json:"ratio"`
+	GrindSize        *float64      `json:"grind_size"`
+	WaterTemperature *float64      `json:"water_temperature"`
+	FilterPaperID    *string       `json:"filter_paper_id"`
+	DripperID        *string       `json:"dripper_id"`
+	Pours            []PourRequest `json:"pours"`
+	Notes            *string       `json:"notes"`
+}
+
+type PourRequest struct {
+	PourNumber  int      `json:"pour_number"`
+	WaterAmount *float64 `json:"water_amount"`
+	PourStyle   *string  `json:"pour_style"`
+	WaitTime    *int     `json:"wait_time"`
+}
@@ -0,0 +1,16 @@
+package recipe
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound = domainerr.NotFound("Recipe not found")
+	ErrConflict = domainerr.Conflict("A recipe with this name already exists")
+
+	ErrFilterPaperNotFound = domainerr.InvalidReference("filter_paper_id", "Filter paper not found")
+	ErrDripperNotFound     = domainerr.InvalidReference("dripper_id", "Dripper not found")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"idx_recipes_user_name": ErrConflict,
+}
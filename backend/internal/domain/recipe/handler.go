@@ -0,0 +1,158 @@
+package recipe
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	pagination := api.ParsePagination(r)
+	sort := r.URL.Query().Get("sort")
+
+	recipes, total, err := h.repo.List(r.Context(), userID, pagination.Page, pagination.PerPage, sort)
+	if err != nil {
+		log.Printf("error listing recipes: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, api.PaginatedResponse{
+		Items: recipes,
+		Pagination: api.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      total,
+			TotalPages: api.TotalPages(total, pagination.PerPage),
+		},
+	})
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	rec, err := h.repo.GetByID(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error getting recipe: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if rec == nil {
+		api.NotFoundError(w, "Recipe not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, rec)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req CreateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRecipe(req.Name, req.CoffeeWeight, req.Ratio, req.WaterTemperature, req.Pours); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	rec, err := h.repo.Create(r.Context(), userID, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error creating recipe: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, rec)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req UpdateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRecipe(req.Name, req.CoffeeWeight, req.Ratio, req.WaterTemperature, req.Pours); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	rec, err := h.repo.Update(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error updating recipe: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if rec == nil {
+		api.NotFoundError(w, "Recipe not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, rec)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	err := h.repo.Delete(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error deleting recipe: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateRecipe checks the recipe name and applies the same setup and pour
+// rules as brews.
+func validateRecipe(name string, coffeeWeight, ratio, waterTemperature *float64, pours []PourRequest) []api.FieldError {
+	var errs validate.Errors
+	if name == "" {
+		errs.Add("name", "Name is required")
+	}
+	errs.Setup(coffeeWeight, ratio, waterTemperature)
+
+	vp := make([]validate.Pour, len(pours))
+	for i, p := range pours {
+		vp[i] = validate.Pour(p)
+	}
+	errs.Pours("pours", vp, coffeeWeight, ratio)
+
+	return errs
+}
@@ -0,0 +1,596 @@
+package recipe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockRepo struct {
+	recipes map[string]*Recipe
+	nextID  int
+	// unownedEquipment holds filter paper and dripper IDs that are
+	// soft-deleted or owned by another user.
+	unownedEquipment map[string]bool
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		recipes:          make(map[string]*Recipe),
+		nextID:           1,
+		unownedEquipment: make(map[string]bool),
+	}
+}
+
+func (m *mockRepo) verifyEquipment(filterPaperID, dripperID *string) error {
+	if filterPaperID != nil && m.unownedEquipment[*filterPaperID] {
+		return ErrFilterPaperNotFound
+	}
+	if dripperID != nil && m.unownedEquipment[*dripperID] {
+		return ErrDripperNotFound
+	}
+	return nil
+}
+
+func (m *mockRepo) List(_ context.Context, userID string, page, perPage int, _ string) ([]Recipe, int, error) {
+	var result []Recipe
+	for _, rec := range m.recipes {
+		if rec.UserID == userID {
+			result = append(result, *rec)
+		}
+	}
+	total := len(result)
+
+	offset := (page - 1) * perPage
+	if offset >= len(result) {
+		return []Recipe{}, total, nil
+	}
+	end := offset + perPage
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*Recipe, error) {
+	rec := m.recipes[id]
+	if rec == nil || rec.UserID != userID {
+		return nil, nil
+	}
+	return rec, nil
+}
+
+func (m *mockRepo) hasName(userID, name, excludeID string) bool {
+	for _, rec := range m.recipes {
+		if rec.UserID == userID && rec.Name == name && rec.ID != excludeID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (*Recipe, error) {
+	if err := m.verifyEquipment(req.FilterPaperID, req.DripperID); err != nil {
+		return nil, err
+	}
+	if m.hasName(userID, req.Name, "") {
+		return nil, ErrConflict
+	}
+
+	m.nextID++
+	now := time.Now()
+	rec := &Recipe{
+		ID:        fmt.Sprintf("r-%d", m.nextID),
+		UserID:    userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyRequest(rec, UpdateRequest(req))
+	m.recipes[rec.ID] = rec
+	return rec, nil
+}
+
+func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateRequest) (*Recipe, error) {
+	rec := m.recipes[id]
+	if rec == nil || rec.UserID != userID {
+		return nil, nil
+	}
+	if err := m.verifyEquipment(req.FilterPaperID, req.DripperID); err != nil {
+		return nil, err
+	}
+	if m.hasName(userID, req.Name, id) {
+		return nil, ErrConflict
+	}
+	applyRequest(rec, req)
+	rec.UpdatedAt = time.Now()
+	return rec, nil
+}
+
+func (m *mockRepo) Delete(_ context.Context, userID, id string) error {
+	rec := m.recipes[id]
+	if rec == nil || rec.UserID != userID {
+		return ErrNotFound
+	}
+	delete(m.recipes, id)
+	return nil
+}
+
+func applyRequest(rec *Recipe, req UpdateRequest) {
+	rec.Name = req.Name
+	rec.CoffeeWeight = req.CoffeeWeight
+	rec.Ratio = req.Ratio
+	rec.GrindSize = req.GrindSize
+	rec.WaterTemperature = req.WaterTemperature
+	rec.Notes = req.Notes
+	rec.FilterPaper = nil
+	if req.FilterPaperID != nil {
+		rec.FilterPaper = &FilterPaper{ID: *req.FilterPaperID, Name: "Test Filter"}
+	}
+	rec.Dripper = nil
+	if req.DripperID != nil {
+		rec.Dripper = &Dripper{ID: *req.DripperID, Name: "Test Dripper"}
+	}
+	rec.Pours = []Pour{}
+	for _, p := range req.Pours {
+		rec.Pours = append(rec.Pours, Pour(p))
+	}
+}
+
+// Error-returning mock
+
+type errorRepo struct{}
+
+func (e *errorRepo) List(_ context.Context, _ string, _, _ int, _ string) ([]Recipe, int, error) {
+	return nil, 0, errors.New("database error")
+}
+func (e *errorRepo) GetByID(_ context.Context, _, _ string) (*Recipe, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Create(_ context.Context, _ string, _ CreateRequest) (*Recipe, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Update(_ context.Context, _, _ string, _ UpdateRequest) (*Recipe, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Delete(_ context.Context, _, _ string) error {
+	return errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/recipes", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Get("/{id}", h.GetByID)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
+	return r
+}
+
+func authRequest(method, url string, body string) *http.Request {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, url, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func seedRecipe(repo *mockRepo, id, userID, name string) *Recipe {
+	now := time.Now()
+	rec := &Recipe{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		Pours:     []Pour{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	repo.recipes[id] = rec
+	return rec
+}
+
+// --- List Tests ---
+
+func TestList_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-123", "House V60")
+	seedRecipe(repo, "r-2", "user-123", "Light roast")
+	seedRecipe(repo, "r-3", "user-456", "Theirs")
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodGet, "/api/v1/recipes", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.PaginatedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	items := resp.Items.([]interface{})
+	if len(items) != 2 {
+		t.Errorf("expected 2 items (own user only), got %d", len(items))
+	}
+	if resp.Pagination.Total != 2 {
+		t.Errorf("expected total 2, got %d", resp.Pagination.Total)
+	}
+}
+
+func TestList_DatabaseError(t *testing.T) {
+	router := setupRouter(NewHandler(&errorRepo{}))
+
+	req := authRequest(http.MethodGet, "/api/v1/recipes", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+}
+
+func TestList_Unauthenticated(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo()))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/recipes", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d", w.Code)
+	}
+}
+
+// --- GetByID Tests ---
+
+func TestGetByID_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-123", "House V60")
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodGet, "/api/v1/recipes/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var rec Recipe
+	json.Unmarshal(w.Body.Bytes(), &rec)
+	if rec.Name != "House V60" {
+		t.Errorf("expected name House V60, got %s", rec.Name)
+	}
+}
+
+func TestGetByID_OtherUserRecipe(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-456", "Theirs")
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodGet, "/api/v1/recipes/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 for other user's recipe, got %d", w.Code)
+	}
+}
+
+// --- Create Tests ---
+
+func TestCreate_Success(t *testing.T) {
+	repo := newMockRepo()
+	router := setupRouter(NewHandler(repo))
+
+	body := `{
+		"name": "House V60",
+		"coffee_weight": 15,
+		"ratio": 16,
+		"grind_size": 3.5,
+		"water_temperature": 93,
+		"filter_paper_id": "fp-1",
+		"dripper_id": "d-1",
+		"pours": [
+			{"pour_number": 1, "water_amount": 45, "pour_style": "center", "wait_time": 30},
+			{"pour_number": 2, "water_amount": 195, "pour_style": "circular"}
+		]
+	}`
+	req := authRequest(http.MethodPost, "/api/v1/recipes", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var rec Recipe
+	json.Unmarshal(w.Body.Bytes(), &rec)
+	if rec.ID == "" {
+		t.Error("expected non-empty ID")
+	}
+	if rec.Ratio == nil || *rec.Ratio != 16 {
+		t.Errorf("expected ratio 16, got %v", rec.Ratio)
+	}
+	if rec.FilterPaper == nil || rec.FilterPaper.ID != "fp-1" {
+		t.Errorf("expected filter paper fp-1, got %v", rec.FilterPaper)
+	}
+	if rec.Dripper == nil || rec.Dripper.ID != "d-1" {
+		t.Errorf("expected dripper d-1, got %v", rec.Dripper)
+	}
+	if len(rec.Pours) != 2 {
+		t.Fatalf("expected 2 pours, got %d", len(rec.Pours))
+	}
+	if rec.Pours[1].WaterAmount == nil || *rec.Pours[1].WaterAmount != 195 {
+		t.Errorf("expected pour #2 water_amount 195, got %v", rec.Pours[1].WaterAmount)
+	}
+}
+
+func TestCreate_EmptyName(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo()))
+
+	req := authRequest(http.MethodPost, "/api/v1/recipes", `{"name":"   "}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreate_NonSequentialPours(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo()))
+
+	body := `{"name":"Bad","pours":[{"pour_number":1},{"pour_number":3}]}`
+	req := authRequest(http.MethodPost, "/api/v1/recipes", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreate_InvalidPourStyle(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo()))
+
+	body := `{"name":"Bad","pours":[{"pour_number":1,"pour_style":"spiral"}]}`
+	req := authRequest(http.MethodPost, "/api/v1/recipes", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCreate_DuplicateName(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-123", "House V60")
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodPost, "/api/v1/recipes", `{"name":"House V60"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", w.Code)
+	}
+}
+
+func TestCreate_DatabaseError(t *testing.T) {
+	router := setupRouter(NewHandler(&errorRepo{}))
+
+	req := authRequest(http.MethodPost, "/api/v1/recipes", `{"name":"House V60"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+}
+
+// --- Update Tests ---
+
+func TestUpdate_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-123", "House V60")
+	router := setupRouter(NewHandler(repo))
+
+	body := `{"name":"House V60 (light)","coffee_weight":16}`
+	req := authRequest(http.MethodPut, "/api/v1/recipes/r-1", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var rec Recipe
+	json.Unmarshal(w.Body.Bytes(), &rec)
+	if rec.Name != "House V60 (light)" {
+		t.Errorf("expected updated name, got %s", rec.Name)
+	}
+	if rec.CoffeeWeight == nil || *rec.CoffeeWeight != 16 {
+		t.Errorf("expected coffee_weight 16, got %v", rec.CoffeeWeight)
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo()))
+
+	req := authRequest(http.MethodPut, "/api/v1/recipes/missing", `{"name":"X"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestUpdate_DuplicateName(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-123", "House V60")
+	seedRecipe(repo, "r-2", "user-123", "Light roast")
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodPut, "/api/v1/recipes/r-2", `{"name":"House V60"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d", w.Code)
+	}
+}
+
+func TestCreate_UnownedEquipment(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"other user's filter paper", `{"name":"V60","filter_paper_id":"fp-other"}`, "filter_paper_id"},
+		{"deleted dripper", `{"name":"V60","dripper_id":"d-deleted"}`, "dripper_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			repo.unownedEquipment["fp-other"] = true
+			repo.unownedEquipment["d-deleted"] = true
+			router := setupRouter(NewHandler(repo))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, authRequest(http.MethodPost, "/api/v1/recipes", tt.body))
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp api.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Error.Code != api.CodeInvalidReference || len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.field {
+				t.Errorf("expected INVALID_REFERENCE on %s, got %+v", tt.field, resp.Error)
+			}
+			if len(repo.recipes) != 0 {
+				t.Errorf("expected no recipe to be created, got %d", len(repo.recipes))
+			}
+		})
+	}
+}
+
+func TestUpdate_UnownedEquipment(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-123", "House V60")
+	repo.unownedEquipment["fp-other"] = true
+	router := setupRouter(NewHandler(repo))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodPut, "/api/v1/recipes/r-1", `{"name":"House V60","filter_paper_id":"fp-other"}`))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.recipes["r-1"].FilterPaper != nil {
+		t.Error("expected the recipe's filter paper to stay unset")
+	}
+}
+
+// --- Delete Tests ---
+
+func TestDelete_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-123", "House V60")
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodDelete, "/api/v1/recipes/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", w.Code)
+	}
+	if _, ok := repo.recipes["r-1"]; ok {
+		t.Error("expected recipe to be deleted")
+	}
+}
+
+func TestDelete_OtherUserRecipe(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipe(repo, "r-1", "user-456", "Theirs")
+	router := setupRouter(NewHandler(repo))
+
+	req := authRequest(http.MethodDelete, "/api/v1/recipes/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestDelete_DatabaseError(t *testing.T) {
+	router := setupRouter(NewHandler(&errorRepo{}))
+
+	req := authRequest(http.MethodDelete, "/api/v1/recipes/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected status 500, got %d", w.Code)
+	}
+}
+
+func TestCreate_ResponseExcludesUserID(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo()))
+
+	req := authRequest(http.MethodPost, "/api/v1/recipes", `{"name":"House V60","coffee_weight":15}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var raw map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &raw)
+	if _, ok := raw["user_id"]; ok {
+		t.Error("response should not include user_id")
+	}
+	if raw["coffee_weight"] != float64(15) {
+		t.Errorf("expected coffee_weight 15, got %v", raw["coffee_weight"])
+	}
+}
@@ -0,0 +1,13 @@
+package recipe
+
+import (
+	"context"
+)
+
+type Repository interface {
+	List(ctx context.Context, userID string, page, perPage int, sort string) ([]Recipe, int, error)
+	GetByID(ctx context.Context, userID, id string) (*Recipe, error)
+	Create(ctx context.Context, userID string, req CreateRequest) (*Recipe, error)
+	Update(ctx context.Context, userID, id string, req UpdateRequest) (*Recipe, error)
+	Delete(ctx context.Context, userID, id string) error
+}
@@ -0,0 +1,322 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+const recipeColumns = `r.id, r.user_id, r.name,
+	r.coffee_weight, r.ratio, r.grind_size, r.water_temperature,
+	r.notes, r.created_at, r.updated_at,
+	fp.id AS fp_id, fp.name AS fp_name, fp.brand AS fp_brand,
+	d.id AS d_id, d.name AS d_name, d.brand AS d_brand`
+
+const recipeSelectBase = `SELECT %s
+	FROM recipes r
+	LEFT JOIN filter_papers fp ON fp.id = r.filter_paper_id
+	LEFT JOIN drippers d ON d.id = r.dripper_id`
+
+var allowedSortFields = map[string]string{
+	"name":       "r.name",
+	"created_at": "r.created_at",
+	"updated_at": "r.updated_at",
+}
+
+func parseSortParam(sort string) string {
+	if sort == "" {
+		sort = "-created_at"
+	}
+
+	desc := false
+	field := sort
+	if strings.HasPrefix(sort, "-") {
+		desc = true
+		field = sort[1:]
+	}
+
+	col, ok := allowedSortFields[field]
+	if !ok {
+		col = "r.created_at"
+		desc = true
+	}
+
+	if desc {
+		return col + " DESC"
+	}
+	return col + " ASC"
+}
+
+func scanRecipe(row pgx.Row) (*Recipe, error) {
+	var rec Recipe
+	var fpID, fpName, fpBrand *string
+	var dID, dName, dBrand *string
+	err := row.Scan(
+		&rec.ID, &rec.UserID, &rec.Name,
+		&rec.CoffeeWeight, &rec.Ratio, &rec.GrindSize, &rec.WaterTemperature,
+		&rec.Notes, &rec.CreatedAt, &rec.UpdatedAt,
+		&fpID, &fpName, &fpBrand,
+		&dID, &dName, &dBrand,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if fpID != nil {
+		rec.FilterPaper = &FilterPaper{ID: *fpID, Name: derefStr(fpName), Brand: fpBrand}
+	}
+	if dID != nil {
+		rec.Dripper = &Dripper{ID: *dID, Name: derefStr(dName), Brand: dBrand}
+	}
+
+	return &rec, nil
+}
+
+func derefStr(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func (r *PgRepository) loadPoursForRecipes(ctx context.Context, recipeIDs []string) (map[string][]Pour, error) {
+	result := make(map[string][]Pour)
+	if len(recipeIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT recipe_id, pour_number, water_amount, pour_style, wait_time
+		 FROM recipe_pours WHERE recipe_id = ANY($1) ORDER BY recipe_id, pour_number`,
+		recipeIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var recipeID string
+		var p Pour
+		if err := rows.Scan(&recipeID, &p.PourNumber, &p.WaterAmount, &p.PourStyle, &p.WaitTime); err != nil {
+			return nil, err
+		}
+		result[recipeID] = append(result[recipeID], p)
+	}
+	return result, rows.Err()
+}
+
+func savePours(ctx context.Context, tx pgx.Tx, recipeID string, pours []PourRequest) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM recipe_pours WHERE recipe_id = $1`, recipeID); err != nil {
+		return err
+	}
+
+	for _, p := range pours {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO recipe_pours (recipe_id, pour_number, water_amount, pour_style, wait_time)
+			 VALUES ($1, $2, $3, $4, $5)`,
+			recipeID, p.PourNumber, p.WaterAmount, p.PourStyle, p.WaitTime,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PgRepository) List(ctx context.Context, userID string, page, perPage int, sort string) ([]Recipe, int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM recipes WHERE user_id = $1`,
+		userID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		`%s WHERE r.user_id = $1 ORDER BY %s LIMIT $2 OFFSET $3`,
+		fmt.Sprintf(recipeSelectBase, recipeColumns),
+		parseSortParam(sort),
+	)
+
+	rows, err := r.pool.Query(ctx, query, userID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var recipes []Recipe
+	var recipeIDs []string
+	for rows.Next() {
+		rec, err := scanRecipe(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		recipeIDs = append(recipeIDs, rec.ID)
+		recipes = append(recipes, *rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	poursMap, err := r.loadPoursForRecipes(ctx, recipeIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range recipes {
+		recipes[i].Pours = poursMap[recipes[i].ID]
+		if recipes[i].Pours == nil {
+			recipes[i].Pours = []Pour{}
+		}
+	}
+
+	if recipes == nil {
+		recipes = []Recipe{}
+	}
+
+	return recipes, total, nil
+}
+
+func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*Recipe, error) {
+	query := fmt.Sprintf(
+		`%s WHERE r.id = $1 AND r.user_id = $2`,
+		fmt.Sprintf(recipeSelectBase, recipeColumns),
+	)
+
+	rec, err := scanRecipe(r.pool.QueryRow(ctx, query, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	poursMap, err := r.loadPoursForRecipes(ctx, []string{rec.ID})
+	if err != nil {
+		return nil, err
+	}
+	rec.Pours = poursMap[rec.ID]
+	if rec.Pours == nil {
+		rec.Pours = []Pour{}
+	}
+
+	return rec, nil
+}
+
+// verifyEquipment checks that the referenced filter paper and dripper belong
+// to the user and have not been soft-deleted.
+func verifyEquipment(ctx context.Context, tx pgx.Tx, userID string, filterPaperID, dripperID *string) error {
+	if filterPaperID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "filter_papers", userID, *filterPaperID, ErrFilterPaperNotFound); err != nil {
+			return err
+		}
+	}
+	if dripperID != nil {
+		if err := domainerr.CheckOwned(ctx, tx, "drippers", userID, *dripperID, ErrDripperNotFound); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequest) (*Recipe, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := verifyEquipment(ctx, tx, userID, req.FilterPaperID, req.DripperID); err != nil {
+		return nil, err
+	}
+
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO recipes (user_id, name, coffee_weight, ratio, grind_size, water_temperature,
+			filter_paper_id, dripper_id, notes)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING id`,
+		userID, req.Name, req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature,
+		req.FilterPaperID, req.DripperID, req.Notes,
+	).Scan(&id)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+
+	if err := savePours(ctx, tx, id, req.Pours); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+func (r *PgRepository) Update(ctx context.Context, userID, id string, req UpdateRequest) (*Recipe, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`UPDATE recipes
+		 SET name = $1, coffee_weight = $2, ratio = $3, grind_size = $4, water_temperature = $5,
+			filter_paper_id = $6, dripper_id = $7, notes = $8, updated_at = NOW()
+		 WHERE id = $9 AND user_id = $10`,
+		req.Name, req.CoffeeWeight, req.Ratio, req.GrindSize, req.WaterTemperature,
+		req.FilterPaperID, req.DripperID, req.Notes,
+		id, userID,
+	)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	if err := verifyEquipment(ctx, tx, userID, req.FilterPaperID, req.DripperID); err != nil {
+		return nil, err
+	}
+
+	if err := savePours(ctx, tx, id, req.Pours); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+func (r *PgRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM recipes WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package roaster
+
+import (
+	"strings"
+	"time"
+)
+
+// Roaster is a coffee roaster. Coffees reference a roaster instead of carrying
+// the name as free text, so spellings can't drift apart.
+type Roaster struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"-"`
+	Name        string    `json:"name"`
+	Country     *string   `json:"country"`
+	Website     *string   `json:"website"`
+	Notes       *string   `json:"notes"`
+	CoffeeCount int       `json:"coffee_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CreateRequest struct {
+	Name    string  `json:"name"`
+	Country *string `json:"country"`
+	Website *string `json:"website"`
+	Notes   *string `json:"notes"`
+}
+
+type UpdateRequest struct {
+	Name    string  `json:"name"`
+	Country *string `json:"country"`
+	Website *string `json:"website"`
+	Notes   *string `json:"notes"`
+}
+
+// MergeRequest names the roaster that absorbs the merged one's coffees.
+type MergeRequest struct {
+	IntoID string `json:"into_id"`
+}
+
+// Stats aggregates every coffee and brew from the roasters matching a name.
+type Stats struct {
+	Roaster     string         `json:"roaster"`
+	RoasterIDs  []string       `json:"roaster_ids"`
+	CoffeeCount int            `json:"coffee_count"`
+	BrewCount   int            `json:"brew_count"`
+	MeanScore   *float64       `json:"mean_score"`
+	MedianScore *float64       `json:"median_score"`
+	BestScore   *int           `json:"best_score"`
+	Intensities AvgIntensities `json:"avg_intensities"`
+	Processes   []ValueCount   `json:"top_processes"`
+	Origins     []ValueCount   `json:"top_origins"`
+
+	// AvgDaysOffRoastAtPeak averages, over coffees, the days off roast of
+	// each coffee's highest-scoring brew.
+	AvgDaysOffRoastAtPeak *float64 `json:"avg_days_off_roast_at_peak"`
+}
+
+// AvgIntensities holds the mean of each sensory intensity across brews.
+type AvgIntensities struct {
+	Aroma      *float64 `json:"aroma_intensity"`
+	Body       *float64 `json:"body_intensity"`
+	Sweetness  *float64 `json:"sweetness_intensity"`
+	Brightness *float64 `json:"brightness_intensity"`
+	Complexity *float64 `json:"complexity_intensity"`
+	Aftertaste *float64 `json:"aftertaste_intensity"`
+}
+
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// TopValuesLimit caps the processes and origins returned in Stats.
+const TopValuesLimit = 5
+
+// normalizeName lowercases a roaster name and collapses runs of whitespace,
+// so "Onyx  Coffee Lab " and "onyx coffee lab" key the same stats.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
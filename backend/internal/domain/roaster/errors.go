@@ -0,0 +1,18 @@
+package roaster
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound            = domainerr.NotFound("Roaster not found")
+	ErrConflict            = domainerr.Conflict("A roaster with this name already exists")
+	ErrHasCoffees          = domainerr.Conflict("Roaster still has coffees; merge it into another roaster instead")
+	ErrMergeTargetNotFound = domainerr.InvalidReference("into_id", "Roaster not found")
+	ErrHasShareLinks       = domainerr.Conflict("Roaster is used by a share link filter")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"idx_roasters_user_name":             ErrConflict,
+	"coffees_roaster_id_fkey":            ErrHasCoffees,
+	"share_links_filter_roaster_id_fkey": ErrHasShareLinks,
+}
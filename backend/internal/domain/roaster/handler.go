@@ -0,0 +1,193 @@
+package roaster
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	pagination := api.ParsePagination(r)
+	sort := r.URL.Query().Get("sort")
+
+	roasters, total, err := h.repo.List(r.Context(), userID, pagination.Page, pagination.PerPage, sort)
+	if err != nil {
+		log.Printf("error listing roasters: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, api.PaginatedResponse{
+		Items: roasters,
+		Pagination: api.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      total,
+			TotalPages: api.TotalPages(total, pagination.PerPage),
+		},
+	})
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	ro, err := h.repo.GetByID(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error getting roaster: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if ro == nil {
+		api.NotFoundError(w, "Roaster not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, ro)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req CreateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	ro, err := h.repo.Create(r.Context(), userID, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error creating roaster: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, ro)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req UpdateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(CreateRequest(req)); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	ro, err := h.repo.Update(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error updating roaster: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if ro == nil {
+		api.NotFoundError(w, "Roaster not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, ro)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	err := h.repo.Delete(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error deleting roaster: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) Merge(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req MergeRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	switch req.IntoID {
+	case "":
+		api.ValidationError(w, []api.FieldError{{Field: "into_id", Message: "Target roaster is required"}})
+		return
+	case id:
+		api.ValidationError(w, []api.FieldError{{Field: "into_id", Message: "Cannot merge a roaster into itself"}})
+		return
+	}
+
+	ro, err := h.repo.Merge(r.Context(), userID, id, req.IntoID)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error merging roasters: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, ro)
+}
+
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	name := normalizeName(chi.URLParam(r, "name"))
+
+	if name == "" {
+		api.ValidationError(w, []api.FieldError{{Field: "name", Message: "Roaster name is required"}})
+		return
+	}
+
+	stats, err := h.repo.Stats(r.Context(), userID, name)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error getting roaster stats: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, stats)
+}
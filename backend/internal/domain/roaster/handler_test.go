@@ -0,0 +1,647 @@
+package roaster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockRepo struct {
+	roasters map[string]*Roaster
+	coffees  map[string]string // coffee ID -> roaster ID
+	stats    map[string]*Stats // normalized name -> stats
+	nextID   int
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		roasters: make(map[string]*Roaster),
+		coffees:  make(map[string]string),
+		stats:    make(map[string]*Stats),
+		nextID:   1,
+	}
+}
+
+func (m *mockRepo) countCoffees(ro *Roaster) {
+	ro.CoffeeCount = 0
+	for _, roasterID := range m.coffees {
+		if roasterID == ro.ID {
+			ro.CoffeeCount++
+		}
+	}
+}
+
+func (m *mockRepo) List(_ context.Context, userID string, page, perPage int, sort string) ([]Roaster, int, error) {
+	var result []Roaster
+	for _, ro := range m.roasters {
+		if ro.UserID == userID {
+			m.countCoffees(ro)
+			result = append(result, *ro)
+		}
+	}
+	total := len(result)
+
+	offset := (page - 1) * perPage
+	if offset >= len(result) {
+		return []Roaster{}, total, nil
+	}
+	end := offset + perPage
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*Roaster, error) {
+	ro := m.roasters[id]
+	if ro == nil || ro.UserID != userID {
+		return nil, nil
+	}
+	m.countCoffees(ro)
+	return ro, nil
+}
+
+func (m *mockRepo) hasName(userID, name, exceptID string) bool {
+	for _, ro := range m.roasters {
+		if ro.UserID == userID && ro.ID != exceptID && strings.EqualFold(ro.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (*Roaster, error) {
+	if m.hasName(userID, req.Name, "") {
+		return nil, ErrConflict
+	}
+
+	m.nextID++
+	id := fmt.Sprintf("roaster-%d", m.nextID)
+	now := time.Now()
+	ro := &Roaster{
+		ID:        id,
+		UserID:    userID,
+		Name:      req.Name,
+		Country:   req.Country,
+		Website:   req.Website,
+		Notes:     req.Notes,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.roasters[id] = ro
+	return ro, nil
+}
+
+func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateRequest) (*Roaster, error) {
+	ro := m.roasters[id]
+	if ro == nil || ro.UserID != userID {
+		return nil, nil
+	}
+	if m.hasName(userID, req.Name, id) {
+		return nil, ErrConflict
+	}
+	ro.Name = req.Name
+	ro.Country = req.Country
+	ro.Website = req.Website
+	ro.Notes = req.Notes
+	ro.UpdatedAt = time.Now()
+	m.countCoffees(ro)
+	return ro, nil
+}
+
+func (m *mockRepo) Delete(_ context.Context, userID, id string) error {
+	ro := m.roasters[id]
+	if ro == nil || ro.UserID != userID {
+		return ErrNotFound
+	}
+	for _, roasterID := range m.coffees {
+		if roasterID == id {
+			return ErrHasCoffees
+		}
+	}
+	delete(m.roasters, id)
+	return nil
+}
+
+func (m *mockRepo) Merge(_ context.Context, userID, id, intoID string) (*Roaster, error) {
+	from := m.roasters[id]
+	if from == nil || from.UserID != userID {
+		return nil, ErrNotFound
+	}
+	into := m.roasters[intoID]
+	if into == nil || into.UserID != userID {
+		return nil, ErrMergeTargetNotFound
+	}
+	for coffeeID, roasterID := range m.coffees {
+		if roasterID == id {
+			m.coffees[coffeeID] = intoID
+		}
+	}
+	if into.Country == nil {
+		into.Country = from.Country
+	}
+	if into.Website == nil {
+		into.Website = from.Website
+	}
+	if into.Notes == nil {
+		into.Notes = from.Notes
+	}
+	delete(m.roasters, id)
+	m.countCoffees(into)
+	return into, nil
+}
+
+func (m *mockRepo) Stats(_ context.Context, _ string, name string) (*Stats, error) {
+	stats := m.stats[name]
+	if stats == nil {
+		return nil, ErrNotFound
+	}
+	return stats, nil
+}
+
+// Error-returning mock
+
+type errorRepo struct{}
+
+func (e *errorRepo) List(_ context.Context, _ string, _, _ int, _ string) ([]Roaster, int, error) {
+	return nil, 0, errors.New("database error")
+}
+func (e *errorRepo) GetByID(_ context.Context, _, _ string) (*Roaster, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Create(_ context.Context, _ string, _ CreateRequest) (*Roaster, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Update(_ context.Context, _, _ string, _ UpdateRequest) (*Roaster, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Delete(_ context.Context, _, _ string) error {
+	return errors.New("database error")
+}
+func (e *errorRepo) Merge(_ context.Context, _, _, _ string) (*Roaster, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Stats(_ context.Context, _, _ string) (*Stats, error) {
+	return nil, errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/roasters", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Get("/{id}", h.GetByID)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+		r.Post("/{id}/merge", h.Merge)
+		r.Get("/{name}/stats", h.Stats)
+	})
+	return r
+}
+
+func authRequest(method, url string, body string) *http.Request {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, url, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func seedRoaster(repo *mockRepo, id, userID, name string) *Roaster {
+	now := time.Now()
+	ro := &Roaster{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	repo.roasters[id] = ro
+	return ro
+}
+
+func strPtr(s string) *string     { return &s }
+func floatPtr(f float64) *float64 { return &f }
+func intPtr(i int) *int           { return &i }
+
+// --- List Tests ---
+
+func TestList_ExcludesOtherUsers(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx Coffee Lab")
+	seedRoaster(repo, "r-2", "other-user", "Theirs")
+	repo.coffees["c-1"] = "r-1"
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/roasters", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items      []Roaster          `json:"items"`
+		Pagination api.PaginationMeta `json:"pagination"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].Name != "Onyx Coffee Lab" {
+		t.Fatalf("expected only Onyx Coffee Lab, got %v", resp.Items)
+	}
+	if resp.Items[0].CoffeeCount != 1 {
+		t.Errorf("expected coffee_count 1, got %d", resp.Items[0].CoffeeCount)
+	}
+}
+
+func TestList_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/roasters", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+// --- GetByID Tests ---
+
+func TestGetByID_OtherUserRoaster(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "other-user", "Theirs")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/roasters/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Create Tests ---
+
+func TestCreate_Success(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":"  Onyx Coffee Lab ","country":"USA","website":"https://onyxcoffeelab.com","notes":"Arkansas"}`
+	req := authRequest(http.MethodPost, "/api/v1/roasters", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Roaster
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Name != "Onyx Coffee Lab" {
+		t.Errorf("expected trimmed name, got %q", resp.Name)
+	}
+	if resp.Website == nil || *resp.Website != "https://onyxcoffeelab.com" {
+		t.Errorf("expected website, got %v", resp.Website)
+	}
+}
+
+func TestCreate_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"missing name", `{"name":"   "}`, "name"},
+		{"website without scheme", `{"name":"Onyx","website":"onyxcoffeelab.com"}`, "website"},
+		{"website with other scheme", `{"name":"Onyx","website":"ftp://onyxcoffeelab.com"}`, "website"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(newMockRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/roasters", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"field":"`+tt.field+`"`) {
+				t.Errorf("expected error on %s, got %s", tt.field, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreate_DuplicateNameIgnoresCase(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx Coffee Lab")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/roasters", `{"name":"onyx coffee lab"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// --- Update Tests ---
+
+func TestUpdate_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/roasters/r-1", `{"name":"Onyx Coffee Lab","country":"USA"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.roasters["r-1"].Name != "Onyx Coffee Lab" {
+		t.Errorf("expected renamed roaster, got %q", repo.roasters["r-1"].Name)
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/roasters/missing", `{"name":"Onyx"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Delete Tests ---
+
+func TestDelete_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/roasters/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.roasters["r-1"] != nil {
+		t.Error("expected roaster to be deleted")
+	}
+}
+
+func TestDelete_HasCoffees(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx")
+	repo.coffees["c-1"] = "r-1"
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/roasters/r-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// --- Merge Tests ---
+
+func TestMerge_RepointsCoffees(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx")
+	from := seedRoaster(repo, "r-2", "user-123", "Onyx Coffee Lab")
+	from.Website = strPtr("https://onyxcoffeelab.com")
+	repo.coffees["c-1"] = "r-1"
+	repo.coffees["c-2"] = "r-2"
+	repo.coffees["c-3"] = "r-2"
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/roasters/r-2/merge", `{"into_id":"r-1"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Roaster
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.ID != "r-1" || resp.CoffeeCount != 3 {
+		t.Errorf("expected r-1 with 3 coffees, got %s with %d", resp.ID, resp.CoffeeCount)
+	}
+	if resp.Website == nil || *resp.Website != "https://onyxcoffeelab.com" {
+		t.Errorf("expected website carried over, got %v", resp.Website)
+	}
+	if repo.roasters["r-2"] != nil {
+		t.Error("expected merged roaster to be deleted")
+	}
+}
+
+func TestMerge_Validation(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing target", `{}`},
+		{"into itself", `{"into_id":"r-1"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepo()
+			seedRoaster(repo, "r-1", "user-123", "Onyx")
+			h := NewHandler(repo)
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/roasters/r-1/merge", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMerge_TargetNotFound(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx")
+	seedRoaster(repo, "r-2", "other-user", "Theirs")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/roasters/r-1/merge", `{"into_id":"r-2"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.roasters["r-1"] == nil {
+		t.Error("expected source roaster to survive a failed merge")
+	}
+}
+
+func TestMerge_SourceNotFound(t *testing.T) {
+	repo := newMockRepo()
+	seedRoaster(repo, "r-1", "user-123", "Onyx")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/roasters/missing/merge", `{"into_id":"r-1"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Stats Tests ---
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		"Onyx Coffee Lab":        "onyx coffee lab",
+		"  onyx   COFFEE\tlab  ": "onyx coffee lab",
+		"":                       "",
+	}
+	for in, want := range tests {
+		if got := normalizeName(in); got != want {
+			t.Errorf("normalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestStats_MatchesNormalizedName(t *testing.T) {
+	repo := newMockRepo()
+	repo.stats["onyx coffee lab"] = &Stats{
+		Roaster:     "Onyx Coffee Lab",
+		RoasterIDs:  []string{"r-1"},
+		CoffeeCount: 3,
+		BrewCount:   12,
+		MeanScore:   floatPtr(7.25),
+		MedianScore: floatPtr(7.5),
+		BestScore:   intPtr(9),
+		Processes:   []ValueCount{{Value: "Washed", Count: 2}},
+		Origins:     []ValueCount{{Value: "Ethiopia", Count: 2}},
+	}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/roasters/%20ONYX%20%20Coffee%20Lab/stats", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp Stats
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.CoffeeCount != 3 || resp.BrewCount != 12 {
+		t.Errorf("expected 3 coffees and 12 brews, got %d and %d", resp.CoffeeCount, resp.BrewCount)
+	}
+	if resp.BestScore == nil || *resp.BestScore != 9 {
+		t.Errorf("expected best score 9, got %v", resp.BestScore)
+	}
+	if len(resp.Processes) != 1 || resp.Processes[0].Value != "Washed" {
+		t.Errorf("expected Washed as top process, got %v", resp.Processes)
+	}
+}
+
+func TestStats_NotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/roasters/Unknown/stats", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestStats_BlankName(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/roasters/%20%20/stats", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestStats_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/roasters/Onyx/stats", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
@@ -0,0 +1,21 @@
+package roaster
+
+import (
+	"context"
+)
+
+type Repository interface {
+	List(ctx context.Context, userID string, page, perPage int, sort string) ([]Roaster, int, error)
+	GetByID(ctx context.Context, userID, id string) (*Roaster, error)
+	Create(ctx context.Context, userID string, req CreateRequest) (*Roaster, error)
+	Update(ctx context.Context, userID, id string, req UpdateRequest) (*Roaster, error)
+	Delete(ctx context.Context, userID, id string) error
+
+	// Merge moves every coffee from roaster id to roaster intoID, deletes
+	// roaster id, and returns the surviving roaster.
+	Merge(ctx context.Context, userID, id, intoID string) (*Roaster, error)
+
+	// Stats aggregates the coffees and brews of every roaster whose
+	// normalized name equals name, which must already be normalized.
+	Stats(ctx context.Context, userID, name string) (*Stats, error)
+}
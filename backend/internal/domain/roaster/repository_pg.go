@@ -0,0 +1,356 @@
+package roaster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+const roasterColumns = `r.id, r.user_id, r.name, r.country, r.website, r.notes,
+	(SELECT COUNT(*) FROM coffees WHERE coffees.roaster_id = r.id) AS coffee_count,
+	r.created_at, r.updated_at`
+
+var allowedSortFields = map[string]string{
+	"name":       "r.name",
+	"created_at": "r.created_at",
+	"updated_at": "r.updated_at",
+}
+
+func parseSortParam(sort string) string {
+	if sort == "" {
+		sort = "name"
+	}
+
+	desc := false
+	field := sort
+	if strings.HasPrefix(sort, "-") {
+		desc = true
+		field = sort[1:]
+	}
+
+	col, ok := allowedSortFields[field]
+	if !ok {
+		col = "r.name"
+		desc = false
+	}
+
+	if desc {
+		return col + " DESC"
+	}
+	return col + " ASC"
+}
+
+func scanRoaster(row pgx.Row) (*Roaster, error) {
+	var ro Roaster
+	err := row.Scan(
+		&ro.ID, &ro.UserID, &ro.Name, &ro.Country, &ro.Website, &ro.Notes,
+		&ro.CoffeeCount, &ro.CreatedAt, &ro.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ro, nil
+}
+
+func (r *PgRepository) List(ctx context.Context, userID string, page, perPage int, sort string) ([]Roaster, int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM roasters WHERE user_id = $1`,
+		userID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s
+		 FROM roasters r
+		 WHERE r.user_id = $1
+		 ORDER BY %s
+		 LIMIT $2 OFFSET $3`,
+		roasterColumns, parseSortParam(sort),
+	)
+
+	rows, err := r.pool.Query(ctx, query, userID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	roasters := []Roaster{}
+	for rows.Next() {
+		ro, err := scanRoaster(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		roasters = append(roasters, *ro)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return roasters, total, nil
+}
+
+func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*Roaster, error) {
+	ro, err := scanRoaster(r.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT %s FROM roasters r WHERE r.id = $1 AND r.user_id = $2`, roasterColumns),
+		id, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ro, nil
+}
+
+func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequest) (*Roaster, error) {
+	var id string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO roasters (user_id, name, country, website, notes)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id`,
+		userID, req.Name, req.Country, req.Website, req.Notes,
+	).Scan(&id)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+func (r *PgRepository) Update(ctx context.Context, userID, id string, req UpdateRequest) (*Roaster, error) {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE roasters
+		 SET name = $1, country = $2, website = $3, notes = $4, updated_at = NOW()
+		 WHERE id = $5 AND user_id = $6`,
+		req.Name, req.Country, req.Website, req.Notes,
+		id, userID,
+	)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+// Delete only removes roasters without coffees; the foreign key from coffees
+// rejects the rest.
+func (r *PgRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM roasters WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return domainerr.FromPg(err, pgErrors)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Merge also copies the merged roaster's country, website and notes onto the
+// surviving roaster where it has none of its own, and moves share link
+// filters over with the coffees.
+func (r *PgRepository) Merge(ctx context.Context, userID, id, intoID string) (*Roaster, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`SELECT id FROM roasters WHERE id = ANY($1) AND user_id = $2 FOR UPDATE`,
+		[]string{id, intoID}, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool)
+	for rows.Next() {
+		var rid string
+		if err := rows.Scan(&rid); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		found[rid] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if !found[id] {
+		return nil, ErrNotFound
+	}
+	if !found[intoID] {
+		return nil, ErrMergeTargetNotFound
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE coffees SET roaster_id = $1, updated_at = NOW()
+		 WHERE roaster_id = $2 AND user_id = $3`,
+		intoID, id, userID,
+	); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE share_links SET filter_roaster_id = $1, updated_at = NOW()
+		 WHERE filter_roaster_id = $2 AND user_id = $3`,
+		intoID, id, userID,
+	); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE roasters t
+		 SET country = COALESCE(t.country, s.country),
+			website = COALESCE(t.website, s.website),
+			notes = COALESCE(t.notes, s.notes),
+			updated_at = NOW()
+		 FROM roasters s
+		 WHERE t.id = $1 AND s.id = $2`,
+		intoID, id,
+	); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM roasters WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, userID, intoID)
+}
+
+// normalizedNameSQL is normalizeName in SQL.
+const normalizedNameSQL = `LOWER(TRIM(REGEXP_REPLACE(name, '\s+', ' ', 'g')))`
+
+func (r *PgRepository) Stats(ctx context.Context, userID, name string) (*Stats, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, name FROM roasters
+		 WHERE user_id = $1 AND `+normalizedNameSQL+` = $2
+		 ORDER BY created_at`,
+		userID, name,
+	)
+	if err != nil {
+		return nil, err
+	}
+	stats := Stats{RoasterIDs: []string{}}
+	for rows.Next() {
+		var id, roasterName string
+		if err := rows.Scan(&id, &roasterName); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if stats.Roaster == "" {
+			stats.Roaster = roasterName
+		}
+		stats.RoasterIDs = append(stats.RoasterIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(stats.RoasterIDs) == 0 {
+		return nil, ErrNotFound
+	}
+
+	err = r.pool.QueryRow(ctx,
+		`SELECT COUNT(DISTINCT c.id), COUNT(b.id),
+			AVG(b.overall_score),
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY b.overall_score),
+			MAX(b.overall_score),
+			AVG(b.aroma_intensity), AVG(b.body_intensity), AVG(b.sweetness_intensity),
+			AVG(b.brightness_intensity), AVG(b.complexity_intensity), AVG(b.aftertaste_intensity)
+		 FROM coffees c
+		 LEFT JOIN brews b ON b.coffee_id = c.id
+		 WHERE c.user_id = $1 AND c.roaster_id = ANY($2)`,
+		userID, stats.RoasterIDs,
+	).Scan(
+		&stats.CoffeeCount, &stats.BrewCount,
+		&stats.MeanScore, &stats.MedianScore, &stats.BestScore,
+		&stats.Intensities.Aroma, &stats.Intensities.Body, &stats.Intensities.Sweetness,
+		&stats.Intensities.Brightness, &stats.Intensities.Complexity, &stats.Intensities.Aftertaste,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Processes, err = r.topValues(ctx, "process", userID, stats.RoasterIDs); err != nil {
+		return nil, err
+	}
+	if stats.Origins, err = r.topValues(ctx, "country", userID, stats.RoasterIDs); err != nil {
+		return nil, err
+	}
+
+	err = r.pool.QueryRow(ctx,
+		`SELECT AVG(days_off_roast) FROM (
+			SELECT DISTINCT ON (b.coffee_id) b.days_off_roast
+			FROM brews b
+			JOIN coffees c ON c.id = b.coffee_id
+			WHERE c.user_id = $1 AND c.roaster_id = ANY($2)
+			  AND b.overall_score IS NOT NULL AND b.days_off_roast IS NOT NULL
+			ORDER BY b.coffee_id, b.overall_score DESC, b.brew_date
+		) peak`,
+		userID, stats.RoasterIDs,
+	).Scan(&stats.AvgDaysOffRoastAtPeak)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// topValues counts the most common values of a coffees column. col must be a
+// trusted column name.
+func (r *PgRepository) topValues(ctx context.Context, col, userID string, roasterIDs []string) ([]ValueCount, error) {
+	rows, err := r.pool.Query(ctx,
+		fmt.Sprintf(
+			`SELECT %s, COUNT(*) FROM coffees
+			 WHERE user_id = $1 AND roaster_id = ANY($2) AND %s IS NOT NULL
+			 GROUP BY %s
+			 ORDER BY COUNT(*) DESC, %s
+			 LIMIT $3`,
+			col, col, col, col,
+		),
+		userID, roasterIDs, TopValuesLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []ValueCount{}
+	for rows.Next() {
+		var v ValueCount
+		if err := rows.Scan(&v.Value, &v.Count); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,26 @@
+package roaster
+
+import (
+	"net/url"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// validateRequest checks the name and that a website, if given, is an
+// http(s) URL.
+func validateRequest(req CreateRequest) []api.FieldError {
+	var errs validate.Errors
+	if req.Name == "" {
+		errs.Add("name", "Name is required")
+	}
+
+	if req.Website != nil {
+		u, err := url.Parse(*req.Website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs.Add("website", "Website must be an http or https URL")
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,35 @@
+package search
+
+// Result types.
+const (
+	TypeCoffee = "coffee"
+	TypeBrew   = "brew"
+)
+
+var validTypes = map[string]bool{
+	TypeCoffee: true,
+	TypeBrew:   true,
+}
+
+// Params is a search request. Type limits results to one entity type when
+// set.
+type Params struct {
+	Query   string
+	Type    string
+	Page    int
+	PerPage int
+}
+
+// Result is one coffee or brew matching the query. For a coffee, ID and
+// CoffeeID are the same and BrewDate is null. Snippet is HTML-escaped text
+// around the matches, with each match wrapped in <mark>.
+type Result struct {
+	Type     string  `json:"type"`
+	ID       string  `json:"id"`
+	CoffeeID string  `json:"coffee_id"`
+	Coffee   string  `json:"coffee"`
+	Roaster  string  `json:"roaster"`
+	BrewDate *string `json:"brew_date"`
+	Rank     float64 `json:"rank"`
+	Snippet  string  `json:"snippet"`
+}
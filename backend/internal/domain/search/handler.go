@@ -0,0 +1,52 @@
+package search
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	pagination := api.ParsePagination(r)
+	q := r.URL.Query()
+
+	params := Params{
+		Query:   strings.TrimSpace(q.Get("q")),
+		Type:    q.Get("type"),
+		Page:    pagination.Page,
+		PerPage: pagination.PerPage,
+	}
+	if fieldErrors := validateParams(params); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	results, total, err := h.repo.Search(r.Context(), userID, params)
+	if err != nil {
+		log.Printf("error searching: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, api.PaginatedResponse{
+		Items: results,
+		Pagination: api.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      total,
+			TotalPages: api.TotalPages(total, pagination.PerPage),
+		},
+	})
+}
@@ -0,0 +1,275 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockDoc struct {
+	userID string
+	text   string
+	result Result
+}
+
+// mockRepo matches a query as a case-insensitive substring and ranks by the
+// number of occurrences; the real ranking is done by Postgres.
+type mockRepo struct {
+	docs       []mockDoc
+	lastParams Params
+}
+
+func (m *mockRepo) Search(_ context.Context, userID string, params Params) ([]Result, int, error) {
+	m.lastParams = params
+	query := strings.ToLower(params.Query)
+	var hits []Result
+	for _, d := range m.docs {
+		if d.userID != userID {
+			continue
+		}
+		if params.Type != "" && d.result.Type != params.Type {
+			continue
+		}
+		n := strings.Count(strings.ToLower(d.text), query)
+		if n == 0 {
+			continue
+		}
+		r := d.result
+		r.Rank = float64(n)
+		r.Snippet = highlight(strings.ReplaceAll(d.text, params.Query, markStart+params.Query+markStop))
+		hits = append(hits, r)
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Rank > hits[j].Rank })
+
+	total := len(hits)
+	offset := (params.Page - 1) * params.PerPage
+	if offset >= total {
+		return []Result{}, total, nil
+	}
+	end := offset + params.PerPage
+	if end > total {
+		end = total
+	}
+	return hits[offset:end], total, nil
+}
+
+type errorRepo struct{}
+
+func (e *errorRepo) Search(_ context.Context, _ string, _ Params) ([]Result, int, error) {
+	return nil, 0, errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/search", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/", h.Search)
+	})
+	return r
+}
+
+func authRequest(method, url string) *http.Request {
+	req := httptest.NewRequest(method, url, nil)
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func strPtr(s string) *string { return &s }
+
+func seedRepo() *mockRepo {
+	return &mockRepo{docs: []mockDoc{
+		{
+			userID: "user-123",
+			text:   "Kiamaina Cata Coffee Kenya Apricot, lemon, honey",
+			result: Result{Type: TypeCoffee, ID: "c-1", CoffeeID: "c-1", Coffee: "Kiamaina", Roaster: "Cata Coffee"},
+		},
+		{
+			userID: "user-123",
+			text:   "Bright lemon, juicy. More lemon next time?",
+			result: Result{Type: TypeBrew, ID: "b-1", CoffeeID: "c-1", Coffee: "Kiamaina", Roaster: "Cata Coffee", BrewDate: strPtr("2026-01-15")},
+		},
+		{
+			userID: "user-123",
+			text:   "Flat and <b>muddy</b>",
+			result: Result{Type: TypeBrew, ID: "b-2", CoffeeID: "c-1", Coffee: "Kiamaina", Roaster: "Cata Coffee", BrewDate: strPtr("2026-01-16")},
+		},
+		{
+			userID: "other-user",
+			text:   "Lemon bomb",
+			result: Result{Type: TypeCoffee, ID: "c-9", CoffeeID: "c-9", Coffee: "Lemon Bomb", Roaster: "Elsewhere"},
+		},
+	}}
+}
+
+type searchResponse struct {
+	Items      []Result `json:"items"`
+	Pagination struct {
+		Page       int `json:"page"`
+		PerPage    int `json:"per_page"`
+		Total      int `json:"total"`
+		TotalPages int `json:"total_pages"`
+	} `json:"pagination"`
+}
+
+// --- Search Tests ---
+
+func TestSearch_Success(t *testing.T) {
+	repo := seedRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/search?q=lemon")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp searchResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Pagination.Total != 2 || len(resp.Items) != 2 {
+		t.Fatalf("expected 2 results for the user, got %+v", resp)
+	}
+	if resp.Items[0].ID != "b-1" || resp.Items[0].Type != TypeBrew {
+		t.Errorf("expected the brew mentioning lemon twice first, got %+v", resp.Items[0])
+	}
+	if resp.Items[1].Type != TypeCoffee || resp.Items[1].BrewDate != nil {
+		t.Errorf("expected a coffee result without brew_date, got %+v", resp.Items[1])
+	}
+	if !strings.Contains(resp.Items[0].Snippet, "<mark>lemon</mark>") {
+		t.Errorf("expected highlighted snippet, got %q", resp.Items[0].Snippet)
+	}
+}
+
+func TestSearch_Pagination(t *testing.T) {
+	repo := seedRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/search?q=lemon&page=2&per_page=1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp searchResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].ID != "c-1" {
+		t.Errorf("expected c-1 on page 2, got %+v", resp.Items)
+	}
+	if resp.Pagination.Total != 2 || resp.Pagination.TotalPages != 2 {
+		t.Errorf("expected total 2 over 2 pages, got %+v", resp.Pagination)
+	}
+}
+
+func TestSearch_FilterByType(t *testing.T) {
+	repo := seedRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/search?q=lemon&type=coffee")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp searchResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].Type != TypeCoffee {
+		t.Errorf("expected only the coffee, got %+v", resp.Items)
+	}
+	if repo.lastParams.Type != TypeCoffee {
+		t.Errorf("expected type coffee passed to repo, got %q", repo.lastParams.Type)
+	}
+}
+
+func TestSearch_TrimsQuery(t *testing.T) {
+	repo := seedRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/search?q=%20muddy%20")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if repo.lastParams.Query != "muddy" {
+		t.Errorf("expected trimmed query, got %q", repo.lastParams.Query)
+	}
+}
+
+func TestSearch_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		field string
+	}{
+		{"missing query", "/api/v1/search", "q"},
+		{"blank query", "/api/v1/search?q=%20%20", "q"},
+		{"long query", "/api/v1/search?q=" + strings.Repeat("a", MaxQueryLength+1), "q"},
+		{"invalid type", "/api/v1/search?q=lemon&type=recipe", "type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(seedRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodGet, tt.url)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), `"field":"`+tt.field+`"`) {
+				t.Errorf("expected error on %s, got %s", tt.field, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSearch_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/search?q=lemon")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", w.Code)
+	}
+}
+
+func TestHighlight_EscapesText(t *testing.T) {
+	got := highlight("Flat and <b>" + markStart + "muddy" + markStop + "</b> & dull")
+	want := "Flat and &lt;b&gt;<mark>muddy</mark>&lt;/b&gt; &amp; dull"
+	if got != want {
+		t.Errorf("highlight = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,11 @@
+package search
+
+import (
+	"context"
+)
+
+type Repository interface {
+	// Search returns one page of the user's coffees and brews matching the
+	// query, best match first, and the total number of matches.
+	Search(ctx context.Context, userID string, params Params) ([]Result, int, error)
+}
@@ -0,0 +1,125 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// ts_headline marks matches with control characters rather than HTML so the
+// surrounding user text can be escaped before the marks become <mark> tags.
+const (
+	markStart = "\x02"
+	markStop  = "\x03"
+)
+
+var headlineOptions = fmt.Sprintf(
+	"StartSel=%s, StopSel=%s, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \"",
+	markStart, markStop,
+)
+
+// hitsCTE selects the matching coffees and brews with $1 the user ID and $2
+// the query. Body is the text snippets are cut from.
+const hitsCTE = `WITH q AS (
+		SELECT websearch_to_tsquery('english', $2) AS query
+	),
+	hits AS (
+		SELECT 'coffee' AS type, c.id, c.id AS coffee_id, c.name AS coffee, r.name AS roaster,
+			NULL::date AS brew_date,
+			ts_rank(c.search_vector, q.query)::float8 AS rank,
+			concat_ws(' · ', c.name, r.name, c.country, c.region, c.farm, c.varietal, c.process, c.tasting_notes, c.notes) AS body
+		FROM coffees c
+		JOIN roasters r ON r.id = c.roaster_id
+		CROSS JOIN q
+		WHERE c.user_id = $1 AND c.search_vector @@ q.query
+		UNION ALL
+		SELECT 'brew', b.id, b.coffee_id, c.name, r.name,
+			b.brew_date,
+			ts_rank(b.search_vector, q.query)::float8,
+			concat_ws(' · ', b.overall_notes, b.improvement_notes, b.technique_notes)
+		FROM brews b
+		JOIN coffees c ON c.id = b.coffee_id
+		JOIN roasters r ON r.id = c.roaster_id
+		CROSS JOIN q
+		WHERE b.user_id = $1 AND b.search_vector @@ q.query
+	)`
+
+func (r *PgRepository) Search(ctx context.Context, userID string, params Params) ([]Result, int, error) {
+	args := []interface{}{userID, params.Query}
+	where := ""
+	if params.Type != "" {
+		args = append(args, params.Type)
+		where = "WHERE type = $3"
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`%s SELECT COUNT(*) FROM hits %s`, hitsCTE, where)
+	if err := r.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Headlines are costly, so they are cut only for the rows on the page.
+	n := len(args)
+	query := fmt.Sprintf(
+		`%s,
+		page AS (
+			SELECT * FROM hits %s
+			ORDER BY rank DESC, brew_date DESC NULLS FIRST, id
+			LIMIT $%d OFFSET $%d
+		)
+		SELECT p.type, p.id, p.coffee_id, p.coffee, p.roaster, p.brew_date, p.rank,
+			ts_headline('english', p.body, q.query, $%d)
+		FROM page p CROSS JOIN q
+		ORDER BY p.rank DESC, p.brew_date DESC NULLS FIRST, p.id`,
+		hitsCTE, where, n+1, n+2, n+3,
+	)
+	offset := (params.Page - 1) * params.PerPage
+	args = append(args, params.PerPage, offset, headlineOptions)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	results := []Result{}
+	for rows.Next() {
+		var res Result
+		var brewDate *time.Time
+		var headline string
+		if err := rows.Scan(&res.Type, &res.ID, &res.CoffeeID, &res.Coffee, &res.Roaster, &brewDate, &res.Rank, &headline); err != nil {
+			return nil, 0, err
+		}
+		if brewDate != nil {
+			s := brewDate.Format("2006-01-02")
+			res.BrewDate = &s
+		}
+		res.Snippet = highlight(headline)
+		results = append(results, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+// highlight escapes a ts_headline result and turns its match markers into
+// <mark> tags.
+func highlight(headline string) string {
+	s := html.EscapeString(headline)
+	s = strings.ReplaceAll(s, markStart, "<mark>")
+	return strings.ReplaceAll(s, markStop, "</mark>")
+}
@@ -0,0 +1,22 @@
+package search
+
+import (
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// MaxQueryLength bounds the search text.
+const MaxQueryLength = 200
+
+func validateParams(p Params) []api.FieldError {
+	var errs validate.Errors
+	if p.Query == "" {
+		errs.Add("q", "Search query is required")
+	} else if len(p.Query) > MaxQueryLength {
+		errs.Add("q", "Search query must be at most 200 characters")
+	}
+	if p.Type != "" && !validTypes[p.Type] {
+		errs.Add("type", "Type must be one of: coffee, brew")
+	}
+	return errs
+}
@@ -0,0 +1,89 @@
+package sharelink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// uaFamilies are checked in order and the first marker found in the
+// lower-cased user agent names the family. Order matters: Edge, Opera and
+// Samsung Internet also claim to be Chrome, and Chrome claims to be Safari.
+var uaFamilies = []struct {
+	marker string
+	family string
+}{
+	{"bot", "Bot"},
+	{"crawler", "Bot"},
+	{"spider", "Bot"},
+	{"facebookexternalhit", "Bot"},
+	{"whatsapp", "Bot"},
+	{"edg/", "Edge"},
+	{"edga/", "Edge"},
+	{"edgios/", "Edge"},
+	{"opr/", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+}
+
+// uaFamily reduces a user agent to its browser family, without versions or
+// platform, so it can't help fingerprint a viewer.
+func uaFamily(ua string) string {
+	ua = strings.ToLower(ua)
+	for _, f := range uaFamilies {
+		if strings.Contains(ua, f.marker) {
+			return f.family
+		}
+	}
+	return "Other"
+}
+
+// referrerHost keeps only the host of a referrer. It returns nil for direct
+// visits, unparseable values and the app's own host.
+func referrerHost(ref, ownHost string) *string {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == ownHost || len(host) > 255 {
+		return nil
+	}
+	return &host
+}
+
+// hashIP returns an HMAC of the client IP. The key never leaves the server,
+// so the hash counts unique visitors without being reversible by trying
+// every address.
+func hashIP(key []byte, remoteAddr string) string {
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newView describes a request for the share page. The page's own request
+// for the API carries the share URL as its Referer, so the frontend passes
+// the page's referrer as ?ref= instead.
+func (h *Handler) newView(r *http.Request) View {
+	ref := r.URL.Query().Get("ref")
+	if ref == "" {
+		ref = r.Referer()
+	}
+	return View{
+		IPHash:   hashIP(h.viewKey, r.RemoteAddr),
+		UAFamily: uaFamily(r.UserAgent()),
+		Referrer: referrerHost(ref, h.ownHost),
+	}
+}
@@ -0,0 +1,146 @@
+package sharelink
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Card size, the 1.91:1 ratio that Open Graph and Twitter previews use.
+const (
+	CardWidth  = 1200
+	CardHeight = 630
+)
+
+// cardCoffees is how many coffees the card lists before "and N more".
+const cardCoffees = 4
+
+const cardMargin = 72
+
+var (
+	cardTeal  = color.RGBA{0x0d, 0x94, 0x88, 0xff} // the app's theme color
+	cardInk   = color.RGBA{0x1c, 0x19, 0x17, 0xff}
+	cardMuted = color.RGBA{0x57, 0x53, 0x4e, 0xff}
+	cardPaper = color.RGBA{0xfa, 0xfa, 0xf9, 0xff}
+)
+
+// cardFonts are parsed on first use. The Go fonts are embedded in the
+// binary, so rendering needs no files or network.
+var cardFonts = sync.OnceValues(func() ([2]*opentype.Font, error) {
+	regular, err := opentype.Parse(goregular.TTF)
+	if err != nil {
+		return [2]*opentype.Font{}, err
+	}
+	bold, err := opentype.Parse(gobold.TTF)
+	return [2]*opentype.Font{regular, bold}, err
+})
+
+type faces struct {
+	brand, title, coffee, roaster, note font.Face
+}
+
+// newFaces sizes the fonts for one card. Faces aren't safe for concurrent
+// use, so each render gets its own.
+func newFaces() (*faces, error) {
+	fonts, err := cardFonts()
+	if err != nil {
+		return nil, err
+	}
+	regular, bold := fonts[0], fonts[1]
+
+	var f faces
+	for _, spec := range []struct {
+		face *font.Face
+		font *opentype.Font
+		size float64
+	}{
+		{&f.brand, bold, 36},
+		{&f.title, bold, 72},
+		{&f.coffee, bold, 38},
+		{&f.roaster, regular, 38},
+		{&f.note, regular, 32},
+	} {
+		*spec.face, err = opentype.NewFace(spec.font, &opentype.FaceOptions{Size: spec.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &f, nil
+}
+
+// renderCard draws the preview image for a share page: the coffee count and
+// the first few coffees, or only a notice for a protected link.
+func renderCard(w io.Writer, p *preview) error {
+	f, err := newFaces()
+	if err != nil {
+		return fmt.Errorf("loading card fonts: %w", err)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, CardWidth, CardHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(cardPaper), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, CardWidth, 96), image.NewUniform(cardTeal), image.Point{}, draw.Src)
+	drawText(img, f.brand, color.White, cardMargin, 62, siteName)
+
+	width := CardWidth - 2*cardMargin
+	if p.Protected {
+		drawText(img, f.title, cardInk, cardMargin, 300, "Coffee collection")
+		drawText(img, f.note, cardMuted, cardMargin, 370, "Enter the passphrase to see the coffees.")
+		return png.Encode(w, img)
+	}
+
+	drawText(img, f.title, cardInk, cardMargin, 200, coffeeCount(len(p.Coffees)))
+
+	y := 290
+	for i, c := range p.Coffees {
+		if i == cardCoffees {
+			drawText(img, f.note, cardMuted, cardMargin, y, fmt.Sprintf("and %d more", len(p.Coffees)-cardCoffees))
+			break
+		}
+		name := fit(f.coffee, c.Name, width)
+		x := drawText(img, f.coffee, cardInk, cardMargin, y, name)
+		if c.Roaster != nil && name == c.Name {
+			rest := width - (x - cardMargin)
+			drawText(img, f.roaster, cardMuted, x, y, fit(f.roaster, " · "+*c.Roaster, rest))
+		}
+		y += 68
+	}
+	return png.Encode(w, img)
+}
+
+// drawText draws s with its baseline at y and returns the x where it ends.
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, s string) int {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+	return d.Dot.X.Round()
+}
+
+// fit shortens s with an ellipsis until it is at most width pixels wide.
+func fit(face font.Face, s string, width int) string {
+	if font.MeasureString(face, s).Round() <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		t := string(runes) + "…"
+		if font.MeasureString(face, t).Round() <= width {
+			return t
+		}
+	}
+	return ""
+}
@@ -2,30 +2,251 @@ package sharelink
 
 import "time"
 
+// Link scopes. A link shares all of the user's active coffees, a chosen set
+// of coffees, or the coffees matching a filter.
+const (
+	ScopeAll     = "all"
+	ScopeCoffees = "coffees"
+	ScopeFilter  = "filter"
+)
+
+var validScopes = map[string]bool{
+	ScopeAll:     true,
+	ScopeCoffees: true,
+	ScopeFilter:  true,
+}
+
+// Link statuses, derived from RevokedAt and ExpiresAt.
+const (
+	StatusActive  = "active"
+	StatusExpired = "expired"
+	StatusRevoked = "revoked"
+)
+
+// Link is one of a user's share links. Revoked links are kept, so a revoked
+// token never resolves again. CoffeeIDs is only set for the coffees scope
+// and Filter only for the filter scope.
+type Link struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"-"`
+	Label      string     `json:"label"`
+	Token      string     `json:"token"`
+	URL        string     `json:"url"`
+	Scope      string     `json:"scope"`
+	CoffeeIDs  []string   `json:"coffee_ids"`
+	Filter     *Filter    `json:"filter"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Visibility Visibility `json:"visibility"`
+	ViewCount  int        `json:"view_count"`
+	// PassphraseHash is the bcrypt hash of the link's passphrase, or nil
+	// for an open link.
+	PassphraseHash *string    `json:"-"`
+	HasPassphrase  bool       `json:"has_passphrase"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// StatusAt reports whether the link is active, expired or revoked at now.
+func (l *Link) StatusAt(now time.Time) string {
+	switch {
+	case l.RevokedAt != nil:
+		return StatusRevoked
+	case l.ExpiresAt != nil && !l.ExpiresAt.After(now):
+		return StatusExpired
+	}
+	return StatusActive
+}
+
+// Filter selects coffees by their attributes. Every field that is set must
+// match; country and process match exactly, as in the coffee list filters.
+type Filter struct {
+	RoasterID *string `json:"roaster_id"`
+	Country   *string `json:"country"`
+	Process   *string `json:"process"`
+}
+
+type CreateRequest struct {
+	Label      string            `json:"label"`
+	Scope      string            `json:"scope"`
+	CoffeeIDs  []string          `json:"coffee_ids"`
+	Filter     *Filter           `json:"filter"`
+	ExpiresAt  *time.Time        `json:"expires_at"`
+	Visibility VisibilityRequest `json:"visibility"`
+	// Passphrase protects the link when set and not empty.
+	Passphrase *string `json:"passphrase"`
+
+	// passphraseHash is the hash of Passphrase, filled in by the handler.
+	passphraseHash *string
+}
+
+// UpdateRequest replaces a link's label, scope, expiry and visibility. The
+// token stays the same. The passphrase is kept when Passphrase is nil and
+// removed when it is empty.
+type UpdateRequest struct {
+	Label      string            `json:"label"`
+	Scope      string            `json:"scope"`
+	CoffeeIDs  []string          `json:"coffee_ids"`
+	Filter     *Filter           `json:"filter"`
+	ExpiresAt  *time.Time        `json:"expires_at"`
+	Visibility VisibilityRequest `json:"visibility"`
+	Passphrase *string           `json:"passphrase"`
+
+	passphraseHash *string
+}
+
+// UnlockRequest carries the passphrase for a protected link.
+type UnlockRequest struct {
+	Passphrase string `json:"passphrase"`
+}
+
+// UnlockResponse carries a viewer token for a protected link. Send it as a
+// bearer token when fetching the share page.
+type UnlockResponse struct {
+	ViewerToken string    `json:"viewer_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Visibility decides which groups of coffee data a link exposes. Name,
+// roaster and roast level are always shown.
+type Visibility struct {
+	// Origin covers country, region and process.
+	Origin       bool `json:"origin"`
+	RoastDate    bool `json:"roast_date"`
+	TastingNotes bool `json:"tasting_notes"`
+	// Sensory covers the six intensity scores of the reference brew.
+	Sensory bool `json:"sensory"`
+	Score   bool `json:"score"`
+	// Recipe covers the reference brew's method, dose, ratio, grind,
+	// temperature, dripper and pours.
+	Recipe bool `json:"recipe"`
+}
+
+// VisibilityRequest sets a link's visibility. Groups left out take their
+// default on create, so {"recipe": true} shares everything, and keep their
+// current setting on update.
+type VisibilityRequest struct {
+	Origin       *bool `json:"origin"`
+	RoastDate    *bool `json:"roast_date"`
+	TastingNotes *bool `json:"tasting_notes"`
+	Sensory      *bool `json:"sensory"`
+	Score        *bool `json:"score"`
+	Recipe       *bool `json:"recipe"`
+}
+
+// ShareLink is the single-link view used by the /share-link endpoints, which
+// manage the user's default link: the newest active one that shares all
+// coffees and never expires.
 type ShareLink struct {
 	Token     *string    `json:"token"`
 	URL       *string    `json:"url"`
 	CreatedAt *time.Time `json:"created_at"`
 }
 
+// ShareCoffee is a coffee as shown on the public share page. FlavorCategories
+// lists the flavor wheel categories in its tasting notes and is only filled
+// when the request asks for it.
 type ShareCoffee struct {
-	Roaster       *string            `json:"roaster"`
-	Name          string             `json:"name"`
-	Country       *string            `json:"country"`
-	Region        *string            `json:"region"`
-	Process       *string            `json:"process"`
-	RoastLevel    *string            `json:"roast_level"`
-	TastingNotes  *string            `json:"tasting_notes"`
-	RoastDate     *string            `json:"roast_date"`
-	ReferenceBrew *ShareReferenceBrew `json:"reference_brew"`
+	Roaster          *string             `json:"roaster"`
+	Name             string              `json:"name"`
+	Country          *string             `json:"country"`
+	Region           *string             `json:"region"`
+	Process          *string             `json:"process"`
+	RoastLevel       *string             `json:"roast_level"`
+	TastingNotes     *string             `json:"tasting_notes"`
+	RoastDate        *string             `json:"roast_date"`
+	ReferenceBrew    *ShareReferenceBrew `json:"reference_brew"`
+	FlavorCategories []string            `json:"flavor_categories,omitempty"`
 }
 
 type ShareReferenceBrew struct {
-	OverallScore        *int `json:"overall_score"`
-	AromaIntensity      *int `json:"aroma_intensity"`
-	BodyIntensity       *int `json:"body_intensity"`
-	SweetnessIntensity  *int `json:"sweetness_intensity"`
-	BrightnessIntensity *int `json:"brightness_intensity"`
-	ComplexityIntensity *int `json:"complexity_intensity"`
-	AftertasteIntensity *int `json:"aftertaste_intensity"`
+	OverallScore        *int         `json:"overall_score"`
+	AromaIntensity      *int         `json:"aroma_intensity"`
+	BodyIntensity       *int         `json:"body_intensity"`
+	SweetnessIntensity  *int         `json:"sweetness_intensity"`
+	BrightnessIntensity *int         `json:"brightness_intensity"`
+	ComplexityIntensity *int         `json:"complexity_intensity"`
+	AftertasteIntensity *int         `json:"aftertaste_intensity"`
+	Recipe              *ShareRecipe `json:"recipe"`
+}
+
+// ShareRecipe is how the reference brew was made. Grinder and Dripper are
+// equipment names.
+type ShareRecipe struct {
+	Method           string      `json:"method"`
+	CoffeeWeight     *float64    `json:"coffee_weight"`
+	Ratio            *float64    `json:"ratio"`
+	GrindSize        *float64    `json:"grind_size"`
+	Grinder          *string     `json:"grinder"`
+	WaterTemperature *float64    `json:"water_temperature"`
+	Dripper          *string     `json:"dripper"`
+	Pours            []SharePour `json:"pours"`
+	TotalBrewTime    *int        `json:"total_brew_time"`
+}
+
+type SharePour struct {
+	PourNumber  int      `json:"pour_number"`
+	WaterAmount *float64 `json:"water_amount"`
+	PourStyle   *string  `json:"pour_style"`
+	WaitTime    *int     `json:"wait_time"`
+}
+
+// Analytics bucket sizes, as in the brew statistics.
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+var validBuckets = map[string]bool{
+	BucketDay:   true,
+	BucketWeek:  true,
+	BucketMonth: true,
+}
+
+// View is one request for a share page through an active link. The IP is
+// only kept as a keyed hash, and the referrer only as a host.
+type View struct {
+	IPHash   string
+	UAFamily string
+	Referrer *string
+}
+
+// AnalyticsParams selects the period, in UTC days, and the bucket size.
+type AnalyticsParams struct {
+	Bucket   string
+	DateFrom string
+	DateTo   string
+}
+
+// Analytics summarizes a link's views over a period. TotalViews counts every
+// view since the link was created; the other figures come from the raw views
+// still within the retention period.
+type Analytics struct {
+	LinkID         string      `json:"link_id"`
+	Bucket         string      `json:"bucket"`
+	DateFrom       string      `json:"date_from"`
+	DateTo         string      `json:"date_to"`
+	TotalViews     int         `json:"total_views"`
+	Views          int         `json:"views"`
+	UniqueVisitors int         `json:"unique_visitors"`
+	Items          []ViewPoint `json:"items"`
+	UserAgents     []ViewCount `json:"user_agents"`
+	Referrers      []ViewCount `json:"referrers"`
+}
+
+// ViewPoint is one bucket of the views series. Buckets without views are
+// included.
+type ViewPoint struct {
+	BucketStart    string `json:"bucket_start"`
+	Views          int    `json:"views"`
+	UniqueVisitors int    `json:"unique_visitors"`
+}
+
+// ViewCount counts views by user agent family or referrer host. Name is nil
+// for views without a referrer.
+type ViewCount struct {
+	Name  *string `json:"name"`
+	Views int     `json:"views"`
 }
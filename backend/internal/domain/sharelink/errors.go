@@ -0,0 +1,11 @@
+package sharelink
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound        = domainerr.NotFound("This share link is no longer active.")
+	ErrLinkNotFound    = domainerr.NotFound("Share link not found")
+	ErrRevoked         = domainerr.Conflict("This share link has been revoked")
+	ErrCoffeeNotFound  = domainerr.InvalidReference("coffee_ids", "Coffee not found")
+	ErrRoasterNotFound = domainerr.InvalidReference("filter.roaster_id", "Roaster not found")
+)
@@ -2,46 +2,273 @@ package sharelink
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
 
 	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/wheel"
 )
 
 type Handler struct {
 	repo    Repository
 	baseURL string
+	// ownHost is the app's host, left out of referrers.
+	ownHost string
+	// viewKey keys the IP hashes in recorded views, and viewerKey signs
+	// viewer tokens. Both are derived from the server secret, so they are
+	// stable across restarts.
+	viewKey   []byte
+	viewerKey []byte
 }
 
-func NewHandler(repo Repository, baseURL string) *Handler {
-	return &Handler{repo: repo, baseURL: baseURL}
+func NewHandler(repo Repository, baseURL, secret string) *Handler {
+	var ownHost string
+	if u, err := url.Parse(baseURL); err == nil {
+		ownHost = strings.ToLower(u.Hostname())
+	}
+	viewKey := sha256.Sum256([]byte("share-link-views:" + secret))
+	viewerKey := sha256.Sum256([]byte("share-link-viewer:" + secret))
+	return &Handler{
+		repo:      repo,
+		baseURL:   baseURL,
+		ownHost:   ownHost,
+		viewKey:   viewKey[:],
+		viewerKey: viewerKey[:],
+	}
 }
 
 func (h *Handler) buildURL(token string) string {
 	return h.baseURL + "/share/" + token
 }
 
-func (h *Handler) GetShareLink(w http.ResponseWriter, r *http.Request) {
+// defaultLabel names the links created through the /share-link endpoints.
+const defaultLabel = "Share link"
+
+// newToken returns 16 random bytes, hex-encoded.
+func newToken() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// present fills in the fields derived from the stored link.
+func (h *Handler) present(l *Link) {
+	l.URL = h.buildURL(l.Token)
+	l.Status = l.StatusAt(time.Now())
+	l.HasPassphrase = l.PassphraseHash != nil
+	if l.CoffeeIDs == nil {
+		l.CoffeeIDs = []string{}
+	}
+}
+
+func (h *Handler) ListLinks(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	links, err := h.repo.List(r.Context(), userID)
+	if err != nil {
+		log.Printf("error listing share links: %v", err)
+		api.InternalError(w)
+		return
+	}
+	for i := range links {
+		h.present(&links[i])
+	}
+
+	api.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"items": links,
+	})
+}
+
+func (h *Handler) GetLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	l, err := h.repo.GetByID(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error getting share link: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if l == nil {
+		api.NotFoundError(w, "Share link not found")
+		return
+	}
+
+	h.present(l)
+	api.WriteJSON(w, http.StatusOK, l)
+}
+
+func (h *Handler) CreateLink(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 
-	token, createdAt, err := h.repo.GetShareToken(r.Context(), userID)
+	var req CreateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	normalize(&req)
+	if fieldErrors := validateRequest(req, time.Now()); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	if err := hashPassphrase(&req); err != nil {
+		log.Printf("error hashing share link passphrase: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	token, err := newToken()
 	if err != nil {
-		log.Printf("error getting share token: %v", err)
+		log.Printf("error generating share token: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	l, err := h.repo.Create(r.Context(), userID, token, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error creating share link: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	h.present(l)
+	api.WriteJSON(w, http.StatusCreated, l)
+}
+
+func (h *Handler) UpdateLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req UpdateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	normalize((*CreateRequest)(&req))
+	if fieldErrors := validateRequest(CreateRequest(req), time.Now()); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	if err := hashPassphrase((*CreateRequest)(&req)); err != nil {
+		log.Printf("error hashing share link passphrase: %v", err)
 		api.InternalError(w)
 		return
 	}
 
-	resp := ShareLink{
-		Token:     token,
-		CreatedAt: createdAt,
+	l, err := h.repo.Update(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error updating share link: %v", err)
+		api.InternalError(w)
+		return
 	}
-	if token != nil {
-		url := h.buildURL(*token)
-		resp.URL = &url
+	if l == nil {
+		api.NotFoundError(w, "Share link not found")
+		return
+	}
+
+	h.present(l)
+	api.WriteJSON(w, http.StatusOK, l)
+}
+
+// RevokeLink revokes a link rather than deleting it, so its token can never
+// be handed out again.
+func (h *Handler) RevokeLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	if err := h.repo.Revoke(r.Context(), userID, id); err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error revoking share link: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Analytics reports a link's views over a period, by default the last 30
+// days in daily buckets.
+func (h *Handler) Analytics(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+	q := r.URL.Query()
+
+	today := time.Now().UTC()
+	params := AnalyticsParams{
+		Bucket:   q.Get("bucket"),
+		DateFrom: q.Get("date_from"),
+		DateTo:   q.Get("date_to"),
+	}
+	if params.Bucket == "" {
+		params.Bucket = BucketDay
+	}
+	if params.DateTo == "" {
+		params.DateTo = today.Format("2006-01-02")
+	}
+	if params.DateFrom == "" {
+		params.DateFrom = today.AddDate(0, 0, -(defaultAnalyticsDays - 1)).Format("2006-01-02")
+	}
+
+	if fieldErrors := validateAnalyticsParams(params); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+	if fieldErrors := validateAnalyticsSpan(params); len(fieldErrors) > 0 {
+		api.RangeTooLargeError(w, fieldErrors)
+		return
+	}
+
+	a, err := h.repo.Analytics(r.Context(), userID, id, params)
+	if err != nil {
+		log.Printf("error getting share link analytics: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if a == nil {
+		api.NotFoundError(w, "Share link not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, a)
+}
+
+func (h *Handler) GetShareLink(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	l, err := h.repo.GetDefault(r.Context(), userID)
+	if err != nil {
+		log.Printf("error getting share link: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	var resp ShareLink
+	if l != nil {
+		url := h.buildURL(l.Token)
+		resp = ShareLink{Token: &l.Token, URL: &url, CreatedAt: &l.CreatedAt}
 	}
 
 	api.WriteJSON(w, http.StatusOK, resp)
@@ -50,34 +277,33 @@ func (h *Handler) GetShareLink(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
+	token, err := newToken()
+	if err != nil {
 		log.Printf("error generating share token: %v", err)
 		api.InternalError(w)
 		return
 	}
-	token := hex.EncodeToString(bytes)
 
-	createdAt, err := h.repo.SetShareToken(r.Context(), userID, token)
+	l, err := h.repo.ReplaceDefault(r.Context(), userID, token, defaultLabel)
 	if err != nil {
-		log.Printf("error setting share token: %v", err)
+		log.Printf("error replacing share link: %v", err)
 		api.InternalError(w)
 		return
 	}
 
-	url := h.buildURL(token)
+	url := h.buildURL(l.Token)
 	api.WriteJSON(w, http.StatusCreated, ShareLink{
-		Token:     &token,
+		Token:     &l.Token,
 		URL:       &url,
-		CreatedAt: createdAt,
+		CreatedAt: &l.CreatedAt,
 	})
 }
 
 func (h *Handler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r.Context())
 
-	if err := h.repo.ClearShareToken(r.Context(), userID); err != nil {
-		log.Printf("error clearing share token: %v", err)
+	if err := h.repo.RevokeDefault(r.Context(), userID); err != nil {
+		log.Printf("error revoking share link: %v", err)
 		api.InternalError(w)
 		return
 	}
@@ -85,28 +311,92 @@ func (h *Handler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetSharedCoffees serves the public share page. Unknown, revoked and
+// expired tokens all get the same 404.
 func (h *Handler) GetSharedCoffees(w http.ResponseWriter, r *http.Request) {
 	token := chi.URLParam(r, "token")
 
-	userID, err := h.repo.GetUserIDByToken(r.Context(), token)
+	link, err := h.repo.GetActiveByToken(r.Context(), token)
 	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
 		log.Printf("error looking up share token: %v", err)
 		api.InternalError(w)
 		return
 	}
-	if userID == nil {
-		api.NotFoundError(w, "This share link is no longer active.")
+
+	if !h.unlocked(r, link) {
+		api.UnauthorizedError(w, "This share link needs a passphrase")
 		return
 	}
 
-	coffees, err := h.repo.GetSharedCoffees(r.Context(), *userID)
+	coffees, err := h.repo.GetSharedCoffees(r.Context(), link.ID)
 	if err != nil {
 		log.Printf("error getting shared coffees: %v", err)
 		api.InternalError(w)
 		return
 	}
 
+	// A view that can't be recorded shouldn't cost the visitor the page.
+	if err := h.repo.RecordView(r.Context(), link.ID, h.newView(r)); err != nil {
+		log.Printf("error recording share link view: %v", err)
+	}
+
+	// Flavor categories come from the tasting notes, so they are derived
+	// after the policy has had a chance to hide them.
+	flavors := r.URL.Query().Get("flavor_categories") == "true"
+	for i := range coffees {
+		link.Visibility.Apply(&coffees[i])
+		if flavors && coffees[i].TastingNotes != nil {
+			coffees[i].FlavorCategories = wheel.TopCategories(*coffees[i].TastingNotes)
+		}
+	}
+
 	api.WriteJSON(w, http.StatusOK, map[string]interface{}{
-		"items": coffees,
+		"items":      coffees,
+		"visibility": link.Visibility,
 	})
 }
+
+// Unlock exchanges a protected link's passphrase for a viewer token.
+func (h *Handler) Unlock(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	var req UnlockRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+	if req.Passphrase == "" {
+		api.ValidationError(w, []api.FieldError{{Field: "passphrase", Message: "is required"}})
+		return
+	}
+
+	link, err := h.repo.GetActiveByToken(r.Context(), token)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error looking up share token: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if link.PassphraseHash == nil {
+		api.ConflictError(w, "This share link has no passphrase")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(*link.PassphraseHash), []byte(req.Passphrase)); err != nil {
+		api.UnauthorizedError(w, "Incorrect passphrase")
+		return
+	}
+
+	viewerToken, expiresAt, err := h.issueViewerToken(link, time.Now())
+	if err != nil {
+		log.Printf("error signing viewer token: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, UnlockResponse{ViewerToken: viewerToken, ExpiresAt: expiresAt})
+}
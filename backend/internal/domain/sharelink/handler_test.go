@@ -4,14 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"image/png"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
 
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
 	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
 )
 
@@ -21,77 +27,330 @@ const testBaseURL = "https://brewlab.example.com"
 // --- Mock Repository ---
 
 type mockRepo struct {
-	tokens  map[string]*mockTokenData // keyed by userID
-	coffees map[string][]ShareCoffee  // keyed by userID
+	links    map[string]*Link        // keyed by link ID
+	coffees  map[string][]mockCoffee // keyed by userID
+	roasters map[string]string       // roaster ID -> userID
+	views    []mockView
+	nextID   int
+	// analyticsParams records the last params passed to Analytics.
+	analyticsParams AnalyticsParams
 }
 
-type mockTokenData struct {
-	token     string
-	createdAt time.Time
+type mockView struct {
+	linkID   string
+	viewedAt time.Time
+	View
+}
+
+// mockCoffee carries the attributes link scopes select on, which the public
+// ShareCoffee leaves out.
+type mockCoffee struct {
+	id        string
+	roasterID string
+	ShareCoffee
 }
 
 func newMockRepo() *mockRepo {
 	return &mockRepo{
-		tokens:  make(map[string]*mockTokenData),
-		coffees: make(map[string][]ShareCoffee),
+		links:    make(map[string]*Link),
+		coffees:  make(map[string][]mockCoffee),
+		roasters: make(map[string]string),
 	}
 }
 
-func (m *mockRepo) GetShareToken(_ context.Context, userID string) (*string, *time.Time, error) {
-	data := m.tokens[userID]
-	if data == nil {
-		return nil, nil, nil
+// seedLink adds an active link sharing all of the user's coffees.
+func (m *mockRepo) seedLink(userID, token string, createdAt time.Time) *Link {
+	m.nextID++
+	l := &Link{
+		ID:         fmt.Sprintf("link-%d", m.nextID),
+		UserID:     userID,
+		Label:      defaultLabel,
+		Token:      token,
+		Scope:      ScopeAll,
+		Visibility: DefaultVisibility(),
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
 	}
-	return &data.token, &data.createdAt, nil
+	m.links[l.ID] = l
+	return l
 }
 
-func (m *mockRepo) SetShareToken(_ context.Context, userID, token string) (*time.Time, error) {
-	now := time.Now().UTC().Truncate(time.Second)
-	m.tokens[userID] = &mockTokenData{token: token, createdAt: now}
-	return &now, nil
+// seedCoffees gives the user coffees c-1, c-2, ... in order, all without a
+// roaster.
+func (m *mockRepo) seedCoffees(userID string, coffees []ShareCoffee) {
+	for i, c := range coffees {
+		m.coffees[userID] = append(m.coffees[userID], mockCoffee{id: fmt.Sprintf("c-%d", i+1), ShareCoffee: c})
+	}
+}
+
+func (m *mockRepo) List(_ context.Context, userID string) ([]Link, error) {
+	links := []Link{}
+	for _, l := range m.links {
+		if l.UserID == userID {
+			links = append(links, *l)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if !links[i].CreatedAt.Equal(links[j].CreatedAt) {
+			return links[i].CreatedAt.After(links[j].CreatedAt)
+		}
+		return links[i].ID > links[j].ID
+	})
+	return links, nil
+}
+
+func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*Link, error) {
+	l := m.links[id]
+	if l == nil || l.UserID != userID {
+		return nil, nil
+	}
+	c := *l
+	return &c, nil
+}
+
+func (m *mockRepo) checkScope(userID string, req CreateRequest) error {
+	switch req.Scope {
+	case ScopeCoffees:
+		for _, id := range req.CoffeeIDs {
+			found := false
+			for _, c := range m.coffees[userID] {
+				found = found || c.id == id
+			}
+			if !found {
+				return ErrCoffeeNotFound
+			}
+		}
+	case ScopeFilter:
+		if id := req.Filter.RoasterID; id != nil && m.roasters[*id] != userID {
+			return ErrRoasterNotFound
+		}
+	}
+	return nil
+}
+
+// apply stores the request's label, scope, expiry, visibility and
+// passphrase on l. Visibility groups the request leaves out keep l's, which
+// seedLink sets to the defaults.
+func apply(l *Link, req CreateRequest) {
+	l.Label = req.Label
+	l.Scope = req.Scope
+	l.CoffeeIDs = nil
+	l.Filter = nil
+	switch req.Scope {
+	case ScopeCoffees:
+		l.CoffeeIDs = append([]string{}, req.CoffeeIDs...)
+	case ScopeFilter:
+		f := *req.Filter
+		l.Filter = &f
+	}
+	l.ExpiresAt = req.ExpiresAt
+	l.Visibility = req.Visibility.resolve(l.Visibility)
+	if req.Passphrase != nil {
+		l.PassphraseHash = req.passphraseHash
+	}
+	l.UpdatedAt = time.Now()
+}
+
+func (m *mockRepo) Create(_ context.Context, userID, token string, req CreateRequest) (*Link, error) {
+	if err := m.checkScope(userID, req); err != nil {
+		return nil, err
+	}
+	l := m.seedLink(userID, token, time.Now())
+	apply(l, req)
+	c := *l
+	return &c, nil
+}
+
+func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateRequest) (*Link, error) {
+	l := m.links[id]
+	if l == nil || l.UserID != userID {
+		return nil, nil
+	}
+	if l.RevokedAt != nil {
+		return nil, ErrRevoked
+	}
+	if err := m.checkScope(userID, CreateRequest(req)); err != nil {
+		return nil, err
+	}
+	apply(l, CreateRequest(req))
+	c := *l
+	return &c, nil
+}
+
+func (m *mockRepo) Revoke(_ context.Context, userID, id string) error {
+	l := m.links[id]
+	if l == nil || l.UserID != userID {
+		return ErrLinkNotFound
+	}
+	if l.RevokedAt == nil {
+		now := time.Now()
+		l.RevokedAt = &now
+	}
+	return nil
+}
+
+func (m *mockRepo) defaultLink(userID string) *Link {
+	var newest *Link
+	for _, l := range m.links {
+		if l.UserID != userID || l.Scope != ScopeAll || l.ExpiresAt != nil || l.RevokedAt != nil || l.PassphraseHash != nil {
+			continue
+		}
+		if newest == nil || l.CreatedAt.After(newest.CreatedAt) {
+			newest = l
+		}
+	}
+	return newest
+}
+
+func (m *mockRepo) GetDefault(_ context.Context, userID string) (*Link, error) {
+	l := m.defaultLink(userID)
+	if l == nil {
+		return nil, nil
+	}
+	c := *l
+	return &c, nil
+}
+
+func (m *mockRepo) ReplaceDefault(ctx context.Context, userID, token, label string) (*Link, error) {
+	m.RevokeDefault(ctx, userID)
+	l := m.seedLink(userID, token, time.Now().UTC().Truncate(time.Second))
+	l.Label = label
+	c := *l
+	return &c, nil
 }
 
-func (m *mockRepo) ClearShareToken(_ context.Context, userID string) error {
-	delete(m.tokens, userID)
+func (m *mockRepo) RevokeDefault(_ context.Context, userID string) error {
+	if l := m.defaultLink(userID); l != nil {
+		now := time.Now()
+		l.RevokedAt = &now
+	}
 	return nil
 }
 
-func (m *mockRepo) GetUserIDByToken(_ context.Context, token string) (*string, error) {
-	for userID, data := range m.tokens {
-		if data.token == token {
-			return &userID, nil
+func (m *mockRepo) GetActiveByToken(_ context.Context, token string) (*Link, error) {
+	for _, l := range m.links {
+		if l.Token == token && l.StatusAt(time.Now()) == StatusActive {
+			c := *l
+			return &c, nil
 		}
 	}
-	return nil, nil
+	return nil, ErrNotFound
 }
 
-func (m *mockRepo) GetSharedCoffees(_ context.Context, userID string) ([]ShareCoffee, error) {
-	coffees := m.coffees[userID]
-	if coffees == nil {
-		return []ShareCoffee{}, nil
+func (m *mockRepo) GetSharedCoffees(_ context.Context, linkID string) ([]ShareCoffee, error) {
+	l := m.links[linkID]
+	coffees := []ShareCoffee{}
+	for _, c := range m.coffees[l.UserID] {
+		if inScope(l, c) {
+			coffees = append(coffees, c.ShareCoffee)
+		}
 	}
 	return coffees, nil
 }
 
+func (m *mockRepo) RecordView(_ context.Context, linkID string, v View) error {
+	m.views = append(m.views, mockView{linkID: linkID, viewedAt: time.Now(), View: v})
+	m.links[linkID].ViewCount++
+	return nil
+}
+
+// Analytics counts every recorded view of the link; bucketing by date is
+// left to the SQL.
+func (m *mockRepo) Analytics(_ context.Context, userID, linkID string, params AnalyticsParams) (*Analytics, error) {
+	m.analyticsParams = params
+	l := m.links[linkID]
+	if l == nil || l.UserID != userID {
+		return nil, nil
+	}
+	a := &Analytics{
+		LinkID: linkID, Bucket: params.Bucket, DateFrom: params.DateFrom, DateTo: params.DateTo,
+		TotalViews: l.ViewCount,
+		Items:      []ViewPoint{}, UserAgents: []ViewCount{}, Referrers: []ViewCount{},
+	}
+	visitors := map[string]bool{}
+	for _, v := range m.views {
+		if v.linkID == linkID {
+			a.Views++
+			visitors[v.IPHash] = true
+		}
+	}
+	a.UniqueVisitors = len(visitors)
+	return a, nil
+}
+
+func (m *mockRepo) PurgeViews(_ context.Context, before time.Time) (int64, error) {
+	kept := m.views[:0]
+	for _, v := range m.views {
+		if !v.viewedAt.Before(before) {
+			kept = append(kept, v)
+		}
+	}
+	n := int64(len(m.views) - len(kept))
+	m.views = kept
+	return n, nil
+}
+
+func inScope(l *Link, c mockCoffee) bool {
+	switch l.Scope {
+	case ScopeCoffees:
+		for _, id := range l.CoffeeIDs {
+			if id == c.id {
+				return true
+			}
+		}
+		return false
+	case ScopeFilter:
+		f := l.Filter
+		return (f.RoasterID == nil || *f.RoasterID == c.roasterID) &&
+			(f.Country == nil || (c.Country != nil && *f.Country == *c.Country)) &&
+			(f.Process == nil || (c.Process != nil && *f.Process == *c.Process))
+	}
+	return true
+}
+
 // Error-returning mock
 
 type errorRepo struct{}
 
-func (e *errorRepo) GetShareToken(_ context.Context, _ string) (*string, *time.Time, error) {
-	return nil, nil, errors.New("database error")
+func (e *errorRepo) List(_ context.Context, _ string) ([]Link, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) GetByID(_ context.Context, _, _ string) (*Link, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Create(_ context.Context, _, _ string, _ CreateRequest) (*Link, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Update(_ context.Context, _, _ string, _ UpdateRequest) (*Link, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Revoke(_ context.Context, _, _ string) error {
+	return errors.New("database error")
+}
+func (e *errorRepo) GetDefault(_ context.Context, _ string) (*Link, error) {
+	return nil, errors.New("database error")
 }
-func (e *errorRepo) SetShareToken(_ context.Context, _, _ string) (*time.Time, error) {
+func (e *errorRepo) ReplaceDefault(_ context.Context, _, _, _ string) (*Link, error) {
 	return nil, errors.New("database error")
 }
-func (e *errorRepo) ClearShareToken(_ context.Context, _ string) error {
+func (e *errorRepo) RevokeDefault(_ context.Context, _ string) error {
 	return errors.New("database error")
 }
-func (e *errorRepo) GetUserIDByToken(_ context.Context, _ string) (*string, error) {
+func (e *errorRepo) GetActiveByToken(_ context.Context, _ string) (*Link, error) {
 	return nil, errors.New("database error")
 }
 func (e *errorRepo) GetSharedCoffees(_ context.Context, _ string) ([]ShareCoffee, error) {
 	return nil, errors.New("database error")
 }
+func (e *errorRepo) RecordView(_ context.Context, _ string, _ View) error {
+	return errors.New("database error")
+}
+func (e *errorRepo) Analytics(_ context.Context, _, _ string, _ AnalyticsParams) (*Analytics, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) PurgeViews(_ context.Context, _ time.Time) (int64, error) {
+	return 0, errors.New("database error")
+}
 
 // --- Helpers ---
 
@@ -110,8 +369,11 @@ func generateTestAccessToken(userID string) string {
 func setupRouter(h *Handler) *chi.Mux {
 	r := chi.NewRouter()
 
-	// Public share endpoint
+	// Public share endpoints
+	r.Get("/share/{token}", h.Page)
 	r.Get("/api/v1/share/{token}", h.GetSharedCoffees)
+	r.Get("/api/v1/share/{token}/image.png", h.Image)
+	r.Post("/api/v1/share/{token}/unlock", h.Unlock)
 
 	// Protected endpoints
 	r.Group(func(r chi.Router) {
@@ -119,6 +381,14 @@ func setupRouter(h *Handler) *chi.Mux {
 		r.Get("/api/v1/share-link", h.GetShareLink)
 		r.Post("/api/v1/share-link", h.CreateShareLink)
 		r.Delete("/api/v1/share-link", h.RevokeShareLink)
+		r.Route("/api/v1/share-links", func(r chi.Router) {
+			r.Get("/", h.ListLinks)
+			r.Post("/", h.CreateLink)
+			r.Get("/{id}", h.GetLink)
+			r.Put("/{id}", h.UpdateLink)
+			r.Delete("/{id}", h.RevokeLink)
+			r.Get("/{id}/analytics", h.Analytics)
+		})
 	})
 
 	return r
@@ -130,6 +400,13 @@ func authRequest(method, url string) *http.Request {
 	return req
 }
 
+func authJSONRequest(method, url, body string) *http.Request {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
 func intPtr(i int) *int       { return &i }
 func strPtr(s string) *string { return &s }
 
@@ -137,7 +414,7 @@ func strPtr(s string) *string { return &s }
 
 func TestGetShareLink_NoLink(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodGet, "/api/v1/share-link")
@@ -165,8 +442,8 @@ func TestGetShareLink_NoLink(t *testing.T) {
 func TestGetShareLink_HasLink(t *testing.T) {
 	repo := newMockRepo()
 	now := time.Now().UTC().Truncate(time.Second)
-	repo.tokens["user-123"] = &mockTokenData{token: "abc123", createdAt: now}
-	h := NewHandler(repo, testBaseURL)
+	repo.seedLink("user-123", "abc123", now)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodGet, "/api/v1/share-link")
@@ -194,7 +471,7 @@ func TestGetShareLink_HasLink(t *testing.T) {
 
 func TestGetShareLink_Unauthenticated(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/share-link", nil)
@@ -207,7 +484,7 @@ func TestGetShareLink_Unauthenticated(t *testing.T) {
 }
 
 func TestGetShareLink_DatabaseError(t *testing.T) {
-	h := NewHandler(&errorRepo{}, testBaseURL)
+	h := NewHandler(&errorRepo{}, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodGet, "/api/v1/share-link")
@@ -223,7 +500,7 @@ func TestGetShareLink_DatabaseError(t *testing.T) {
 
 func TestCreateShareLink_Success(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodPost, "/api/v1/share-link")
@@ -257,8 +534,8 @@ func TestCreateShareLink_Success(t *testing.T) {
 
 func TestCreateShareLink_ReplacesExisting(t *testing.T) {
 	repo := newMockRepo()
-	repo.tokens["user-123"] = &mockTokenData{token: "old-token", createdAt: time.Now()}
-	h := NewHandler(repo, testBaseURL)
+	repo.seedLink("user-123", "old-token", time.Now())
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodPost, "/api/v1/share-link")
@@ -277,15 +554,15 @@ func TestCreateShareLink_ReplacesExisting(t *testing.T) {
 	}
 
 	// Verify old token no longer resolves
-	oldUserID, _ := repo.GetUserIDByToken(context.Background(), "old-token")
-	if oldUserID != nil {
-		t.Error("old token should no longer resolve to a user")
+	oldLink, _ := repo.GetActiveByToken(context.Background(), "old-token")
+	if oldLink != nil {
+		t.Error("old token should no longer resolve to a link")
 	}
 }
 
 func TestCreateShareLink_Unauthenticated(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/share-link", nil)
@@ -298,7 +575,7 @@ func TestCreateShareLink_Unauthenticated(t *testing.T) {
 }
 
 func TestCreateShareLink_DatabaseError(t *testing.T) {
-	h := NewHandler(&errorRepo{}, testBaseURL)
+	h := NewHandler(&errorRepo{}, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodPost, "/api/v1/share-link")
@@ -314,8 +591,8 @@ func TestCreateShareLink_DatabaseError(t *testing.T) {
 
 func TestRevokeShareLink_Success(t *testing.T) {
 	repo := newMockRepo()
-	repo.tokens["user-123"] = &mockTokenData{token: "abc123", createdAt: time.Now()}
-	h := NewHandler(repo, testBaseURL)
+	repo.seedLink("user-123", "abc123", time.Now())
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodDelete, "/api/v1/share-link")
@@ -327,14 +604,14 @@ func TestRevokeShareLink_Success(t *testing.T) {
 	}
 
 	// Verify token is cleared
-	if repo.tokens["user-123"] != nil {
+	if l, _ := repo.GetDefault(context.Background(), "user-123"); l != nil {
 		t.Error("expected token to be cleared after revoke")
 	}
 }
 
 func TestRevokeShareLink_NoExistingLink(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	// Revoking when no link exists should succeed silently
@@ -349,7 +626,7 @@ func TestRevokeShareLink_NoExistingLink(t *testing.T) {
 
 func TestRevokeShareLink_Unauthenticated(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodDelete, "/api/v1/share-link", nil)
@@ -362,7 +639,7 @@ func TestRevokeShareLink_Unauthenticated(t *testing.T) {
 }
 
 func TestRevokeShareLink_DatabaseError(t *testing.T) {
-	h := NewHandler(&errorRepo{}, testBaseURL)
+	h := NewHandler(&errorRepo{}, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := authRequest(http.MethodDelete, "/api/v1/share-link")
@@ -378,8 +655,8 @@ func TestRevokeShareLink_DatabaseError(t *testing.T) {
 
 func TestGetSharedCoffees_ValidToken(t *testing.T) {
 	repo := newMockRepo()
-	repo.tokens["user-123"] = &mockTokenData{token: "valid-token", createdAt: time.Now()}
-	repo.coffees["user-123"] = []ShareCoffee{
+	repo.seedLink("user-123", "valid-token", time.Now())
+	repo.seedCoffees("user-123", []ShareCoffee{
 		{
 			Name:       "Kiamaina",
 			Roaster:    strPtr("Cata Coffee"),
@@ -396,8 +673,8 @@ func TestGetSharedCoffees_ValidToken(t *testing.T) {
 			Roaster: strPtr("Manhattan"),
 			Country: strPtr("Ethiopia"),
 		},
-	}
-	h := NewHandler(repo, testBaseURL)
+	})
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/valid-token", nil)
@@ -431,7 +708,7 @@ func TestGetSharedCoffees_ValidToken(t *testing.T) {
 
 func TestGetSharedCoffees_InvalidToken(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/nonexistent", nil)
@@ -443,11 +720,47 @@ func TestGetSharedCoffees_InvalidToken(t *testing.T) {
 	}
 }
 
+func TestGetSharedCoffees_FlavorCategories(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedLink("user-123", "valid-token", time.Now())
+	repo.seedCoffees("user-123", []ShareCoffee{
+		{Name: "Kiamaina", TastingNotes: strPtr("Apricot Nectar, Lemon Sorbet, Raw Honey")},
+		{Name: "Gesha Village"},
+	})
+	h := NewHandler(repo, testBaseURL, testSecret)
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/valid-token", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if strings.Contains(w.Body.String(), "flavor_categories") {
+		t.Errorf("expected no flavor categories unless requested, got %s", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/share/valid-token?flavor_categories=true", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Items []ShareCoffee `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+
+	got := resp.Items[0].FlavorCategories
+	if len(got) != 2 || got[0] != "Fruity" || got[1] != "Sweet" {
+		t.Errorf("expected [Fruity Sweet], got %v", got)
+	}
+	if resp.Items[1].FlavorCategories != nil {
+		t.Errorf("expected no categories without tasting notes, got %v", resp.Items[1].FlavorCategories)
+	}
+}
+
 func TestGetSharedCoffees_EmptyCoffeeList(t *testing.T) {
 	repo := newMockRepo()
-	repo.tokens["user-123"] = &mockTokenData{token: "valid-token", createdAt: time.Now()}
+	repo.seedLink("user-123", "valid-token", time.Now())
 	// No coffees seeded
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/valid-token", nil)
@@ -470,11 +783,11 @@ func TestGetSharedCoffees_EmptyCoffeeList(t *testing.T) {
 
 func TestGetSharedCoffees_NoAuthRequired(t *testing.T) {
 	repo := newMockRepo()
-	repo.tokens["user-123"] = &mockTokenData{token: "public-token", createdAt: time.Now()}
-	repo.coffees["user-123"] = []ShareCoffee{
+	repo.seedLink("user-123", "public-token", time.Now())
+	repo.seedCoffees("user-123", []ShareCoffee{
 		{Name: "Test Coffee", Roaster: strPtr("Test Roaster")},
-	}
-	h := NewHandler(repo, testBaseURL)
+	})
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	// No auth header — should still work
@@ -490,7 +803,7 @@ func TestGetSharedCoffees_NoAuthRequired(t *testing.T) {
 func TestGetSharedCoffees_RevokedToken(t *testing.T) {
 	repo := newMockRepo()
 	// Token was set then revoked (not in map)
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/revoked-token", nil)
@@ -503,7 +816,7 @@ func TestGetSharedCoffees_RevokedToken(t *testing.T) {
 }
 
 func TestGetSharedCoffees_DatabaseError_TokenLookup(t *testing.T) {
-	h := NewHandler(&errorRepo{}, testBaseURL)
+	h := NewHandler(&errorRepo{}, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/some-token", nil)
@@ -518,7 +831,7 @@ func TestGetSharedCoffees_DatabaseError_TokenLookup(t *testing.T) {
 // --- URL Construction Tests ---
 
 func TestBuildURL(t *testing.T) {
-	h := NewHandler(nil, "https://brew.example.com")
+	h := NewHandler(nil, "https://brew.example.com", testSecret)
 	url := h.buildURL("abc123def456")
 	expected := "https://brew.example.com/share/abc123def456"
 	if url != expected {
@@ -527,7 +840,7 @@ func TestBuildURL(t *testing.T) {
 }
 
 func TestBuildURL_LocalhostBaseURL(t *testing.T) {
-	h := NewHandler(nil, "http://localhost:5173")
+	h := NewHandler(nil, "http://localhost:5173", testSecret)
 	url := h.buildURL("token123")
 	expected := "http://localhost:5173/share/token123"
 	if url != expected {
@@ -539,7 +852,7 @@ func TestBuildURL_LocalhostBaseURL(t *testing.T) {
 
 func TestFullFlow_CreateThenGetThenRevoke(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	// 1. Create share link
@@ -611,7 +924,7 @@ func TestFullFlow_CreateThenGetThenRevoke(t *testing.T) {
 
 func TestCreateShareLink_TokenIs32HexChars(t *testing.T) {
 	repo := newMockRepo()
-	h := NewHandler(repo, testBaseURL)
+	h := NewHandler(repo, testBaseURL, testSecret)
 	router := setupRouter(h)
 
 	// Create multiple tokens and verify they're all valid hex
@@ -638,3 +951,1095 @@ func TestCreateShareLink_TokenIs32HexChars(t *testing.T) {
 		}
 	}
 }
+
+// --- Share Links Tests ---
+
+// sharedNames fetches the public page for token and returns the coffee names.
+func sharedNames(t *testing.T, router http.Handler, token string) []string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/share/"+token, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("share page: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Items []ShareCoffee `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	names := []string{}
+	for _, c := range resp.Items {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func TestCreateLink_DefaultsToAll(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo, testBaseURL, testSecret)
+	router := setupRouter(h)
+
+	req := authJSONRequest(http.MethodPost, "/api/v1/share-links", `{"label": "  Friends  "}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var l Link
+	json.Unmarshal(w.Body.Bytes(), &l)
+	if l.Label != "Friends" || l.Scope != ScopeAll || l.Status != StatusActive {
+		t.Errorf("expected active all-scope link labelled Friends, got %+v", l)
+	}
+	if len(l.Token) != 32 || l.URL != testBaseURL+"/share/"+l.Token {
+		t.Errorf("expected 32-char token and matching url, got %q %q", l.Token, l.URL)
+	}
+	if !strings.Contains(w.Body.String(), `"coffee_ids":[]`) || !strings.Contains(w.Body.String(), `"filter":null`) {
+		t.Errorf("expected empty coffee_ids and null filter, got %s", w.Body.String())
+	}
+}
+
+func TestCreateLink_CoffeesScope(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedCoffees("user-123", []ShareCoffee{{Name: "Kiamaina"}, {Name: "Gesha Village"}, {Name: "Daterra"}})
+	h := NewHandler(repo, testBaseURL, testSecret)
+	router := setupRouter(h)
+
+	body := `{"label": "For Sam", "scope": "coffees", "coffee_ids": ["c-3", "c-1", "c-3"]}`
+	req := authJSONRequest(http.MethodPost, "/api/v1/share-links", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var l Link
+	json.Unmarshal(w.Body.Bytes(), &l)
+	if len(l.CoffeeIDs) != 2 {
+		t.Errorf("expected repeated coffee IDs dropped, got %v", l.CoffeeIDs)
+	}
+
+	names := sharedNames(t, router, l.Token)
+	if len(names) != 2 || names[0] != "Kiamaina" || names[1] != "Daterra" {
+		t.Errorf("expected only the chosen coffees, got %v", names)
+	}
+}
+
+func TestCreateLink_FilterScope(t *testing.T) {
+	repo := newMockRepo()
+	repo.roasters["r-1"] = "user-123"
+	repo.coffees["user-123"] = []mockCoffee{
+		{id: "c-1", roasterID: "r-1", ShareCoffee: ShareCoffee{Name: "Kiamaina", Country: strPtr("Kenya")}},
+		{id: "c-2", roasterID: "r-1", ShareCoffee: ShareCoffee{Name: "Guji", Country: strPtr("Ethiopia")}},
+		{id: "c-3", roasterID: "r-2", ShareCoffee: ShareCoffee{Name: "Gatomboya", Country: strPtr("Kenya")}},
+	}
+	h := NewHandler(repo, testBaseURL, testSecret)
+	router := setupRouter(h)
+
+	body := `{"label": "Cata Kenyans", "scope": "filter", "filter": {"roaster_id": "r-1", "country": " Kenya ", "process": ""}}`
+	req := authJSONRequest(http.MethodPost, "/api/v1/share-links", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var l Link
+	json.Unmarshal(w.Body.Bytes(), &l)
+	if l.Filter == nil || l.Filter.Country == nil || *l.Filter.Country != "Kenya" || l.Filter.Process != nil {
+		t.Errorf("expected trimmed country and no process, got %+v", l.Filter)
+	}
+
+	names := sharedNames(t, router, l.Token)
+	if len(names) != 1 || names[0] != "Kiamaina" {
+		t.Errorf("expected only Kiamaina, got %v", names)
+	}
+}
+
+func TestCreateLink_ValidationErrors(t *testing.T) {
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"missing label", `{"label": "  "}`, "label"},
+		{"long label", `{"label": "` + strings.Repeat("a", MaxLabelLength+1) + `"}`, "label"},
+		{"unknown scope", `{"label": "x", "scope": "roaster"}`, "scope"},
+		{"coffees without IDs", `{"label": "x", "scope": "coffees"}`, "coffee_ids"},
+		{"IDs outside coffees scope", `{"label": "x", "coffee_ids": ["c-1"]}`, "coffee_ids"},
+		{"filter scope without filter", `{"label": "x", "scope": "filter", "filter": {"country": " "}}`, "filter"},
+		{"filter outside filter scope", `{"label": "x", "filter": {"country": "Kenya"}}`, "filter"},
+		{"expiry in the past", `{"label": "x", "expires_at": "` + past + `"}`, "expires_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRouter(NewHandler(newMockRepo(), testBaseURL, testSecret))
+
+			req := authJSONRequest(http.MethodPost, "/api/v1/share-links", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), `"field":"`+tt.field+`"`) {
+				t.Errorf("expected an error on %s, got %s", tt.field, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateLink_UnknownReferences(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedCoffees("other-user", []ShareCoffee{{Name: "Not yours"}})
+	repo.roasters["r-9"] = "other-user"
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	for _, body := range []string{
+		`{"label": "x", "scope": "coffees", "coffee_ids": ["c-1"]}`,
+		`{"label": "x", "scope": "filter", "filter": {"roaster_id": "r-9"}}`,
+	} {
+		req := authJSONRequest(http.MethodPost, "/api/v1/share-links", body)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected 422, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestListLinks_Statuses(t *testing.T) {
+	repo := newMockRepo()
+	now := time.Now()
+	repo.seedLink("user-123", "active-token", now.Add(-3*time.Hour))
+	expired := repo.seedLink("user-123", "expired-token", now.Add(-2*time.Hour))
+	expired.ExpiresAt = &now
+	revoked := repo.seedLink("user-123", "revoked-token", now.Add(-time.Hour))
+	revoked.RevokedAt = &now
+	repo.seedLink("other-user", "other-token", now)
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := authRequest(http.MethodGet, "/api/v1/share-links")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var resp struct {
+		Items []Link `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	var statuses []string
+	for _, l := range resp.Items {
+		statuses = append(statuses, l.Status)
+	}
+	if strings.Join(statuses, ",") != "revoked,expired,active" {
+		t.Errorf("expected the user's links newest first, got %v", statuses)
+	}
+}
+
+func TestGetLink_OtherUsersLink(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("other-user", "other-token", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := authRequest(http.MethodGet, "/api/v1/share-links/"+l.ID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestUpdateLink_ChangesScopeKeepsToken(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedCoffees("user-123", []ShareCoffee{{Name: "Kiamaina"}, {Name: "Guji"}})
+	l := repo.seedLink("user-123", "same-token", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	expires := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	body := `{"label": "Just Guji", "scope": "coffees", "coffee_ids": ["c-2"], "expires_at": "` + expires + `"}`
+	req := authJSONRequest(http.MethodPut, "/api/v1/share-links/"+l.ID, body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var updated Link
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	if updated.Token != "same-token" || updated.Label != "Just Guji" || updated.ExpiresAt == nil {
+		t.Errorf("expected relabelled link with expiry and the same token, got %+v", updated)
+	}
+
+	names := sharedNames(t, router, "same-token")
+	if len(names) != 1 || names[0] != "Guji" {
+		t.Errorf("expected only Guji, got %v", names)
+	}
+}
+
+func TestUpdateLink_LabelOnlyKeepsVisibility(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "tok", time.Now())
+	l.Visibility.Score = false
+	l.Visibility.Recipe = true
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := authJSONRequest(http.MethodPut, "/api/v1/share-links/"+l.ID, `{"label": "Renamed", "scope": "all"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var updated Link
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	want := DefaultVisibility()
+	want.Score = false
+	want.Recipe = true
+	if updated.Visibility != want {
+		t.Errorf("expected visibility %+v to be kept, got %+v", want, updated.Visibility)
+	}
+}
+
+func TestUpdateLink_VisibilityChangesOnlyNamedGroups(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "tok", time.Now())
+	l.Visibility.Score = false
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := authJSONRequest(http.MethodPut, "/api/v1/share-links/"+l.ID, `{"label": "Renamed", "scope": "all", "visibility": {"sensory": false}}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var updated Link
+	json.Unmarshal(w.Body.Bytes(), &updated)
+	if updated.Visibility.Sensory || updated.Visibility.Score || !updated.Visibility.Origin {
+		t.Errorf("expected sensory and score hidden, origin shown, got %+v", updated.Visibility)
+	}
+}
+
+func TestUpdateLink_Revoked(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "revoked-token", time.Now())
+	now := time.Now()
+	l.RevokedAt = &now
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := authJSONRequest(http.MethodPut, "/api/v1/share-links/"+l.ID, `{"label": "Again"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestUpdateLink_NotFound(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo(), testBaseURL, testSecret))
+
+	req := authJSONRequest(http.MethodPut, "/api/v1/share-links/missing", `{"label": "x"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestRevokeLink(t *testing.T) {
+	repo := newMockRepo()
+	keep := repo.seedLink("user-123", "keep-token", time.Now().Add(-time.Hour))
+	l := repo.seedLink("user-123", "revoke-token", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := authRequest(http.MethodDelete, "/api/v1/share-links/"+l.ID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/share/revoke-token", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected revoked token to 404, got %d", w.Code)
+	}
+	sharedNames(t, router, keep.Token)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-links/"+l.ID))
+	var got Link
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if got.Status != StatusRevoked || got.RevokedAt == nil {
+		t.Errorf("expected the link kept as revoked, got %+v", got)
+	}
+}
+
+func TestRevokeLink_NotFound(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("other-user", "other-token", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := authRequest(http.MethodDelete, "/api/v1/share-links/"+l.ID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+	if repo.links[l.ID].RevokedAt != nil {
+		t.Error("expected another user's link to stay active")
+	}
+}
+
+func TestListLinks_DatabaseError(t *testing.T) {
+	router := setupRouter(NewHandler(&errorRepo{}, testBaseURL, testSecret))
+
+	req := authRequest(http.MethodGet, "/api/v1/share-links")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestGetSharedCoffees_ExpiredToken(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "expired-token", time.Now().Add(-48*time.Hour))
+	expiresAt := time.Now().Add(-time.Minute)
+	l.ExpiresAt = &expiresAt
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/expired-token", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for expired token, got %d", w.Code)
+	}
+}
+
+func TestShareLink_IgnoresScopedLinks(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedCoffees("user-123", []ShareCoffee{{Name: "Kiamaina"}})
+	scoped := repo.seedLink("user-123", "scoped-token", time.Now())
+	scoped.Scope = ScopeCoffees
+	scoped.CoffeeIDs = []string{"c-1"}
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-link"))
+	var resp ShareLink
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Token != nil {
+		t.Errorf("expected no default link, got %v", *resp.Token)
+	}
+
+	// Regenerating and revoking the default link leaves other links alone.
+	router.ServeHTTP(httptest.NewRecorder(), authRequest(http.MethodPost, "/api/v1/share-link"))
+	router.ServeHTTP(httptest.NewRecorder(), authRequest(http.MethodDelete, "/api/v1/share-link"))
+	if scoped.RevokedAt != nil {
+		t.Error("expected the scoped link to stay active")
+	}
+}
+
+// --- Visibility Tests ---
+
+func seedRecipeCoffee(repo *mockRepo) {
+	repo.seedCoffees("user-123", []ShareCoffee{{
+		Name:         "Kiamaina",
+		Roaster:      strPtr("Cata Coffee"),
+		Country:      strPtr("Kenya"),
+		Region:       strPtr("Nyeri"),
+		Process:      strPtr("Washed"),
+		RoastLevel:   strPtr("Light"),
+		TastingNotes: strPtr("Blackcurrant, Lemon"),
+		RoastDate:    strPtr("2025-11-19"),
+		ReferenceBrew: &ShareReferenceBrew{
+			OverallScore:   intPtr(8),
+			AromaIntensity: intPtr(7),
+			Recipe: &ShareRecipe{
+				Method:  "pour_over",
+				Ratio:   floatPtr(16),
+				Dripper: strPtr("V60"),
+				Pours:   []SharePour{{PourNumber: 1, WaterAmount: floatPtr(50)}},
+			},
+		},
+	}})
+}
+
+func floatPtr(f float64) *float64 { return &f }
+
+// sharedCoffee fetches the public page for token and returns its first
+// coffee.
+func sharedCoffee(t *testing.T, router http.Handler, url string) ShareCoffee {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("share page: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Items []ShareCoffee `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 {
+		t.Fatalf("expected 1 coffee, got %d", len(resp.Items))
+	}
+	return resp.Items[0]
+}
+
+func TestGetSharedCoffees_DefaultVisibilityHidesRecipe(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipeCoffee(repo)
+	repo.seedLink("user-123", "valid-token", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	c := sharedCoffee(t, router, "/api/v1/share/valid-token")
+	if c.Country == nil || c.TastingNotes == nil || c.RoastDate == nil {
+		t.Errorf("expected origin, notes and roast date shown, got %+v", c)
+	}
+	if c.ReferenceBrew == nil || c.ReferenceBrew.OverallScore == nil || c.ReferenceBrew.AromaIntensity == nil {
+		t.Fatalf("expected score and sensory shown, got %+v", c.ReferenceBrew)
+	}
+	if c.ReferenceBrew.Recipe != nil {
+		t.Errorf("expected recipe hidden by default, got %+v", c.ReferenceBrew.Recipe)
+	}
+}
+
+func TestCreateLink_RecipeWithoutScores(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipeCoffee(repo)
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	body := `{"label": "Cafe partners", "visibility": {"recipe": true, "score": false}}`
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authJSONRequest(http.MethodPost, "/api/v1/share-links", body))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var l Link
+	json.Unmarshal(w.Body.Bytes(), &l)
+	want := DefaultVisibility()
+	want.Recipe = true
+	want.Score = false
+	if l.Visibility != want {
+		t.Errorf("expected unset groups to keep their defaults, got %+v", l.Visibility)
+	}
+
+	c := sharedCoffee(t, router, "/api/v1/share/"+l.Token)
+	ref := c.ReferenceBrew
+	if ref == nil || ref.Recipe == nil {
+		t.Fatalf("expected the recipe shown, got %+v", ref)
+	}
+	if ref.Recipe.Dripper == nil || *ref.Recipe.Dripper != "V60" || len(ref.Recipe.Pours) != 1 {
+		t.Errorf("expected dripper and pours, got %+v", ref.Recipe)
+	}
+	if ref.OverallScore != nil {
+		t.Errorf("expected overall score hidden, got %d", *ref.OverallScore)
+	}
+	if ref.AromaIntensity == nil {
+		t.Error("expected sensory profile still shown")
+	}
+}
+
+func TestGetSharedCoffees_HidesEveryGroup(t *testing.T) {
+	repo := newMockRepo()
+	seedRecipeCoffee(repo)
+	l := repo.seedLink("user-123", "bare-token", time.Now())
+	l.Visibility = Visibility{}
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/share/bare-token?flavor_categories=true", nil))
+	if strings.Contains(w.Body.String(), "flavor_categories") {
+		t.Errorf("expected hidden tasting notes to hide flavor categories, got %s", w.Body.String())
+	}
+
+	c := sharedCoffee(t, router, "/api/v1/share/bare-token")
+	if c.Country != nil || c.Region != nil || c.Process != nil || c.RoastDate != nil || c.TastingNotes != nil {
+		t.Errorf("expected origin, roast date and notes hidden, got %+v", c)
+	}
+	if c.ReferenceBrew != nil {
+		t.Errorf("expected no reference brew, got %+v", c.ReferenceBrew)
+	}
+	if c.Name != "Kiamaina" || c.Roaster == nil || c.RoastLevel == nil {
+		t.Errorf("expected name, roaster and roast level always shown, got %+v", c)
+	}
+
+	// The policy must not leak into the stored coffee.
+	if repo.coffees["user-123"][0].ReferenceBrew.OverallScore == nil {
+		t.Error("expected the repository's reference brew left intact")
+	}
+}
+
+// --- Analytics Tests ---
+
+func TestGetSharedCoffees_RecordsView(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "viewed-token", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/viewed-token?ref=https://www.Instagram.com/p/abc", nil)
+	req.RemoteAddr = "203.0.113.7:52100"
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	if len(repo.views) != 1 || repo.links[l.ID].ViewCount != 1 {
+		t.Fatalf("expected one view recorded, got %+v", repo.views)
+	}
+	v := repo.views[0]
+	if v.UAFamily != "Safari" {
+		t.Errorf("expected Safari, got %q", v.UAFamily)
+	}
+	if v.Referrer == nil || *v.Referrer != "www.instagram.com" {
+		t.Errorf("expected the referrer host only, got %v", v.Referrer)
+	}
+	if len(v.IPHash) != 64 || strings.Contains(v.IPHash, "203.0.113.7") {
+		t.Errorf("expected a hex hash of the IP, got %q", v.IPHash)
+	}
+}
+
+func TestGetSharedCoffees_InactiveLinkRecordsNoView(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "revoked-token", time.Now())
+	now := time.Now()
+	l.RevokedAt = &now
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/share/revoked-token", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if len(repo.views) != 0 {
+		t.Errorf("expected no view recorded, got %+v", repo.views)
+	}
+}
+
+func TestAnalytics(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "tok", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	for _, addr := range []string{"198.51.100.1:1000", "198.51.100.1:2000", "198.51.100.2:1000"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/share/tok", nil)
+		req.RemoteAddr = addr
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-links/"+l.ID+"/analytics"))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var a Analytics
+	json.Unmarshal(w.Body.Bytes(), &a)
+	if a.TotalViews != 3 || a.Views != 3 || a.UniqueVisitors != 2 {
+		t.Errorf("expected 3 views from 2 visitors, got %+v", a)
+	}
+
+	// Defaults: daily buckets over the last 30 days, in UTC.
+	today := time.Now().UTC()
+	want := AnalyticsParams{
+		Bucket:   BucketDay,
+		DateFrom: today.AddDate(0, 0, -29).Format("2006-01-02"),
+		DateTo:   today.Format("2006-01-02"),
+	}
+	if repo.analyticsParams != want {
+		t.Errorf("expected params %+v, got %+v", want, repo.analyticsParams)
+	}
+}
+
+func TestAnalytics_InvalidParams(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "tok", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	for _, query := range []string{
+		"bucket=year",
+		"date_from=01/02/2026",
+		"date_from=2026-03-01&date_to=2026-02-01",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-links/"+l.ID+"/analytics?"+query))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
+
+func TestAnalytics_RangeTooLarge(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "tok", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-links/"+l.ID+"/analytics?date_from=0001-01-01&date_to=2026-03-01"))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp api.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Error.Code != api.CodeRangeTooLarge || len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "date_from" {
+		t.Errorf("expected RANGE_TOO_LARGE on date_from, got %+v", resp.Error)
+	}
+
+	// The same span is fine in monthly buckets.
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-links/"+l.ID+"/analytics?bucket=month&date_from=2000-01-01&date_to=2026-03-01"))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for a monthly series, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAnalytics_OtherUsersLink(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("other-user", "tok", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-links/"+l.ID+"/analytics"))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestAnalytics_DatabaseError(t *testing.T) {
+	router := setupRouter(NewHandler(&errorRepo{}, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-links/link-1/analytics"))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestViewPurger_RunOnce(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "tok", time.Now())
+	repo.views = []mockView{
+		{linkID: l.ID, viewedAt: time.Now().AddDate(0, 0, -100)},
+		{linkID: l.ID, viewedAt: time.Now().AddDate(0, 0, -10)},
+	}
+
+	n, err := NewViewPurger(repo, 90*24*time.Hour, time.Hour).RunOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || len(repo.views) != 1 {
+		t.Errorf("expected the 100-day-old view purged, got %d purged and %d left", n, len(repo.views))
+	}
+}
+
+func TestUAFamily(t *testing.T) {
+	tests := []struct{ ua, want string }{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36", "Chrome"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51", "Edge"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.4; rv:125.0) Gecko/20100101 Firefox/125.0", "Firefox"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/124.0.6367.88 Mobile/15E148 Safari/604.1", "Chrome"},
+		{"Mozilla/5.0 (Linux; Android 14; SM-S918B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/24.0 Chrome/117.0.0.0 Mobile Safari/537.36", "Samsung Internet"},
+		{"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", "Bot"},
+		{"WhatsApp/2.23.20.0", "Bot"},
+		{"curl/8.4.0", "Other"},
+		{"", "Other"},
+	}
+	for _, tt := range tests {
+		if got := uaFamily(tt.ua); got != tt.want {
+			t.Errorf("uaFamily(%q) = %q, want %q", tt.ua, got, tt.want)
+		}
+	}
+}
+
+func TestReferrerHost(t *testing.T) {
+	if got := referrerHost("https://t.co/abc?x=1", "brewlab.example.com"); got == nil || *got != "t.co" {
+		t.Errorf("expected t.co, got %v", got)
+	}
+	for _, ref := range []string{"", "not a url", "https://brewlab.example.com/share/abc"} {
+		if got := referrerHost(ref, "brewlab.example.com"); got != nil {
+			t.Errorf("referrerHost(%q) = %q, want nil", ref, *got)
+		}
+	}
+}
+
+func TestHashIP(t *testing.T) {
+	key := []byte("key")
+	if hashIP(key, "203.0.113.7:1000") != hashIP(key, "203.0.113.7:2000") {
+		t.Error("expected the port ignored")
+	}
+	if hashIP(key, "203.0.113.7") != hashIP(key, "203.0.113.7:1000") {
+		t.Error("expected a bare IP, as set by RealIP, to hash the same")
+	}
+	if hashIP(key, "203.0.113.7") == hashIP([]byte("other"), "203.0.113.7") {
+		t.Error("expected the hash to depend on the key")
+	}
+}
+
+// --- Passphrase Tests ---
+
+// protect sets a passphrase on l, hashed at the minimum cost to keep tests
+// fast.
+func protect(l *Link, passphrase string) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte(passphrase), bcrypt.MinCost)
+	s := string(hash)
+	l.PassphraseHash = &s
+}
+
+func unlock(router http.Handler, token, passphrase string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/share/"+token+"/unlock",
+		strings.NewReader(fmt.Sprintf(`{"passphrase": %q}`, passphrase)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// viewerToken unlocks the link and returns the viewer token.
+func viewerToken(t *testing.T, router http.Handler, token, passphrase string) string {
+	t.Helper()
+	w := unlock(router, token, passphrase)
+	if w.Code != http.StatusOK {
+		t.Fatalf("unlock: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp UnlockResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp.ViewerToken
+}
+
+func viewRequest(token, bearer string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/share/"+token, nil)
+	if bearer != "" {
+		req.Header.Set("Authorization", "Bearer "+bearer)
+	}
+	return req
+}
+
+func TestCreateLink_Passphrase(t *testing.T) {
+	repo := newMockRepo()
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authJSONRequest(http.MethodPost, "/api/v1/share-links",
+		`{"label": "Wholesale", "passphrase": "pre-release"}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "$2a$") || strings.Contains(w.Body.String(), "pre-release") {
+		t.Errorf("expected neither passphrase nor hash in the response, got %s", w.Body.String())
+	}
+
+	var l Link
+	json.Unmarshal(w.Body.Bytes(), &l)
+	if !l.HasPassphrase {
+		t.Error("expected has_passphrase")
+	}
+	hash := repo.links[l.ID].PassphraseHash
+	if hash == nil || bcrypt.CompareHashAndPassword([]byte(*hash), []byte("pre-release")) != nil {
+		t.Error("expected a bcrypt hash of the passphrase stored")
+	}
+}
+
+func TestCreateLink_PassphraseValidation(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo(), testBaseURL, testSecret))
+
+	for _, passphrase := range []string{"short", strings.Repeat("x", MaxPassphraseBytes+1)} {
+		body := fmt.Sprintf(`{"label": "Wholesale", "passphrase": %q}`, passphrase)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, authJSONRequest(http.MethodPost, "/api/v1/share-links", body))
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "passphrase") {
+			t.Errorf("%d chars: expected 400 on passphrase, got %d: %s", len(passphrase), w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestGetSharedCoffees_PassphraseRequired(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedCoffees("user-123", []ShareCoffee{{Name: "Kiamaina"}})
+	protect(repo.seedLink("user-123", "locked-token", time.Now()), "pre-release")
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, viewRequest("locked-token", ""))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "Kiamaina") {
+		t.Error("expected no coffees before unlocking")
+	}
+	if len(repo.views) != 0 {
+		t.Error("expected no view recorded for a locked page")
+	}
+
+	bearer := viewerToken(t, router, "locked-token", "pre-release")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, viewRequest("locked-token", bearer))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Kiamaina") {
+		t.Fatalf("expected the page with a viewer token, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestGetSharedCoffees_RejectsForeignTokens(t *testing.T) {
+	repo := newMockRepo()
+	protect(repo.seedLink("user-123", "locked-token", time.Now()), "pre-release")
+	protect(repo.seedLink("user-123", "other-token", time.Now()), "pre-release")
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	otherLink := viewerToken(t, router, "other-token", "pre-release")
+	otherServer := viewerToken(t, setupRouter(NewHandler(repo, testBaseURL, "another-secret")), "locked-token", "pre-release")
+	for name, bearer := range map[string]string{
+		"another link's token":       otherLink,
+		"a token from another key":   otherServer,
+		"the owner's access token":   generateTestAccessToken("user-123"),
+		"a token that isn't a token": "not-a-jwt",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, viewRequest("locked-token", bearer))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, w.Code)
+		}
+	}
+}
+
+func TestUnlock_Errors(t *testing.T) {
+	repo := newMockRepo()
+	protect(repo.seedLink("user-123", "locked-token", time.Now()), "pre-release")
+	repo.seedLink("user-123", "open-token", time.Now())
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	tests := []struct {
+		name, token, passphrase string
+		want                    int
+	}{
+		{"wrong passphrase", "locked-token", "post-release", http.StatusUnauthorized},
+		{"missing passphrase", "locked-token", "", http.StatusBadRequest},
+		{"unknown link", "no-such-token", "pre-release", http.StatusNotFound},
+		{"open link", "open-token", "pre-release", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if w := unlock(router, tt.token, tt.passphrase); w.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, w.Code)
+		}
+	}
+}
+
+func TestUpdateLink_Passphrase(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "locked-token", time.Now())
+	protect(l, "pre-release")
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+	bearer := viewerToken(t, router, "locked-token", "pre-release")
+
+	update := func(body string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, authJSONRequest(http.MethodPut, "/api/v1/share-links/"+l.ID, body))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	view := func() int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, viewRequest("locked-token", bearer))
+		return w.Code
+	}
+
+	// Leaving the passphrase out keeps it, and viewers stay unlocked.
+	update(`{"label": "Renamed"}`)
+	if repo.links[l.ID].PassphraseHash == nil || view() != http.StatusOK {
+		t.Fatal("expected the passphrase and viewer token kept")
+	}
+
+	// A new passphrase locks out earlier viewers.
+	update(`{"label": "Renamed", "passphrase": "second-batch"}`)
+	if view() != http.StatusUnauthorized {
+		t.Error("expected the old viewer token rejected")
+	}
+
+	// An empty passphrase opens the link.
+	update(`{"label": "Renamed", "passphrase": ""}`)
+	if repo.links[l.ID].PassphraseHash != nil {
+		t.Error("expected the passphrase removed")
+	}
+	bearer = ""
+	if view() != http.StatusOK {
+		t.Error("expected the open link viewable without a token")
+	}
+}
+
+func TestGetShareLink_IgnoresProtectedLinks(t *testing.T) {
+	repo := newMockRepo()
+	protect(repo.seedLink("user-123", "locked-token", time.Now()), "pre-release")
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, authRequest(http.MethodGet, "/api/v1/share-link"))
+	if strings.Contains(w.Body.String(), "locked-token") {
+		t.Errorf("expected a protected link not to be the default, got %s", w.Body.String())
+	}
+}
+
+// --- Link Preview Tests ---
+
+func TestPage(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedLink("user-123", "page-token", time.Now())
+	repo.seedCoffees("user-123", []ShareCoffee{
+		{Name: "Kiamaina", Roaster: strPtr("Cata Coffee")},
+		{Name: "Guji <Natural>"},
+		{Name: "Gesha Village", Roaster: strPtr("Manhattan")},
+		{Name: "Finca Deborah"},
+	})
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/share/page-token", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("expected HTML, got %q", ct)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		`<meta property="og:title" content="Shared coffees · Brew Lab">`,
+		`<meta property="og:description" content="4 coffees: Kiamaina by Cata Coffee, Guji &lt;Natural&gt;, Gesha Village by Manhattan and 1 more.">`,
+		`<meta property="og:url" content="` + testBaseURL + `/share/page-token">`,
+		`<meta property="og:image" content="` + testBaseURL + `/api/v1/share/page-token/image.png">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`<li>Finca Deborah</li>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in:\n%s", want, body)
+		}
+	}
+	if len(repo.views) != 0 {
+		t.Error("expected the preview page not to record a view")
+	}
+}
+
+func TestPage_ProtectedLink(t *testing.T) {
+	repo := newMockRepo()
+	protect(repo.seedLink("user-123", "locked-token", time.Now()), "pre-release")
+	repo.seedCoffees("user-123", []ShareCoffee{{Name: "Pre-release Gesha"}})
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/share/locked-token", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "Pre-release Gesha") {
+		t.Error("expected a protected link's coffees left out of the preview")
+	}
+	if !strings.Contains(w.Body.String(), "protected by a passphrase") {
+		t.Errorf("expected the passphrase notice, got %s", w.Body.String())
+	}
+}
+
+func TestPage_InactiveLink(t *testing.T) {
+	repo := newMockRepo()
+	l := repo.seedLink("user-123", "revoked-token", time.Now())
+	now := time.Now()
+	l.RevokedAt = &now
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/share/revoked-token", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "og:") {
+		t.Errorf("expected no Open Graph tags, got %s", w.Body.String())
+	}
+}
+
+func TestPage_DatabaseError(t *testing.T) {
+	router := setupRouter(NewHandler(&errorRepo{}, testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/share/any-token", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestImage(t *testing.T) {
+	repo := newMockRepo()
+	repo.seedLink("user-123", "image-token", time.Now())
+	repo.seedCoffees("user-123", []ShareCoffee{
+		{Name: "Kiamaina", Roaster: strPtr("Cata Coffee")},
+		{Name: strings.Repeat("A very long coffee name ", 10)},
+		{Name: "Guji"}, {Name: "Sidama"}, {Name: "Huila"},
+	})
+	protect(repo.seedLink("user-123", "locked-token", time.Now()), "pre-release")
+	router := setupRouter(NewHandler(repo, testBaseURL, testSecret))
+
+	for _, token := range []string{"image-token", "locked-token"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/share/"+token+"/image.png", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d", token, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "image/png" {
+			t.Errorf("%s: expected a PNG, got %q", token, ct)
+		}
+		img, err := png.Decode(w.Body)
+		if err != nil {
+			t.Fatalf("%s: decoding PNG: %v", token, err)
+		}
+		if b := img.Bounds(); b.Dx() != CardWidth || b.Dy() != CardHeight {
+			t.Errorf("%s: expected %dx%d, got %v", token, CardWidth, CardHeight, b)
+		}
+	}
+}
+
+func TestImage_InactiveLink(t *testing.T) {
+	router := setupRouter(NewHandler(newMockRepo(), testBaseURL, testSecret))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/share/no-such-token/image.png", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestPreviewDescription(t *testing.T) {
+	tests := []struct {
+		p    preview
+		want string
+	}{
+		{preview{}, "No coffees are shared right now."},
+		{preview{Coffees: []ShareCoffee{{Name: "Guji"}}}, "1 coffee: Guji."},
+		{preview{Coffees: []ShareCoffee{{Name: "Guji"}, {Name: "Huila", Roaster: strPtr("Onyx")}}}, "2 coffees: Guji and Huila by Onyx."},
+		{preview{Protected: true}, "This coffee collection is protected by a passphrase."},
+	}
+	for _, tt := range tests {
+		if got := tt.p.description(); got != tt.want {
+			t.Errorf("description() = %q, want %q", got, tt.want)
+		}
+	}
+}
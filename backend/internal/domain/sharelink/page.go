@@ -0,0 +1,205 @@
+package sharelink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+)
+
+const siteName = "Brew Lab"
+
+// previewCoffees is how many coffees the page description names.
+const previewCoffees = 3
+
+// preview is what link previews may show of a share page. The coffees of a
+// protected link are left out.
+type preview struct {
+	Protected bool
+	Coffees   []ShareCoffee
+}
+
+// loadPreview returns ErrNotFound for unknown, revoked and expired tokens.
+// The page and image don't record views: they are fetched by link
+// preview crawlers rather than people.
+func (h *Handler) loadPreview(ctx context.Context, token string) (*preview, error) {
+	link, err := h.repo.GetActiveByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if link.PassphraseHash != nil {
+		return &preview{Protected: true}, nil
+	}
+
+	coffees, err := h.repo.GetSharedCoffees(ctx, link.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range coffees {
+		link.Visibility.Apply(&coffees[i])
+	}
+	return &preview{Coffees: coffees}, nil
+}
+
+func coffeeCount(n int) string {
+	if n == 1 {
+		return "1 coffee"
+	}
+	return fmt.Sprintf("%d coffees", n)
+}
+
+// description names the first few coffees, e.g. "3 coffees: Kiamaina by
+// Cata Coffee, Guji and 1 more."
+func (p *preview) description() string {
+	if p.Protected {
+		return "This coffee collection is protected by a passphrase."
+	}
+	if len(p.Coffees) == 0 {
+		return "No coffees are shared right now."
+	}
+
+	var names []string
+	for i, c := range p.Coffees {
+		if i == previewCoffees {
+			names = append(names, fmt.Sprintf("%d more", len(p.Coffees)-previewCoffees))
+			break
+		}
+		if c.Roaster != nil {
+			names = append(names, c.Name+" by "+*c.Roaster)
+		} else {
+			names = append(names, c.Name)
+		}
+	}
+	list := names[0]
+	if len(names) > 1 {
+		list = strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+	}
+	return coffeeCount(len(p.Coffees)) + ": " + list + "."
+}
+
+type pageData struct {
+	Title       string
+	Description string
+	URL         string
+	ImageURL    string
+	Coffees     []ShareCoffee
+	SiteName    string
+	Width       int
+	Height      int
+}
+
+var pageTemplate = template.Must(template.New("share").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+{{- if .URL}}
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="{{.SiteName}}">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:image" content="{{.ImageURL}}">
+<meta property="og:image:type" content="image/png">
+<meta property="og:image:width" content="{{.Width}}">
+<meta property="og:image:height" content="{{.Height}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta name="twitter:image" content="{{.ImageURL}}">
+{{- end}}
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{.Description}}</p>
+{{- if .Coffees}}
+<ul>
+{{- range .Coffees}}
+<li>{{.Name}}{{with .Roaster}} · {{.}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .URL}}
+<p><a href="{{.URL}}">Open in {{.SiteName}}</a></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// Page renders a minimal HTML share page with Open Graph and Twitter card
+// tags, for chat apps and social sites that don't run the frontend.
+func (h *Handler) Page(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	data := pageData{SiteName: siteName, Width: CardWidth, Height: CardHeight}
+	status := http.StatusOK
+
+	p, err := h.loadPreview(r.Context(), token)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+		data.Title = siteName
+		data.Description = "This share link is no longer active."
+	case err != nil:
+		log.Printf("error loading share page: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	default:
+		data.Title = "Shared coffees · " + siteName
+		data.Description = p.description()
+		data.URL = h.buildURL(token)
+		data.ImageURL = h.baseURL + "/api/v1/share/" + token + "/image.png"
+		data.Coffees = p.Coffees
+	}
+
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, data); err != nil {
+		log.Printf("error rendering share page: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if status == http.StatusOK {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	}
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// Image renders the share page's preview image as a PNG.
+func (h *Handler) Image(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	p, err := h.loadPreview(r.Context(), token)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error loading share image: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := renderCard(&buf, p); err != nil {
+		log.Printf("error rendering share image: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(buf.Bytes())
+}
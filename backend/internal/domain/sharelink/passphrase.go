@@ -0,0 +1,86 @@
+package sharelink
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ViewerTokenTTL is how long an unlocked share page stays unlocked.
+const ViewerTokenTTL = time.Hour
+
+// bcryptCost matches the cost used for account passwords.
+const bcryptCost = 12
+
+const viewerTokenType = "share_viewer"
+
+// hashPassphrase fills in req.passphraseHash from a non-empty passphrase.
+func hashPassphrase(req *CreateRequest) error {
+	if req.Passphrase == nil || *req.Passphrase == "" {
+		return nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(*req.Passphrase), bcryptCost)
+	if err != nil {
+		return err
+	}
+	s := string(hash)
+	req.passphraseHash = &s
+	return nil
+}
+
+// passphraseTag identifies the passphrase a viewer token was issued for, so
+// changing the passphrase locks out earlier viewers.
+func passphraseTag(hash string) string {
+	sum := sha256.Sum256([]byte(hash))
+	return hex.EncodeToString(sum[:8])
+}
+
+// issueViewerToken signs a token that unlocks l until it expires. Viewer
+// tokens use their own key, so they can never pass as access tokens.
+func (h *Handler) issueViewerToken(l *Link, now time.Time) (string, time.Time, error) {
+	expiresAt := now.Add(ViewerTokenTTL)
+	claims := jwt.MapClaims{
+		"sub":  l.ID,
+		"type": viewerTokenType,
+		"pass": passphraseTag(*l.PassphraseHash),
+		"iat":  now.Unix(),
+		"exp":  expiresAt.Unix(),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(h.viewerKey)
+	return token, expiresAt, err
+}
+
+// unlocked reports whether the request may see l: the link is open, or the
+// request carries a viewer token for it and its current passphrase.
+func (h *Handler) unlocked(r *http.Request, l *Link) bool {
+	if l.PassphraseHash == nil {
+		return true
+	}
+	tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return h.viewerKey, nil
+	})
+	if err != nil || !token.Valid {
+		return false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return false
+	}
+	sub, _ := claims.GetSubject()
+	tokenType, _ := claims["type"].(string)
+	pass, _ := claims["pass"].(string)
+	return sub == l.ID && tokenType == viewerTokenType && pass == passphraseTag(*l.PassphraseHash)
+}
@@ -0,0 +1,49 @@
+package sharelink
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ViewPurger periodically deletes raw share link views older than the
+// retention period. Lifetime view counts are kept on the links.
+type ViewPurger struct {
+	repo      Repository
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewViewPurger(repo Repository, retention, interval time.Duration) *ViewPurger {
+	return &ViewPurger{repo: repo, retention: retention, interval: interval}
+}
+
+// Run purges immediately and then once per interval until ctx is cancelled.
+func (p *ViewPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("error purging share link views: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce purges a single time and returns the number of views deleted.
+func (p *ViewPurger) RunOnce(ctx context.Context) (int64, error) {
+	n, err := p.repo.PurgeViews(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		log.Printf("purged %d share link views", n)
+	}
+	return n, nil
+}
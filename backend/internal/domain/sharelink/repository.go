@@ -6,9 +6,29 @@ import (
 )
 
 type Repository interface {
-	GetShareToken(ctx context.Context, userID string) (*string, *time.Time, error)
-	SetShareToken(ctx context.Context, userID, token string) (*time.Time, error)
-	ClearShareToken(ctx context.Context, userID string) error
-	GetUserIDByToken(ctx context.Context, token string) (*string, error)
-	GetSharedCoffees(ctx context.Context, userID string) ([]ShareCoffee, error)
+	List(ctx context.Context, userID string) ([]Link, error)
+	GetByID(ctx context.Context, userID, id string) (*Link, error)
+	Create(ctx context.Context, userID, token string, req CreateRequest) (*Link, error)
+	Update(ctx context.Context, userID, id string, req UpdateRequest) (*Link, error)
+	Revoke(ctx context.Context, userID, id string) error
+
+	// GetDefault returns the user's default link, or nil if there is none.
+	GetDefault(ctx context.Context, userID string) (*Link, error)
+	// ReplaceDefault revokes the default link, if any, and creates a new one.
+	ReplaceDefault(ctx context.Context, userID, token, label string) (*Link, error)
+	// RevokeDefault revokes the default link, if any.
+	RevokeDefault(ctx context.Context, userID string) error
+
+	// GetActiveByToken returns the link for token, or ErrNotFound if it is
+	// unknown, revoked or expired.
+	GetActiveByToken(ctx context.Context, token string) (*Link, error)
+	GetSharedCoffees(ctx context.Context, linkID string) ([]ShareCoffee, error)
+
+	// RecordView stores a view and counts it towards the link's total.
+	RecordView(ctx context.Context, linkID string, v View) error
+	// Analytics returns nil if the link isn't the user's.
+	Analytics(ctx context.Context, userID, linkID string, params AnalyticsParams) (*Analytics, error)
+	// PurgeViews deletes raw views from before the cutoff and returns how
+	// many were deleted.
+	PurgeViews(ctx context.Context, before time.Time) (int64, error)
 }
@@ -3,6 +3,7 @@ package sharelink
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -17,98 +18,393 @@ func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
 	return &PgRepository{pool: pool}
 }
 
-func (r *PgRepository) GetShareToken(ctx context.Context, userID string) (*string, *time.Time, error) {
-	var token *string
-	var createdAt *time.Time
-	err := r.pool.QueryRow(ctx,
-		`SELECT share_token, share_token_created_at FROM users WHERE id = $1`,
+const linkColumns = `
+	sl.id, sl.user_id, sl.label, sl.token, sl.scope,
+	ARRAY(
+		SELECT slc.coffee_id::text FROM share_link_coffees slc
+		WHERE slc.share_link_id = sl.id ORDER BY slc.coffee_id
+	),
+	sl.filter_roaster_id, sl.filter_country, sl.filter_process,
+	sl.show_origin, sl.show_roast_date, sl.show_tasting_notes,
+	sl.show_sensory, sl.show_score, sl.show_recipe, sl.view_count,
+	sl.passphrase_hash, sl.expires_at, sl.revoked_at, sl.created_at, sl.updated_at`
+
+// defaultLinkSQL selects the id of the user ($1) default link: the newest
+// active link that shares all coffees, never expires and is open.
+const defaultLinkSQL = `
+	SELECT id FROM share_links
+	WHERE user_id = $1 AND scope = 'all' AND expires_at IS NULL AND revoked_at IS NULL
+	  AND passphrase_hash IS NULL
+	ORDER BY created_at DESC
+	LIMIT 1`
+
+func scanLink(row pgx.Row) (*Link, error) {
+	var l Link
+	var f Filter
+	err := row.Scan(
+		&l.ID, &l.UserID, &l.Label, &l.Token, &l.Scope,
+		&l.CoffeeIDs,
+		&f.RoasterID, &f.Country, &f.Process,
+		&l.Visibility.Origin, &l.Visibility.RoastDate, &l.Visibility.TastingNotes,
+		&l.Visibility.Sensory, &l.Visibility.Score, &l.Visibility.Recipe, &l.ViewCount,
+		&l.PassphraseHash, &l.ExpiresAt, &l.RevokedAt, &l.CreatedAt, &l.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if l.Scope == ScopeFilter {
+		l.Filter = &f
+	}
+	return &l, nil
+}
+
+func (r *PgRepository) List(ctx context.Context, userID string) ([]Link, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+linkColumns+` FROM share_links sl
+		 WHERE sl.user_id = $1
+		 ORDER BY sl.created_at DESC`,
 		userID,
-	).Scan(&token, &createdAt)
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []Link{}
+	for rows.Next() {
+		l, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *l)
+	}
+	return links, rows.Err()
+}
+
+func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*Link, error) {
+	l, err := scanLink(r.pool.QueryRow(ctx,
+		`SELECT `+linkColumns+` FROM share_links sl WHERE sl.id = $1 AND sl.user_id = $2`,
+		id, userID,
+	))
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, nil, nil
+		return nil, nil
 	}
+	return l, err
+}
+
+func (r *PgRepository) Create(ctx context.Context, userID, token string, req CreateRequest) (*Link, error) {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkScope(ctx, tx, userID, req); err != nil {
+		return nil, err
+	}
+
+	f := scopeFilter(req)
+	v := req.Visibility.resolve(DefaultVisibility())
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO share_links (
+			user_id, token, label, scope,
+			filter_roaster_id, filter_country, filter_process, expires_at,
+			show_origin, show_roast_date, show_tasting_notes,
+			show_sensory, show_score, show_recipe, passphrase_hash
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		RETURNING id`,
+		userID, token, req.Label, req.Scope,
+		f.RoasterID, f.Country, f.Process, req.ExpiresAt,
+		v.Origin, v.RoastDate, v.TastingNotes,
+		v.Sensory, v.Score, v.Recipe, req.passphraseHash,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
 	}
-	return token, createdAt, nil
+
+	if err := setCoffees(ctx, tx, id, req); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, userID, id)
 }
 
-func (r *PgRepository) SetShareToken(ctx context.Context, userID, token string) (*time.Time, error) {
-	var createdAt time.Time
-	err := r.pool.QueryRow(ctx,
-		`UPDATE users SET share_token = $1, share_token_created_at = NOW() WHERE id = $2 RETURNING share_token_created_at`,
-		token, userID,
-	).Scan(&createdAt)
+func (r *PgRepository) Update(ctx context.Context, userID, id string, req UpdateRequest) (*Link, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var revokedAt *time.Time
+	var current Visibility
+	err = tx.QueryRow(ctx,
+		`SELECT revoked_at,
+			show_origin, show_roast_date, show_tasting_notes,
+			show_sensory, show_score, show_recipe
+		 FROM share_links WHERE id = $1 AND user_id = $2 FOR UPDATE`,
+		id, userID,
+	).Scan(&revokedAt,
+		&current.Origin, &current.RoastDate, &current.TastingNotes,
+		&current.Sensory, &current.Score, &current.Recipe,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &createdAt, nil
+	if revokedAt != nil {
+		return nil, ErrRevoked
+	}
+
+	if err := checkScope(ctx, tx, userID, CreateRequest(req)); err != nil {
+		return nil, err
+	}
+
+	f := scopeFilter(CreateRequest(req))
+	v := req.Visibility.resolve(current)
+	if _, err := tx.Exec(ctx,
+		`UPDATE share_links SET
+			label = $1, scope = $2,
+			filter_roaster_id = $3, filter_country = $4, filter_process = $5,
+			expires_at = $6,
+			show_origin = $7, show_roast_date = $8, show_tasting_notes = $9,
+			show_sensory = $10, show_score = $11, show_recipe = $12,
+			passphrase_hash = CASE WHEN $13::boolean THEN $14 ELSE passphrase_hash END,
+			updated_at = NOW()
+		WHERE id = $15`,
+		req.Label, req.Scope,
+		f.RoasterID, f.Country, f.Process,
+		req.ExpiresAt,
+		v.Origin, v.RoastDate, v.TastingNotes,
+		v.Sensory, v.Score, v.Recipe,
+		req.Passphrase != nil, req.passphraseHash,
+		id,
+	); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM share_link_coffees WHERE share_link_id = $1`, id); err != nil {
+		return nil, err
+	}
+	if err := setCoffees(ctx, tx, id, CreateRequest(req)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, userID, id)
 }
 
-func (r *PgRepository) ClearShareToken(ctx context.Context, userID string) error {
-	_, err := r.pool.Exec(ctx,
-		`UPDATE users SET share_token = NULL, share_token_created_at = NULL WHERE id = $1`,
-		userID,
+// Revoke is idempotent: revoking a revoked link keeps its original
+// revoked_at.
+func (r *PgRepository) Revoke(ctx context.Context, userID, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE share_links
+		 SET revoked_at = COALESCE(revoked_at, NOW()), updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+// checkScope verifies that the coffees and roaster a request names belong to
+// the user.
+func checkScope(ctx context.Context, tx pgx.Tx, userID string, req CreateRequest) error {
+	switch req.Scope {
+	case ScopeCoffees:
+		var n int
+		if err := tx.QueryRow(ctx,
+			`SELECT COUNT(*) FROM coffees WHERE id = ANY($1) AND user_id = $2`,
+			req.CoffeeIDs, userID,
+		).Scan(&n); err != nil {
+			return err
+		}
+		if n != len(req.CoffeeIDs) {
+			return ErrCoffeeNotFound
+		}
+	case ScopeFilter:
+		if req.Filter.RoasterID == nil {
+			return nil
+		}
+		var exists bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM roasters WHERE id = $1 AND user_id = $2)`,
+			*req.Filter.RoasterID, userID,
+		).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRoasterNotFound
+		}
+	}
+	return nil
+}
+
+// scopeFilter returns the filter columns to store, which are empty unless
+// the link is scoped by filter.
+func scopeFilter(req CreateRequest) Filter {
+	if req.Scope != ScopeFilter || req.Filter == nil {
+		return Filter{}
+	}
+	return *req.Filter
+}
+
+func setCoffees(ctx context.Context, tx pgx.Tx, linkID string, req CreateRequest) error {
+	if req.Scope != ScopeCoffees {
+		return nil
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO share_link_coffees (share_link_id, coffee_id)
+		 SELECT $1, unnest($2::uuid[])`,
+		linkID, req.CoffeeIDs,
 	)
 	return err
 }
 
-func (r *PgRepository) GetUserIDByToken(ctx context.Context, token string) (*string, error) {
-	var userID string
-	err := r.pool.QueryRow(ctx,
-		`SELECT id FROM users WHERE share_token = $1`,
-		token,
-	).Scan(&userID)
+func (r *PgRepository) GetDefault(ctx context.Context, userID string) (*Link, error) {
+	l, err := scanLink(r.pool.QueryRow(ctx,
+		`SELECT `+linkColumns+` FROM share_links sl WHERE sl.id = (`+defaultLinkSQL+`)`,
+		userID,
+	))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
+	return l, err
+}
+
+func (r *PgRepository) ReplaceDefault(ctx context.Context, userID, token, label string) (*Link, error) {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &userID, nil
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE share_links SET revoked_at = NOW(), updated_at = NOW()
+		 WHERE id = (`+defaultLinkSQL+`)`,
+		userID,
+	); err != nil {
+		return nil, err
+	}
+
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO share_links (user_id, token, label) VALUES ($1, $2, $3) RETURNING id`,
+		userID, token, label,
+	).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, userID, id)
 }
 
-func (r *PgRepository) GetSharedCoffees(ctx context.Context, userID string) ([]ShareCoffee, error) {
+func (r *PgRepository) RevokeDefault(ctx context.Context, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE share_links SET revoked_at = NOW(), updated_at = NOW()
+		 WHERE id = (`+defaultLinkSQL+`)`,
+		userID,
+	)
+	return err
+}
+
+func (r *PgRepository) GetActiveByToken(ctx context.Context, token string) (*Link, error) {
+	l, err := scanLink(r.pool.QueryRow(ctx,
+		`SELECT `+linkColumns+` FROM share_links sl
+		 WHERE sl.token = $1 AND sl.revoked_at IS NULL
+		   AND (sl.expires_at IS NULL OR sl.expires_at > NOW())`,
+		token,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return l, err
+}
+
+// GetSharedCoffees returns the active coffees a link shares, newest first,
+// with everything a share page can show. Callers apply the link's
+// visibility.
+func (r *PgRepository) GetSharedCoffees(ctx context.Context, linkID string) ([]ShareCoffee, error) {
 	rows, err := r.pool.Query(ctx, `
 		SELECT
-			c.roaster, c.name, c.country, c.region, c.process,
+			ro.name, c.name, c.country, c.region, c.process,
 			c.roast_level, c.tasting_notes, c.roast_date,
-			ref.overall_score,
+			ref.id, ref.overall_score,
 			ref.aroma_intensity, ref.body_intensity, ref.sweetness_intensity,
-			ref.brightness_intensity, ref.complexity_intensity, ref.aftertaste_intensity
-		FROM coffees c
+			ref.brightness_intensity, ref.complexity_intensity, ref.aftertaste_intensity,
+			ref.method, ref.coffee_weight, ref.ratio, ref.grind_size, ref.grinder,
+			ref.water_temperature, ref.dripper, ref.total_brew_time
+		FROM share_links sl
+		JOIN coffees c ON c.user_id = sl.user_id AND c.archived_at IS NULL
+		JOIN roasters ro ON ro.id = c.roaster_id
 		LEFT JOIN LATERAL (
-			SELECT b.overall_score,
+			SELECT b.id, b.overall_score,
 				   b.aroma_intensity, b.body_intensity, b.sweetness_intensity,
-				   b.brightness_intensity, b.complexity_intensity, b.aftertaste_intensity
+				   b.brightness_intensity, b.complexity_intensity, b.aftertaste_intensity,
+				   b.method, b.coffee_weight, b.ratio, b.grind_size, g.name AS grinder,
+				   b.water_temperature, d.name AS dripper, b.total_brew_time
 			FROM brews b
+			LEFT JOIN grinders g ON g.id = b.grinder_id
+			LEFT JOIN drippers d ON d.id = b.dripper_id
 			WHERE b.coffee_id = c.id
 			ORDER BY
 				CASE WHEN b.id = c.reference_brew_id THEN 0 ELSE 1 END,
 				b.brew_date DESC, b.created_at DESC
 			LIMIT 1
 		) ref ON true
-		WHERE c.user_id = $1 AND c.archived_at IS NULL
+		WHERE sl.id = $1
+		  AND (sl.scope <> 'coffees' OR EXISTS (
+			SELECT 1 FROM share_link_coffees slc
+			WHERE slc.share_link_id = sl.id AND slc.coffee_id = c.id
+		  ))
+		  AND (sl.scope <> 'filter' OR (
+			(sl.filter_roaster_id IS NULL OR c.roaster_id = sl.filter_roaster_id)
+			AND (sl.filter_country IS NULL OR c.country = sl.filter_country)
+			AND (sl.filter_process IS NULL OR c.process = sl.filter_process)
+		  ))
 		ORDER BY c.created_at DESC`,
-		userID,
+		linkID,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var coffees []ShareCoffee
+	coffees := []ShareCoffee{}
+	// recipes maps reference brew IDs to their recipes, for attaching pours.
+	recipes := map[string]*ShareRecipe{}
+	var brewIDs []string
 	for rows.Next() {
 		var sc ShareCoffee
 		var roastDate *time.Time
-		var overallScore, aroma, body, sweetness, brightness, complexity, aftertaste *int
+		var brewID, method *string
+		var ref ShareReferenceBrew
+		var recipe ShareRecipe
 
 		err := rows.Scan(
 			&sc.Roaster, &sc.Name, &sc.Country, &sc.Region, &sc.Process,
 			&sc.RoastLevel, &sc.TastingNotes, &roastDate,
-			&overallScore,
-			&aroma, &body, &sweetness,
-			&brightness, &complexity, &aftertaste,
+			&brewID, &ref.OverallScore,
+			&ref.AromaIntensity, &ref.BodyIntensity, &ref.SweetnessIntensity,
+			&ref.BrightnessIntensity, &ref.ComplexityIntensity, &ref.AftertasteIntensity,
+			&method, &recipe.CoffeeWeight, &recipe.Ratio, &recipe.GrindSize, &recipe.Grinder,
+			&recipe.WaterTemperature, &recipe.Dripper, &recipe.TotalBrewTime,
 		)
 		if err != nil {
 			return nil, err
@@ -119,25 +415,177 @@ func (r *PgRepository) GetSharedCoffees(ctx context.Context, userID string) ([]S
 			sc.RoastDate = &s
 		}
 
-		if overallScore != nil || aroma != nil || body != nil || sweetness != nil ||
-			brightness != nil || complexity != nil || aftertaste != nil {
-			sc.ReferenceBrew = &ShareReferenceBrew{
-				OverallScore:        overallScore,
-				AromaIntensity:      aroma,
-				BodyIntensity:       body,
-				SweetnessIntensity:  sweetness,
-				BrightnessIntensity: brightness,
-				ComplexityIntensity: complexity,
-				AftertasteIntensity: aftertaste,
-			}
+		if brewID != nil {
+			recipe.Method = *method
+			recipe.Pours = []SharePour{}
+			ref.Recipe = &recipe
+			sc.ReferenceBrew = &ref
+			recipes[*brewID] = &recipe
+			brewIDs = append(brewIDs, *brewID)
 		}
 
 		coffees = append(coffees, sc)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(brewIDs) == 0 {
+		return coffees, nil
+	}
+	pourRows, err := r.pool.Query(ctx,
+		`SELECT brew_id, pour_number, water_amount, pour_style, wait_time
+		 FROM brew_pours WHERE brew_id = ANY($1) ORDER BY brew_id, pour_number`,
+		brewIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer pourRows.Close()
+
+	for pourRows.Next() {
+		var brewID string
+		var p SharePour
+		if err := pourRows.Scan(&brewID, &p.PourNumber, &p.WaterAmount, &p.PourStyle, &p.WaitTime); err != nil {
+			return nil, err
+		}
+		recipes[brewID].Pours = append(recipes[brewID].Pours, p)
+	}
+	return coffees, pourRows.Err()
+}
 
-	if coffees == nil {
-		coffees = []ShareCoffee{}
+// RecordView stores the raw view and bumps the lifetime count in one
+// statement.
+func (r *PgRepository) RecordView(ctx context.Context, linkID string, v View) error {
+	_, err := r.pool.Exec(ctx,
+		`WITH view AS (
+			INSERT INTO share_link_views (share_link_id, ip_hash, ua_family, referrer)
+			VALUES ($1, $2, $3, $4)
+		)
+		UPDATE share_links SET view_count = view_count + 1 WHERE id = $1`,
+		linkID, v.IPHash, v.UAFamily, v.Referrer,
+	)
+	return err
+}
+
+// viewRangeSQL restricts share_link_views to link $1 between the UTC dates
+// $2 and $3, inclusive.
+const viewRangeSQL = `share_link_id = $1
+	AND viewed_at >= $2::date::timestamp AT TIME ZONE 'UTC'
+	AND viewed_at < ($3::date + 1)::timestamp AT TIME ZONE 'UTC'`
+
+// Analytics aggregates the raw views in SQL and fills empty buckets with
+// generate_series, as the brew statistics do.
+func (r *PgRepository) Analytics(ctx context.Context, userID, linkID string, params AnalyticsParams) (*Analytics, error) {
+	if !validBuckets[params.Bucket] {
+		return nil, fmt.Errorf("invalid bucket: %s", params.Bucket)
 	}
 
-	return coffees, nil
+	a := Analytics{
+		LinkID:   linkID,
+		Bucket:   params.Bucket,
+		DateFrom: params.DateFrom,
+		DateTo:   params.DateTo,
+	}
+	err := r.pool.QueryRow(ctx,
+		`SELECT view_count FROM share_links WHERE id = $1 AND user_id = $2`,
+		linkID, userID,
+	).Scan(&a.TotalViews)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	args := []interface{}{linkID, params.DateFrom, params.DateTo}
+
+	err = r.pool.QueryRow(ctx,
+		`SELECT COUNT(*), COUNT(DISTINCT ip_hash) FROM share_link_views WHERE `+viewRangeSQL,
+		args...,
+	).Scan(&a.Views, &a.UniqueVisitors)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(
+		`WITH agg AS (
+			SELECT date_trunc('%[1]s', viewed_at AT TIME ZONE 'UTC')::date AS bucket,
+				COUNT(*) AS views,
+				COUNT(DISTINCT ip_hash) AS unique_visitors
+			FROM share_link_views
+			WHERE %[2]s
+			GROUP BY 1
+		)
+		SELECT s.bucket::date, COALESCE(a.views, 0), COALESCE(a.unique_visitors, 0)
+		FROM generate_series(
+			date_trunc('%[1]s', $2::date::timestamp),
+			date_trunc('%[1]s', $3::date::timestamp),
+			INTERVAL '1 %[1]s'
+		) AS s(bucket)
+		LEFT JOIN agg a ON a.bucket = s.bucket::date
+		ORDER BY s.bucket`,
+		params.Bucket, viewRangeSQL,
+	)
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	a.Items = []ViewPoint{}
+	for rows.Next() {
+		var p ViewPoint
+		var bucket time.Time
+		if err := rows.Scan(&bucket, &p.Views, &p.UniqueVisitors); err != nil {
+			return nil, err
+		}
+		p.BucketStart = bucket.Format("2006-01-02")
+		a.Items = append(a.Items, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if a.UserAgents, err = r.countViews(ctx, "ua_family", args); err != nil {
+		return nil, err
+	}
+	if a.Referrers, err = r.countViews(ctx, "referrer", args); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// countViews counts the views in range by a share_link_views column, most
+// viewed first.
+func (r *PgRepository) countViews(ctx context.Context, column string, args []interface{}) ([]ViewCount, error) {
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(
+		`SELECT %[1]s, COUNT(*) FROM share_link_views
+		 WHERE %[2]s
+		 GROUP BY %[1]s
+		 ORDER BY COUNT(*) DESC, %[1]s`,
+		column, viewRangeSQL,
+	), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []ViewCount{}
+	for rows.Next() {
+		var c ViewCount
+		if err := rows.Scan(&c.Name, &c.Views); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func (r *PgRepository) PurgeViews(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM share_link_views WHERE viewed_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
 }
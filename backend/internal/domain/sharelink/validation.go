@@ -0,0 +1,142 @@
+package sharelink
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/domain/stats"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// MaxLabelLength matches the share_links.label column.
+const MaxLabelLength = 100
+
+// Passphrase limits. bcrypt only reads the first 72 bytes.
+const (
+	MinPassphraseLength = 8
+	MaxPassphraseBytes  = 72
+)
+
+// normalize trims the label and filter values, defaults the scope to all and
+// drops repeated coffee IDs. Blank filter values count as unset.
+func normalize(req *CreateRequest) {
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Scope == "" {
+		req.Scope = ScopeAll
+	}
+	if req.Filter != nil {
+		req.Filter.RoasterID = trimmed(req.Filter.RoasterID)
+		req.Filter.Country = trimmed(req.Filter.Country)
+		req.Filter.Process = trimmed(req.Filter.Process)
+	}
+
+	seen := make(map[string]bool, len(req.CoffeeIDs))
+	ids := req.CoffeeIDs[:0]
+	for _, id := range req.CoffeeIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	req.CoffeeIDs = ids
+}
+
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+// validateRequest checks the label, expiry and passphrase, and that
+// coffee_ids and filter are given exactly when the scope needs them.
+func validateRequest(req CreateRequest, now time.Time) []api.FieldError {
+	var errs validate.Errors
+	if req.Label == "" {
+		errs.Add("label", "is required")
+	} else if utf8.RuneCountInString(req.Label) > MaxLabelLength {
+		errs.Add("label", fmt.Sprintf("must be at most %d characters", MaxLabelLength))
+	}
+
+	if !validScopes[req.Scope] {
+		errs.Add("scope", "must be one of all, coffees, filter")
+	}
+
+	if req.Scope == ScopeCoffees {
+		if len(req.CoffeeIDs) == 0 {
+			errs.Add("coffee_ids", "must name at least one coffee")
+		}
+	} else if len(req.CoffeeIDs) > 0 {
+		errs.Add("coffee_ids", "is only allowed when scope is coffees")
+	}
+
+	hasFilter := req.Filter != nil &&
+		(req.Filter.RoasterID != nil || req.Filter.Country != nil || req.Filter.Process != nil)
+	if req.Scope == ScopeFilter {
+		if !hasFilter {
+			errs.Add("filter", "must set roaster_id, country or process")
+		}
+	} else if hasFilter {
+		errs.Add("filter", "is only allowed when scope is filter")
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		errs.Add("expires_at", "must be in the future")
+	}
+
+	// An empty passphrase means none, or removes it on update.
+	if p := req.Passphrase; p != nil && *p != "" {
+		if utf8.RuneCountInString(*p) < MinPassphraseLength {
+			errs.Add("passphrase", fmt.Sprintf("must be at least %d characters", MinPassphraseLength))
+		} else if len(*p) > MaxPassphraseBytes {
+			errs.Add("passphrase", fmt.Sprintf("must be at most %d bytes", MaxPassphraseBytes))
+		}
+	}
+
+	return errs
+}
+
+// defaultAnalyticsDays is the period analytics cover when no dates are given.
+const defaultAnalyticsDays = 30
+
+// validateAnalyticsParams checks the bucket size and that the dates are
+// YYYY-MM-DD and in order.
+func validateAnalyticsParams(p AnalyticsParams) []api.FieldError {
+	var errs validate.Errors
+	if !validBuckets[p.Bucket] {
+		errs.Add("bucket", "must be one of day, week, month")
+	}
+	from, fromErr := time.Parse("2006-01-02", p.DateFrom)
+	if fromErr != nil {
+		errs.Add("date_from", "must be a date in YYYY-MM-DD format")
+	}
+	to, toErr := time.Parse("2006-01-02", p.DateTo)
+	if toErr != nil {
+		errs.Add("date_to", "must be a date in YYYY-MM-DD format")
+	}
+	if fromErr == nil && toErr == nil && to.Before(from) {
+		errs.Add("date_to", "must not be before date_from")
+	}
+	return errs
+}
+
+// validateAnalyticsSpan checks that the series spans at most
+// stats.MaxSeriesBuckets buckets. Call it once validateAnalyticsParams has
+// passed.
+func validateAnalyticsSpan(p AnalyticsParams) []api.FieldError {
+	from, _ := time.Parse("2006-01-02", p.DateFrom)
+	to, _ := time.Parse("2006-01-02", p.DateTo)
+
+	var errs validate.Errors
+	if stats.SeriesBuckets(p.Bucket, from, to) > stats.MaxSeriesBuckets {
+		errs.Add("date_from", fmt.Sprintf("must span at most %d %ss", stats.MaxSeriesBuckets, p.Bucket))
+	}
+	return errs
+}
@@ -0,0 +1,66 @@
+package sharelink
+
+// DefaultVisibility shares everything but the recipe, which is what share
+// pages showed before links had a visibility policy.
+func DefaultVisibility() Visibility {
+	return Visibility{
+		Origin:       true,
+		RoastDate:    true,
+		TastingNotes: true,
+		Sensory:      true,
+		Score:        true,
+	}
+}
+
+// resolve fills the groups the request leaves out from base: the defaults
+// when creating a link, its stored visibility when updating one.
+func (r VisibilityRequest) resolve(base Visibility) Visibility {
+	v := base
+	pick := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	pick(&v.Origin, r.Origin)
+	pick(&v.RoastDate, r.RoastDate)
+	pick(&v.TastingNotes, r.TastingNotes)
+	pick(&v.Sensory, r.Sensory)
+	pick(&v.Score, r.Score)
+	pick(&v.Recipe, r.Recipe)
+	return v
+}
+
+// Apply clears the fields of c that v hides. A reference brew left with
+// nothing to show is dropped. The reference brew is copied rather than
+// modified in place.
+func (v Visibility) Apply(c *ShareCoffee) {
+	if !v.Origin {
+		c.Country, c.Region, c.Process = nil, nil, nil
+	}
+	if !v.RoastDate {
+		c.RoastDate = nil
+	}
+	if !v.TastingNotes {
+		c.TastingNotes = nil
+	}
+
+	if c.ReferenceBrew == nil {
+		return
+	}
+	ref := *c.ReferenceBrew
+	if !v.Score {
+		ref.OverallScore = nil
+	}
+	if !v.Sensory {
+		ref.AromaIntensity, ref.BodyIntensity, ref.SweetnessIntensity = nil, nil, nil
+		ref.BrightnessIntensity, ref.ComplexityIntensity, ref.AftertasteIntensity = nil, nil, nil
+	}
+	if !v.Recipe {
+		ref.Recipe = nil
+	}
+	if ref == (ShareReferenceBrew{}) {
+		c.ReferenceBrew = nil
+	} else {
+		c.ReferenceBrew = &ref
+	}
+}
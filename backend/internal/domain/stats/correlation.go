@@ -0,0 +1,157 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// MinCorrelationSamples is the fewest brews a coefficient is computed from.
+const MinCorrelationSamples = 3
+
+// MaxBins caps the bins per variable. Variables with no more distinct values
+// than this get one bin per value.
+const MaxBins = 5
+
+// Correlate relates every variable to every outcome across the samples.
+func Correlate(samples []Sample) []Correlation {
+	items := make([]Correlation, 0, len(Variables)*len(Outcomes))
+	for _, v := range Variables {
+		for _, o := range Outcomes {
+			var xs, ys []float64
+			for _, s := range samples {
+				x, okX := s.Variables[v]
+				y, okY := s.Outcomes[o]
+				if okX && okY {
+					xs = append(xs, x)
+					ys = append(ys, y)
+				}
+			}
+
+			c := Correlation{Variable: v, Outcome: o, SampleSize: len(xs), Bins: binMeans(xs, ys)}
+			if len(xs) >= MinCorrelationSamples {
+				c.Pearson = roundPtr(pearson(xs, ys), 3)
+				c.Spearman = roundPtr(pearson(ranks(xs), ranks(ys)), 3)
+			}
+			items = append(items, c)
+		}
+	}
+	return items
+}
+
+// pearson returns the Pearson correlation coefficient, or nil when either
+// series has no variance.
+func pearson(xs, ys []float64) *float64 {
+	n := float64(len(xs))
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	r := cov / math.Sqrt(varX*varY)
+	return &r
+}
+
+// ranks returns the 1-based rank of each value, giving tied values the mean
+// of their ranks, as Spearman's coefficient requires.
+func ranks(xs []float64) []float64 {
+	idx := make([]int, len(xs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return xs[idx[a]] < xs[idx[b]] })
+
+	r := make([]float64, len(xs))
+	for i := 0; i < len(idx); {
+		j := i
+		for j+1 < len(idx) && xs[idx[j+1]] == xs[idx[i]] {
+			j++
+		}
+		avg := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			r[idx[k]] = avg
+		}
+		i = j + 1
+	}
+	return r
+}
+
+// binMeans groups the samples by x and averages y in each group. Bins are
+// equal-width over the range of x, the last one including its upper bound.
+func binMeans(xs, ys []float64) []Bin {
+	bins := []Bin{}
+	if len(xs) == 0 {
+		return bins
+	}
+
+	distinct := map[float64]bool{}
+	for _, x := range xs {
+		distinct[x] = true
+	}
+
+	if len(distinct) <= MaxBins {
+		values := make([]float64, 0, len(distinct))
+		for x := range distinct {
+			values = append(values, x)
+		}
+		sort.Float64s(values)
+		for _, x := range values {
+			bins = append(bins, Bin{Min: x, Max: x})
+		}
+	} else {
+		lo, hi := xs[0], xs[0]
+		for _, x := range xs {
+			lo, hi = math.Min(lo, x), math.Max(hi, x)
+		}
+		width := (hi - lo) / MaxBins
+		for i := 0; i < MaxBins; i++ {
+			bins = append(bins, Bin{
+				Min: round(lo+float64(i)*width, 2),
+				Max: round(lo+float64(i+1)*width, 2),
+			})
+		}
+		bins[MaxBins-1].Max = hi
+	}
+
+	sums := make([]float64, len(bins))
+	for i, x := range xs {
+		b := sort.Search(len(bins), func(k int) bool { return bins[k].Max >= x })
+		if b == len(bins) {
+			b = len(bins) - 1
+		}
+		bins[b].SampleSize++
+		sums[b] += ys[i]
+	}
+	for i := range bins {
+		if bins[i].SampleSize > 0 {
+			mean := round(sums[i]/float64(bins[i].SampleSize), 2)
+			bins[i].Mean = &mean
+		}
+	}
+	return bins
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+func roundPtr(v *float64, places int) *float64 {
+	if v == nil {
+		return nil
+	}
+	r := round(*v, places)
+	return &r
+}
@@ -0,0 +1,97 @@
+package stats
+
+import "github.com/poimgs/coffee-tracker/backend/internal/brewfilter"
+
+// Bucket sizes for time series.
+const (
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+var validBuckets = map[string]bool{
+	BucketDay:   true,
+	BucketWeek:  true,
+	BucketMonth: true,
+}
+
+// Filters select the brews that feed a statistic. They are the filters of
+// the brew list.
+type Filters = brewfilter.Filters
+
+type SeriesParams struct {
+	Bucket string
+	Filters
+}
+
+// Point is one bucket of a brew time series. Buckets without brews are
+// included with a zero count and null averages, so the series has no gaps.
+type Point struct {
+	BucketStart        string   `json:"bucket_start"`
+	BrewCount          int      `json:"brew_count"`
+	GramsConsumed      float64  `json:"grams_consumed"`
+	AvgScore           *float64 `json:"avg_score"`
+	AvgExtractionYield *float64 `json:"avg_extraction_yield"`
+	AvgTDS             *float64 `json:"avg_tds"`
+}
+
+type SeriesResponse struct {
+	Bucket string  `json:"bucket"`
+	Items  []Point `json:"items"`
+}
+
+// Brew variables and outcomes compared by the correlation analysis. Pour
+// count and bloom wait come from the brew's pours; pour #1 is the bloom.
+var (
+	Variables = []string{
+		"grind_size",
+		"ratio",
+		"water_temperature",
+		"total_brew_time",
+		"days_off_roast",
+		"pour_count",
+		"bloom_wait_time",
+	}
+	Outcomes = []string{
+		"overall_score",
+		"aroma_intensity",
+		"body_intensity",
+		"sweetness_intensity",
+		"brightness_intensity",
+		"complexity_intensity",
+		"aftertaste_intensity",
+	}
+)
+
+// Sample holds one brew's recorded variables and outcomes, keyed by name.
+// Values the brew didn't record are absent.
+type Sample struct {
+	Variables map[string]float64
+	Outcomes  map[string]float64
+}
+
+// Correlation relates one variable to one outcome over the brews that
+// recorded both. The coefficients are nil with fewer than
+// MinCorrelationSamples brews or when either side never varies.
+type Correlation struct {
+	Variable   string   `json:"variable"`
+	Outcome    string   `json:"outcome"`
+	SampleSize int      `json:"sample_size"`
+	Pearson    *float64 `json:"pearson"`
+	Spearman   *float64 `json:"spearman"`
+	Bins       []Bin    `json:"bins"`
+}
+
+// Bin is the mean outcome of the brews whose variable lies in [Min, Max].
+// Mean is nil for an empty bin.
+type Bin struct {
+	Min        float64  `json:"min"`
+	Max        float64  `json:"max"`
+	SampleSize int      `json:"sample_size"`
+	Mean       *float64 `json:"mean"`
+}
+
+type CorrelationResponse struct {
+	BrewCount int           `json:"brew_count"`
+	Items     []Correlation `json:"items"`
+}
@@ -0,0 +1,119 @@
+package stats
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// parseFilters reads the brew filters from the query string, using the same
+// parameter names as the brew list.
+func parseFilters(q url.Values) Filters {
+	f := Filters{
+		CoffeeID:       q.Get("coffee_id"),
+		DripperID:      q.Get("dripper_id"),
+		FilterPaperID:  q.Get("filter_paper_id"),
+		WaterProfileID: q.Get("water_profile_id"),
+		Method:         q.Get("method"),
+		DateFrom:       nilIfEmpty(q.Get("date_from")),
+		DateTo:         nilIfEmpty(q.Get("date_to")),
+		Tags:           tags.Parse(q.Get("tags")),
+		TagMatch:       q.Get("tag_match"),
+	}
+
+	if v := q.Get("score_gte"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			f.ScoreGTE = &n
+		}
+	}
+	if v := q.Get("score_lte"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			f.ScoreLTE = &n
+		}
+	}
+	if v := q.Get("has_tds"); v != "" {
+		b := v == "true"
+		f.HasTDS = &b
+	}
+
+	return f
+}
+
+func (h *Handler) Series(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	q := r.URL.Query()
+
+	params := SeriesParams{
+		Bucket:  q.Get("bucket"),
+		Filters: parseFilters(q),
+	}
+	if params.Bucket == "" {
+		params.Bucket = BucketWeek
+	}
+
+	if fieldErrors := validateSeriesParams(params); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+	if fieldErrors := validateSeriesSpan(params, time.Now()); len(fieldErrors) > 0 {
+		api.RangeTooLargeError(w, fieldErrors)
+		return
+	}
+
+	points, err := h.repo.Series(r.Context(), userID, params)
+	if err != nil {
+		log.Printf("error computing brew series: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, SeriesResponse{Bucket: params.Bucket, Items: points})
+}
+
+// Correlations relates brew variables to the score and sensory intensities.
+// Filter by coffee_id to analyse one coffee.
+func (h *Handler) Correlations(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	filters := parseFilters(r.URL.Query())
+
+	var errs validate.Errors
+	validateDateRange(&errs, filters)
+	if len(errs) > 0 {
+		api.ValidationError(w, errs)
+		return
+	}
+
+	samples, err := h.repo.Samples(r.Context(), userID, filters)
+	if err != nil {
+		log.Printf("error loading correlation samples: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, CorrelationResponse{
+		BrewCount: len(samples),
+		Items:     Correlate(samples),
+	})
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,507 @@
+package stats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockBrew struct {
+	userID       string
+	coffeeID     string
+	dripperID    string
+	brewDate     string
+	coffeeWeight float64
+	score        *int
+	tds          *float64
+}
+
+// mockRepo buckets brews by day only; the week and month truncation is done
+// in SQL.
+type mockRepo struct {
+	brews       []mockBrew
+	lastParams  SeriesParams
+	samples     []Sample
+	lastFilters Filters
+}
+
+func (m *mockRepo) Series(_ context.Context, userID string, params SeriesParams) ([]Point, error) {
+	m.lastParams = params
+	byDay := map[string]*Point{}
+	var order []string
+	for _, b := range m.brews {
+		if b.userID != userID {
+			continue
+		}
+		if params.CoffeeID != "" && b.coffeeID != params.CoffeeID {
+			continue
+		}
+		if params.DripperID != "" && b.dripperID != params.DripperID {
+			continue
+		}
+		p := byDay[b.brewDate]
+		if p == nil {
+			p = &Point{BucketStart: b.brewDate}
+			byDay[b.brewDate] = p
+			order = append(order, b.brewDate)
+		}
+		p.BrewCount++
+		p.GramsConsumed += b.coffeeWeight
+		if b.score != nil {
+			s := float64(*b.score)
+			p.AvgScore = &s
+		}
+		if b.tds != nil {
+			p.AvgTDS = b.tds
+		}
+	}
+	points := []Point{}
+	for _, d := range order {
+		points = append(points, *byDay[d])
+	}
+	return points, nil
+}
+
+func (m *mockRepo) Samples(_ context.Context, _ string, filters Filters) ([]Sample, error) {
+	m.lastFilters = filters
+	return m.samples, nil
+}
+
+type errorRepo struct{}
+
+func (e *errorRepo) Series(_ context.Context, _ string, _ SeriesParams) ([]Point, error) {
+	return nil, errors.New("database error")
+}
+
+func (e *errorRepo) Samples(_ context.Context, _ string, _ Filters) ([]Sample, error) {
+	return nil, errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/stats", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/brews", h.Series)
+		r.Get("/correlations", h.Correlations)
+	})
+	return r
+}
+
+func authRequest(method, url string) *http.Request {
+	req := httptest.NewRequest(method, url, nil)
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func intPtr(i int) *int           { return &i }
+func floatPtr(f float64) *float64 { return &f }
+
+// --- Tests ---
+
+func TestSeries_DefaultsToWeek(t *testing.T) {
+	repo := &mockRepo{brews: []mockBrew{
+		{userID: "user-123", coffeeID: "c-1", brewDate: "2026-01-05", coffeeWeight: 15, score: intPtr(7), tds: floatPtr(1.35)},
+		{userID: "user-123", coffeeID: "c-1", brewDate: "2026-01-05", coffeeWeight: 18},
+		{userID: "other-user", coffeeID: "c-2", brewDate: "2026-01-05", coffeeWeight: 20},
+	}}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/brews")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp SeriesResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Bucket != BucketWeek {
+		t.Errorf("expected week bucket, got %q", resp.Bucket)
+	}
+	if len(resp.Items) != 1 {
+		t.Fatalf("expected 1 point, got %d", len(resp.Items))
+	}
+	p := resp.Items[0]
+	if p.BrewCount != 2 || p.GramsConsumed != 33 {
+		t.Errorf("expected 2 brews and 33g, got %d and %v", p.BrewCount, p.GramsConsumed)
+	}
+	if p.AvgScore == nil || *p.AvgScore != 7 {
+		t.Errorf("expected avg score 7, got %v", p.AvgScore)
+	}
+}
+
+func TestSeries_PassesFilters(t *testing.T) {
+	repo := &mockRepo{brews: []mockBrew{
+		{userID: "user-123", coffeeID: "c-1", dripperID: "d-1", brewDate: "2026-01-05", coffeeWeight: 15},
+		{userID: "user-123", coffeeID: "c-1", dripperID: "d-2", brewDate: "2026-01-06", coffeeWeight: 15},
+	}}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/brews?bucket=day&coffee_id=c-1&dripper_id=d-1&filter_paper_id=fp-1&method=pour_over&score_gte=5&has_tds=true&date_from=2026-01-01&date_to=2026-01-31")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp SeriesResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].BucketStart != "2026-01-05" {
+		t.Errorf("expected only the d-1 brew, got %+v", resp.Items)
+	}
+
+	p := repo.lastParams
+	if p.Bucket != BucketDay || p.FilterPaperID != "fp-1" || p.Method != "pour_over" {
+		t.Errorf("unexpected params: %+v", p)
+	}
+	if p.ScoreGTE == nil || *p.ScoreGTE != 5 {
+		t.Errorf("expected score_gte 5, got %v", p.ScoreGTE)
+	}
+	if p.HasTDS == nil || !*p.HasTDS {
+		t.Error("expected has_tds true")
+	}
+	if p.DateFrom == nil || *p.DateFrom != "2026-01-01" || p.DateTo == nil || *p.DateTo != "2026-01-31" {
+		t.Errorf("expected date range, got %v to %v", p.DateFrom, p.DateTo)
+	}
+}
+
+func TestSeries_PassesTagFilter(t *testing.T) {
+	repo := &mockRepo{}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/brews?bucket=week&tags=washed,%20Kenya,&tag_match=all")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	p := repo.lastParams
+	if len(p.Tags) != 2 || p.Tags[0] != "washed" || p.Tags[1] != "Kenya" {
+		t.Errorf("expected tags [washed Kenya], got %q", p.Tags)
+	}
+	if p.TagMatch != "all" {
+		t.Errorf("expected tag_match all, got %q", p.TagMatch)
+	}
+}
+
+func TestSeries_EmptyItems(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/brews?bucket=month")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	items, ok := resp["items"].([]interface{})
+	if !ok || len(items) != 0 {
+		t.Errorf("expected empty items array, got %v", resp["items"])
+	}
+}
+
+func TestSeries_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"invalid bucket", "bucket=year"},
+		{"malformed date_from", "date_from=01-05-2026"},
+		{"malformed date_to", "date_to=2026-13-01"},
+		{"reversed range", "date_from=2026-02-01&date_to=2026-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(&mockRepo{})
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodGet, "/api/v1/stats/brews?"+tt.query)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSeries_RangeTooLarge(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"daily over decades", "bucket=day&date_from=2000-01-01&date_to=2026-01-01"},
+		{"open-ended from far past", "bucket=week&date_from=0001-01-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{}
+			h := NewHandler(repo)
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodGet, "/api/v1/stats/brews?"+tt.query)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp api.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if resp.Error.Code != api.CodeRangeTooLarge || len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != "date_from" {
+				t.Errorf("expected RANGE_TOO_LARGE on date_from, got %+v", resp.Error)
+			}
+		})
+	}
+}
+
+func TestSeries_MonthlyOverDecadesAllowed(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/brews?bucket=month&date_from=2000-01-01&date_to=2026-01-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestSeries_RepoError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/brews")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestSeries_Unauthorized(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/brews", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w.Code)
+	}
+}
+
+// --- Correlation Tests ---
+
+func sample(vars, outcomes map[string]float64) Sample {
+	return Sample{Variables: vars, Outcomes: outcomes}
+}
+
+func getCorrelations(t *testing.T, repo *mockRepo, query string) CorrelationResponse {
+	t.Helper()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/correlations"+query)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp CorrelationResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return resp
+}
+
+func findCorrelation(items []Correlation, variable, outcome string) *Correlation {
+	for i := range items {
+		if items[i].Variable == variable && items[i].Outcome == outcome {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func TestCorrelations_TemperatureAgainstScore(t *testing.T) {
+	repo := &mockRepo{}
+	for i, temp := range []float64{90, 92, 94, 96} {
+		repo.samples = append(repo.samples, sample(
+			map[string]float64{"water_temperature": temp},
+			map[string]float64{"overall_score": float64(5 + i)},
+		))
+	}
+
+	resp := getCorrelations(t, repo, "?coffee_id=c-1")
+
+	if repo.lastFilters.CoffeeID != "c-1" {
+		t.Errorf("expected coffee filter, got %q", repo.lastFilters.CoffeeID)
+	}
+	if resp.BrewCount != 4 || len(resp.Items) != len(Variables)*len(Outcomes) {
+		t.Fatalf("expected 4 brews and every pair, got %d and %d items", resp.BrewCount, len(resp.Items))
+	}
+
+	c := findCorrelation(resp.Items, "water_temperature", "overall_score")
+	if c.SampleSize != 4 {
+		t.Errorf("expected 4 samples, got %d", c.SampleSize)
+	}
+	if c.Pearson == nil || *c.Pearson != 1 || c.Spearman == nil || *c.Spearman != 1 {
+		t.Errorf("expected perfect correlation, got %v and %v", c.Pearson, c.Spearman)
+	}
+	if len(c.Bins) != 4 || c.Bins[2].Min != 94 || c.Bins[2].Mean == nil || *c.Bins[2].Mean != 7 {
+		t.Errorf("expected one bin per temperature, got %+v", c.Bins)
+	}
+
+	other := findCorrelation(resp.Items, "grind_size", "overall_score")
+	if other.SampleSize != 0 || other.Pearson != nil || len(other.Bins) != 0 {
+		t.Errorf("expected empty grind_size analysis, got %+v", other)
+	}
+}
+
+func TestCorrelations_SpearmanIgnoresScale(t *testing.T) {
+	repo := &mockRepo{}
+	for i, g := range []float64{1, 2, 3, 4, 10, 20} {
+		repo.samples = append(repo.samples, sample(
+			map[string]float64{"grind_size": g},
+			map[string]float64{"sweetness_intensity": float64(i + 1)},
+		))
+	}
+
+	resp := getCorrelations(t, repo, "")
+
+	c := findCorrelation(resp.Items, "grind_size", "sweetness_intensity")
+	if c.Spearman == nil || *c.Spearman != 1 {
+		t.Errorf("expected spearman 1, got %v", c.Spearman)
+	}
+	if c.Pearson == nil || *c.Pearson >= 1 {
+		t.Errorf("expected pearson below 1, got %v", c.Pearson)
+	}
+	if len(c.Bins) != MaxBins {
+		t.Fatalf("expected %d bins, got %d", MaxBins, len(c.Bins))
+	}
+	if c.Bins[0].SampleSize != 4 || c.Bins[MaxBins-1].Max != 20 || c.Bins[MaxBins-1].SampleSize != 1 {
+		t.Errorf("unexpected bins: %+v", c.Bins)
+	}
+	if c.Bins[1].Mean != nil {
+		t.Errorf("expected empty bin to have no mean, got %v", *c.Bins[1].Mean)
+	}
+}
+
+func TestCorrelations_TooFewSamplesOrNoVariance(t *testing.T) {
+	repo := &mockRepo{samples: []Sample{
+		sample(map[string]float64{"ratio": 15, "pour_count": 3}, map[string]float64{"overall_score": 6}),
+		sample(map[string]float64{"ratio": 16, "pour_count": 3}, map[string]float64{"overall_score": 7}),
+		sample(map[string]float64{"pour_count": 3}, map[string]float64{"overall_score": 8}),
+	}}
+
+	resp := getCorrelations(t, repo, "")
+
+	ratio := findCorrelation(resp.Items, "ratio", "overall_score")
+	if ratio.SampleSize != 2 || ratio.Pearson != nil || ratio.Spearman != nil {
+		t.Errorf("expected no coefficients from 2 samples, got %+v", ratio)
+	}
+	pours := findCorrelation(resp.Items, "pour_count", "overall_score")
+	if pours.SampleSize != 3 || pours.Pearson != nil {
+		t.Errorf("expected no coefficient without variance, got %+v", pours)
+	}
+	if len(pours.Bins) != 1 || *pours.Bins[0].Mean != 7 {
+		t.Errorf("expected a single bin with mean 7, got %+v", pours.Bins)
+	}
+}
+
+func TestCorrelations_InvalidDateRange(t *testing.T) {
+	h := NewHandler(&mockRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/correlations?date_from=2026-02-01&date_to=2026-01-01")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+}
+
+func TestCorrelations_RepoError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/stats/correlations")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+func TestSeriesBuckets(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	tests := []struct {
+		bucket   string
+		from, to string
+		want     int
+	}{
+		{"day", "2026-01-01", "2026-01-01", 1},
+		{"day", "2026-01-01", "2026-12-31", 365},
+		// Wed to the following Mon touches two Monday-start weeks.
+		{"week", "2026-01-07", "2026-01-12", 2},
+		{"week", "2026-01-05", "2026-01-11", 1},
+		{"month", "2025-11-30", "2026-02-01", 4},
+		{"day", "0001-01-01", "9999-12-31", 3652059},
+	}
+	for _, tt := range tests {
+		if got := SeriesBuckets(tt.bucket, date(tt.from), date(tt.to)); got != tt.want {
+			t.Errorf("SeriesBuckets(%s, %s, %s) = %d, want %d", tt.bucket, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
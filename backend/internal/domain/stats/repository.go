@@ -0,0 +1,12 @@
+package stats
+
+import (
+	"context"
+)
+
+type Repository interface {
+	// Series buckets the user's matching brews by brew date.
+	Series(ctx context.Context, userID string, params SeriesParams) ([]Point, error)
+	// Samples returns the variables and outcomes of the user's matching brews.
+	Samples(ctx context.Context, userID string, filters Filters) ([]Sample, error)
+}
@@ -0,0 +1,147 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/brewfilter"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+// extractionYieldSQL is brew.ComputeExtractionYield in SQL: the beverage mass
+// depends on the method, and immersion and cold brew use the brew water.
+const extractionYieldSQL = `(CASE b.method
+		WHEN 'espresso' THEN b.yield_weight
+		WHEN 'immersion' THEN COALESCE(b.coffee_weight * b.ratio, b.coffee_ml)
+		WHEN 'cold_brew' THEN COALESCE(b.coffee_weight * b.ratio, b.coffee_ml)
+		ELSE b.coffee_ml
+	END) * b.tds / NULLIF(b.coffee_weight, 0)`
+
+// Series aggregates in SQL and fills empty buckets with generate_series. The
+// series spans the date range when given, otherwise the first to last brew.
+func (r *PgRepository) Series(ctx context.Context, userID string, params SeriesParams) ([]Point, error) {
+	if !validBuckets[params.Bucket] {
+		return nil, fmt.Errorf("invalid bucket: %s", params.Bucket)
+	}
+
+	conditions, args, _ := brewfilter.Conditions(userID, params.Filters)
+	args = append(args, params.DateFrom, params.DateTo)
+	fromIdx, toIdx := len(args)-1, len(args)
+
+	query := fmt.Sprintf(
+		`WITH filtered AS (
+			SELECT date_trunc('%[1]s', b.brew_date::timestamp)::date AS bucket,
+				b.coffee_weight, b.overall_score, b.tds,
+				%[2]s AS extraction_yield
+			FROM brews b
+			WHERE %[3]s
+		), agg AS (
+			SELECT bucket,
+				COUNT(*) AS brew_count,
+				COALESCE(SUM(coffee_weight), 0) AS grams,
+				ROUND(AVG(overall_score), 2) AS avg_score,
+				ROUND(AVG(extraction_yield), 2) AS avg_ey,
+				ROUND(AVG(tds), 2) AS avg_tds
+			FROM filtered
+			GROUP BY bucket
+		), bounds AS (
+			SELECT COALESCE(date_trunc('%[1]s', $%[4]d::date::timestamp)::date, MIN(bucket)) AS lo,
+				COALESCE(date_trunc('%[1]s', $%[5]d::date::timestamp)::date, MAX(bucket)) AS hi
+			FROM filtered
+		)
+		SELECT s.bucket::date, COALESCE(a.brew_count, 0), COALESCE(a.grams, 0),
+			a.avg_score, a.avg_ey, a.avg_tds
+		FROM bounds
+		CROSS JOIN LATERAL generate_series(bounds.lo::timestamp, bounds.hi::timestamp, INTERVAL '1 %[1]s') AS s(bucket)
+		LEFT JOIN agg a ON a.bucket = s.bucket::date
+		ORDER BY s.bucket`,
+		params.Bucket, extractionYieldSQL, strings.Join(conditions, " AND "), fromIdx, toIdx,
+	)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []Point{}
+	for rows.Next() {
+		var p Point
+		var bucket time.Time
+		if err := rows.Scan(&bucket, &p.BrewCount, &p.GramsConsumed, &p.AvgScore, &p.AvgExtractionYield, &p.AvgTDS); err != nil {
+			return nil, err
+		}
+		p.BucketStart = bucket.Format("2006-01-02")
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// Samples selects columns in the order of Variables and then Outcomes.
+func (r *PgRepository) Samples(ctx context.Context, userID string, filters Filters) ([]Sample, error) {
+	conditions, args, _ := brewfilter.Conditions(userID, filters)
+
+	query := fmt.Sprintf(
+		`SELECT b.grind_size::float8, b.ratio::float8, b.water_temperature::float8,
+			b.total_brew_time::float8, b.days_off_roast::float8,
+			NULLIF(p.pour_count, 0)::float8, p.bloom_wait_time::float8,
+			b.overall_score::float8, b.aroma_intensity::float8, b.body_intensity::float8,
+			b.sweetness_intensity::float8, b.brightness_intensity::float8,
+			b.complexity_intensity::float8, b.aftertaste_intensity::float8
+		FROM brews b
+		CROSS JOIN LATERAL (
+			SELECT COUNT(*) AS pour_count,
+				MAX(bp.wait_time) FILTER (WHERE bp.pour_number = 1) AS bloom_wait_time
+			FROM brew_pours bp WHERE bp.brew_id = b.id
+		) p
+		WHERE %s`,
+		strings.Join(conditions, " AND "),
+	)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	samples := []Sample{}
+	for rows.Next() {
+		vars := make([]*float64, len(Variables))
+		outcomes := make([]*float64, len(Outcomes))
+		dest := make([]interface{}, 0, len(vars)+len(outcomes))
+		for i := range vars {
+			dest = append(dest, &vars[i])
+		}
+		for i := range outcomes {
+			dest = append(dest, &outcomes[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		s := Sample{Variables: map[string]float64{}, Outcomes: map[string]float64{}}
+		for i, name := range Variables {
+			if vars[i] != nil {
+				s.Variables[name] = *vars[i]
+			}
+		}
+		for i, name := range Outcomes {
+			if outcomes[i] != nil {
+				s.Outcomes[name] = *outcomes[i]
+			}
+		}
+		samples = append(samples, s)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,29 @@
+package stats
+
+import "time"
+
+// MaxSeriesBuckets caps the buckets a time series may span, about ten years
+// of days, so a wide date range can't make generate_series build millions of
+// rows.
+const MaxSeriesBuckets = 3660
+
+// SeriesBuckets counts the day, week or month buckets a series from from
+// through to covers. Weeks start on Monday, as with date_trunc.
+func SeriesBuckets(bucket string, from, to time.Time) int {
+	switch bucket {
+	case BucketMonth:
+		return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+	case BucketWeek:
+		from = from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+		to = to.AddDate(0, 0, -(int(to.Weekday())+6)%7)
+		return days(from, to)/7 + 1
+	default:
+		return days(from, to) + 1
+	}
+}
+
+// days counts whole days between two dates. It works on Unix seconds because
+// time.Duration saturates after about 292 years.
+func days(from, to time.Time) int {
+	return int((to.Unix() - from.Unix()) / (24 * 60 * 60))
+}
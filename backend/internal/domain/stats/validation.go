@@ -0,0 +1,66 @@
+package stats
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// validateSeriesParams checks the bucket size and that the date range, when
+// given, is made of YYYY-MM-DD dates in order.
+func validateSeriesParams(p SeriesParams) []api.FieldError {
+	var errs validate.Errors
+	if !validBuckets[p.Bucket] {
+		errs.Add("bucket", "Bucket must be one of: day, week, month")
+	}
+	validateDateRange(&errs, p.Filters)
+	return errs
+}
+
+// validateSeriesSpan checks that the series spans at most MaxSeriesBuckets
+// buckets. Without date_to the series ends at the latest brew, which is never
+// after today. Without date_from the series starts at the first brew, so the
+// span isn't known here. Call it once validateSeriesParams has passed.
+func validateSeriesSpan(p SeriesParams, today time.Time) []api.FieldError {
+	from, _ := parseDate(p.DateFrom)
+	if from == nil {
+		return nil
+	}
+	to := today
+	if t, _ := parseDate(p.DateTo); t != nil {
+		to = *t
+	}
+
+	var errs validate.Errors
+	if SeriesBuckets(p.Bucket, *from, to) > MaxSeriesBuckets {
+		errs.Add("date_from", fmt.Sprintf("Range must span at most %d %ss", MaxSeriesBuckets, p.Bucket))
+	}
+	return errs
+}
+
+func validateDateRange(errs *validate.Errors, f Filters) {
+	from, fromErr := parseDate(f.DateFrom)
+	if fromErr != nil {
+		errs.Add("date_from", "Must be a date in YYYY-MM-DD format")
+	}
+	to, toErr := parseDate(f.DateTo)
+	if toErr != nil {
+		errs.Add("date_to", "Must be a date in YYYY-MM-DD format")
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		errs.Add("date_to", "Must not be before date_from")
+	}
+}
+
+func parseDate(v *string) (*time.Time, error) {
+	if v == nil {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", *v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
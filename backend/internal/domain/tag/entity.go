@@ -0,0 +1,27 @@
+package tag
+
+import "time"
+
+// Tag is a user's label for coffees and brews, such as a flavor descriptor.
+// Names are unique per user ignoring case.
+type Tag struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"-"`
+	Name        string    `json:"name"`
+	CoffeeCount int       `json:"coffee_count"`
+	BrewCount   int       `json:"brew_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CreateRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateRequest struct {
+	Name string `json:"name"`
+}
+
+type SuggestionsResponse struct {
+	Items []string `json:"items"`
+}
@@ -0,0 +1,13 @@
+package tag
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound = domainerr.NotFound("Tag not found")
+	ErrConflict = domainerr.Conflict("A tag with this name already exists")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"idx_tags_user_name": ErrConflict,
+}
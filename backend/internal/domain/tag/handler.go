@@ -0,0 +1,153 @@
+package tag
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	pagination := api.ParsePagination(r)
+	search := strings.TrimSpace(r.URL.Query().Get("search"))
+
+	tags, total, err := h.repo.List(r.Context(), userID, pagination.Page, pagination.PerPage, search)
+	if err != nil {
+		log.Printf("error listing tags: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, api.PaginatedResponse{
+		Items: tags,
+		Pagination: api.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      total,
+			TotalPages: api.TotalPages(total, pagination.PerPage),
+		},
+	})
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	t, err := h.repo.GetByID(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error getting tag: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if t == nil {
+		api.NotFoundError(w, "Tag not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, t)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req CreateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	t, err := h.repo.Create(r.Context(), userID, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error creating tag: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, t)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req UpdateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(CreateRequest(req)); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	t, err := h.repo.Update(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error updating tag: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if t == nil {
+		api.NotFoundError(w, "Tag not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, t)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	err := h.repo.Delete(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error deleting tag: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) Suggestions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	items, err := h.repo.Suggestions(r.Context(), userID, query)
+	if err != nil {
+		log.Printf("error getting tag suggestions: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, SuggestionsResponse{Items: items})
+}
@@ -0,0 +1,470 @@
+package tag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockRepo struct {
+	tags   map[string]*Tag
+	nextID int
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{
+		tags:   make(map[string]*Tag),
+		nextID: 1,
+	}
+}
+
+// userTags returns the user's tags containing search, sorted by name.
+func (m *mockRepo) userTags(userID, search string) []Tag {
+	var result []Tag
+	for _, t := range m.tags {
+		if t.UserID != userID {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(t.Name), strings.ToLower(search)) {
+			continue
+		}
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i].Name) < strings.ToLower(result[j].Name)
+	})
+	return result
+}
+
+func (m *mockRepo) List(_ context.Context, userID string, page, perPage int, search string) ([]Tag, int, error) {
+	result := m.userTags(userID, search)
+	total := len(result)
+
+	offset := (page - 1) * perPage
+	if offset >= len(result) {
+		return []Tag{}, total, nil
+	}
+	end := offset + perPage
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*Tag, error) {
+	t := m.tags[id]
+	if t == nil || t.UserID != userID {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func (m *mockRepo) hasName(userID, name, exceptID string) bool {
+	for _, t := range m.tags {
+		if t.UserID == userID && t.ID != exceptID && strings.EqualFold(t.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (*Tag, error) {
+	if m.hasName(userID, req.Name, "") {
+		return nil, ErrConflict
+	}
+
+	m.nextID++
+	id := fmt.Sprintf("tag-%d", m.nextID)
+	now := time.Now()
+	t := &Tag{ID: id, UserID: userID, Name: req.Name, CreatedAt: now, UpdatedAt: now}
+	m.tags[id] = t
+	return t, nil
+}
+
+func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateRequest) (*Tag, error) {
+	t := m.tags[id]
+	if t == nil || t.UserID != userID {
+		return nil, nil
+	}
+	if m.hasName(userID, req.Name, id) {
+		return nil, ErrConflict
+	}
+	t.Name = req.Name
+	t.UpdatedAt = time.Now()
+	return t, nil
+}
+
+func (m *mockRepo) Delete(_ context.Context, userID, id string) error {
+	t := m.tags[id]
+	if t == nil || t.UserID != userID {
+		return ErrNotFound
+	}
+	delete(m.tags, id)
+	return nil
+}
+
+func (m *mockRepo) Suggestions(_ context.Context, userID, query string) ([]string, error) {
+	result := m.userTags(userID, query)
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].CoffeeCount+result[i].BrewCount > result[j].CoffeeCount+result[j].BrewCount
+	})
+	items := []string{}
+	for _, t := range result {
+		items = append(items, t.Name)
+	}
+	return items, nil
+}
+
+// Error-returning mock
+
+type errorRepo struct{}
+
+func (e *errorRepo) List(_ context.Context, _ string, _, _ int, _ string) ([]Tag, int, error) {
+	return nil, 0, errors.New("database error")
+}
+func (e *errorRepo) GetByID(_ context.Context, _, _ string) (*Tag, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Create(_ context.Context, _ string, _ CreateRequest) (*Tag, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Update(_ context.Context, _, _ string, _ UpdateRequest) (*Tag, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Delete(_ context.Context, _, _ string) error {
+	return errors.New("database error")
+}
+func (e *errorRepo) Suggestions(_ context.Context, _, _ string) ([]string, error) {
+	return nil, errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/tags", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Get("/suggestions", h.Suggestions)
+		r.Get("/{id}", h.GetByID)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
+	return r
+}
+
+func authRequest(method, url string, body string) *http.Request {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, url, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func seedTag(repo *mockRepo, id, userID, name string) *Tag {
+	now := time.Now()
+	t := &Tag{ID: id, UserID: userID, Name: name, CreatedAt: now, UpdatedAt: now}
+	repo.tags[id] = t
+	return t
+}
+
+// --- List Tests ---
+
+func TestList_ExcludesOtherUsers(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "user-123", "Jasmine")
+	seedTag(repo, "t-2", "user-123", "blueberry")
+	seedTag(repo, "t-3", "other-user", "Cacao")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/tags", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items      []Tag              `json:"items"`
+		Pagination api.PaginationMeta `json:"pagination"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 2 || resp.Items[0].Name != "blueberry" || resp.Items[1].Name != "Jasmine" {
+		t.Errorf("expected blueberry, Jasmine, got %v", resp.Items)
+	}
+	if resp.Pagination.Total != 2 {
+		t.Errorf("expected total 2, got %d", resp.Pagination.Total)
+	}
+}
+
+func TestList_Search(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "user-123", "Jasmine")
+	seedTag(repo, "t-2", "user-123", "Blueberry")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/tags?search=BERRY", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp struct {
+		Items []Tag `json:"items"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].Name != "Blueberry" {
+		t.Errorf("expected only Blueberry, got %v", resp.Items)
+	}
+}
+
+func TestList_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/tags", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
+
+// --- GetByID Tests ---
+
+func TestGetByID_OtherUserTag(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "other-user", "Cacao")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/tags/t-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Create Tests ---
+
+func TestCreate_Success(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/tags", `{"name":"  Blueberry "}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var tag Tag
+	json.Unmarshal(w.Body.Bytes(), &tag)
+	if tag.Name != "Blueberry" {
+		t.Errorf("expected trimmed name, got %q", tag.Name)
+	}
+}
+
+func TestCreate_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing name", `{}`},
+		{"blank name", `{"name":"   "}`},
+		{"comma", `{"name":"lemon, lime"}`},
+		{"too long", `{"name":"` + strings.Repeat("a", 101) + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(newMockRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/tags", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d", w.Code)
+			}
+			if !strings.Contains(w.Body.String(), `"field":"name"`) {
+				t.Errorf("expected error on name, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreate_DuplicateNameIgnoresCase(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "user-123", "Blueberry")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/tags", `{"name":"blueberry"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// --- Update Tests ---
+
+func TestUpdate_Renames(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "user-123", "blueberry")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/tags/t-1", `{"name":"Blueberry"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if repo.tags["t-1"].Name != "Blueberry" {
+		t.Errorf("expected rename to Blueberry, got %q", repo.tags["t-1"].Name)
+	}
+}
+
+func TestUpdate_Conflict(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "user-123", "Blueberry")
+	seedTag(repo, "t-2", "user-123", "Blackberry")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/tags/t-2", `{"name":"BLUEBERRY"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", w.Code)
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/tags/missing", `{"name":"Cacao"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Delete Tests ---
+
+func TestDelete_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "user-123", "Cacao")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/tags/t-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if _, ok := repo.tags["t-1"]; ok {
+		t.Error("expected tag to be deleted")
+	}
+}
+
+func TestDelete_NotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/tags/missing", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Suggestions Tests ---
+
+func TestSuggestions_MostUsedFirst(t *testing.T) {
+	repo := newMockRepo()
+	seedTag(repo, "t-1", "user-123", "Blackberry")
+	seedTag(repo, "t-2", "user-123", "Blueberry").CoffeeCount = 3
+	seedTag(repo, "t-3", "user-123", "Jasmine")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/tags/suggestions?q=berry", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var resp SuggestionsResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 2 || resp.Items[0] != "Blueberry" || resp.Items[1] != "Blackberry" {
+		t.Errorf("expected Blueberry, Blackberry, got %v", resp.Items)
+	}
+}
+
+func TestSuggestions_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/tags/suggestions?q=a", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+}
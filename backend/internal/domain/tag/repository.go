@@ -0,0 +1,18 @@
+package tag
+
+import (
+	"context"
+)
+
+type Repository interface {
+	// List returns the user's tags by name, optionally only those whose name
+	// contains search.
+	List(ctx context.Context, userID string, page, perPage int, search string) ([]Tag, int, error)
+	GetByID(ctx context.Context, userID, id string) (*Tag, error)
+	Create(ctx context.Context, userID string, req CreateRequest) (*Tag, error)
+	Update(ctx context.Context, userID, id string, req UpdateRequest) (*Tag, error)
+	Delete(ctx context.Context, userID, id string) error
+
+	// Suggestions returns tag names containing query, most used first.
+	Suggestions(ctx context.Context, userID, query string) ([]string, error)
+}
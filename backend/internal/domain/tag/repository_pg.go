@@ -0,0 +1,168 @@
+package tag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+const coffeeCountSubquery = `(SELECT COUNT(*) FROM coffee_tags WHERE coffee_tags.tag_id = t.id)`
+const brewCountSubquery = `(SELECT COUNT(*) FROM brew_tags WHERE brew_tags.tag_id = t.id)`
+
+const tagColumns = `t.id, t.user_id, t.name, ` + coffeeCountSubquery + ` AS coffee_count,
+	` + brewCountSubquery + ` AS brew_count, t.created_at, t.updated_at`
+
+func scanTag(row pgx.Row) (*Tag, error) {
+	var t Tag
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.CoffeeCount, &t.BrewCount, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PgRepository) List(ctx context.Context, userID string, page, perPage int, search string) ([]Tag, int, error) {
+	where := "t.user_id = $1"
+	args := []interface{}{userID}
+	if search != "" {
+		where += " AND t.name ILIKE $2"
+		args = append(args, "%"+search+"%")
+	}
+
+	var total int
+	err := r.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT COUNT(*) FROM tags t WHERE %s`, where),
+		args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s
+		 FROM tags t
+		 WHERE %s
+		 ORDER BY LOWER(t.name)
+		 LIMIT $%d OFFSET $%d`,
+		tagColumns, where, len(args)+1, len(args)+2,
+	)
+	args = append(args, perPage, (page-1)*perPage)
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		t, err := scanTag(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		tags = append(tags, *t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return tags, total, nil
+}
+
+func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*Tag, error) {
+	t, err := scanTag(r.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT %s FROM tags t WHERE t.id = $1 AND t.user_id = $2`, tagColumns),
+		id, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequest) (*Tag, error) {
+	var id string
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO tags (user_id, name) VALUES ($1, $2) RETURNING id`,
+		userID, req.Name,
+	).Scan(&id)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+// Update renames the tag everywhere it is used.
+func (r *PgRepository) Update(ctx context.Context, userID, id string, req UpdateRequest) (*Tag, error) {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE tags SET name = $1, updated_at = NOW() WHERE id = $2 AND user_id = $3`,
+		req.Name, id, userID,
+	)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+// Delete also removes the tag from every coffee and brew.
+func (r *PgRepository) Delete(ctx context.Context, userID, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`DELETE FROM tags WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PgRepository) Suggestions(ctx context.Context, userID, query string) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		fmt.Sprintf(
+			`SELECT t.name FROM tags t
+			 WHERE t.user_id = $1 AND t.name ILIKE $2
+			 ORDER BY %s + %s DESC, LOWER(t.name)
+			 LIMIT 20`,
+			coffeeCountSubquery, brewCountSubquery,
+		),
+		userID, "%"+query+"%",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		items = append(items, name)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,13 @@
+package tag
+
+import (
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/tags"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+func validateRequest(req CreateRequest) []api.FieldError {
+	var errs validate.Errors
+	tags.ValidateName(&errs, "name", req.Name)
+	return errs
+}
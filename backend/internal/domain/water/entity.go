@@ -0,0 +1,84 @@
+package water
+
+import (
+	"time"
+)
+
+// WaterProfile is a named brewing water. It can be described by its measured
+// hardness and mineral composition, by a base water plus mineral concentrate
+// additions, or both. Hardness values are ppm as CaCO3; ion concentrations
+// are mg/L.
+type WaterProfile struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"-"`
+	Name        string     `json:"name"`
+	Notes       *string    `json:"notes"`
+	GH          *float64   `json:"gh"`
+	KH          *float64   `json:"kh"`
+	TDS         *float64   `json:"tds"`
+	Calcium     *float64   `json:"calcium"`
+	Magnesium   *float64   `json:"magnesium"`
+	Sodium      *float64   `json:"sodium"`
+	Bicarbonate *float64   `json:"bicarbonate"`
+	BaseWater   *string    `json:"base_water"`
+	Additions   []Addition `json:"additions"`
+	DeletedAt   *time.Time `json:"-"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Addition is a dose of mineral concentrate added to the base water, per
+// litre of base water.
+type Addition struct {
+	Concentrate string  `json:"concentrate"`
+	Amount      float64 `json:"amount"`
+	Unit        string  `json:"unit"`
+}
+
+// Addition units.
+const (
+	UnitMlPerLitre    = "ml_per_l"
+	UnitGPerLitre     = "g_per_l"
+	UnitDropsPerLitre = "drops_per_l"
+)
+
+// Validation limits. Brewing water rarely exceeds a few hundred ppm of
+// anything; these bounds only catch unit mix-ups.
+const (
+	MaxPPM            = 1000
+	MaxAdditionAmount = 1000
+)
+
+var validUnits = map[string]bool{
+	UnitMlPerLitre:    true,
+	UnitGPerLitre:     true,
+	UnitDropsPerLitre: true,
+}
+
+type CreateRequest struct {
+	Name        string     `json:"name"`
+	Notes       *string    `json:"notes"`
+	GH          *float64   `json:"gh"`
+	KH          *float64   `json:"kh"`
+	TDS         *float64   `json:"tds"`
+	Calcium     *float64   `json:"calcium"`
+	Magnesium   *float64   `json:"magnesium"`
+	Sodium      *float64   `json:"sodium"`
+	Bicarbonate *float64   `json:"bicarbonate"`
+	BaseWater   *string    `json:"base_water"`
+	Additions   []Addition `json:"additions"`
+}
+
+type UpdateRequest struct {
+	Name        string     `json:"name"`
+	Notes       *string    `json:"notes"`
+	GH          *float64   `json:"gh"`
+	KH          *float64   `json:"kh"`
+	TDS         *float64   `json:"tds"`
+	Calcium     *float64   `json:"calcium"`
+	Magnesium   *float64   `json:"magnesium"`
+	Sodium      *float64   `json:"sodium"`
+	Bicarbonate *float64   `json:"bicarbonate"`
+	BaseWater   *string    `json:"base_water"`
+	Additions   []Addition `json:"additions"`
+}
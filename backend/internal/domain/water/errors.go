@@ -0,0 +1,13 @@
+package water
+
+import "github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+
+var (
+	ErrNotFound = domainerr.NotFound("Water profile not found")
+	ErrConflict = domainerr.Conflict("A water profile with this name already exists")
+)
+
+// pgErrors maps constraint names to the errors they represent.
+var pgErrors = map[string]error{
+	"idx_water_profiles_user_name": ErrConflict,
+}
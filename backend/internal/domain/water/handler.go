@@ -0,0 +1,171 @@
+package water
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+type Handler struct {
+	repo Repository
+}
+
+func NewHandler(repo Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	pagination := api.ParsePagination(r)
+	sort := r.URL.Query().Get("sort")
+
+	profiles, total, err := h.repo.List(r.Context(), userID, pagination.Page, pagination.PerPage, sort)
+	if err != nil {
+		log.Printf("error listing water profiles: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, api.PaginatedResponse{
+		Items: profiles,
+		Pagination: api.PaginationMeta{
+			Page:       pagination.Page,
+			PerPage:    pagination.PerPage,
+			Total:      total,
+			TotalPages: api.TotalPages(total, pagination.PerPage),
+		},
+	})
+}
+
+func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	p, err := h.repo.GetByID(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("error getting water profile: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if p == nil {
+		api.NotFoundError(w, "Water profile not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+
+	var req CreateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(req); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	p, err := h.repo.Create(r.Context(), userID, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error creating water profile: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	api.WriteJSON(w, http.StatusCreated, p)
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	var req UpdateRequest
+	if err := api.DecodeJSON(r, &req); err != nil {
+		api.ValidationError(w, []api.FieldError{{Field: "body", Message: "Invalid request body"}})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validateRequest(CreateRequest(req)); len(fieldErrors) > 0 {
+		api.ValidationError(w, fieldErrors)
+		return
+	}
+
+	p, err := h.repo.Update(r.Context(), userID, id, req)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error updating water profile: %v", err)
+		api.InternalError(w)
+		return
+	}
+	if p == nil {
+		api.NotFoundError(w, "Water profile not found")
+		return
+	}
+
+	api.WriteJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r.Context())
+	id := chi.URLParam(r, "id")
+
+	err := h.repo.SoftDelete(r.Context(), userID, id)
+	if err != nil {
+		if api.DomainError(w, err) {
+			return
+		}
+		log.Printf("error deleting water profile: %v", err)
+		api.InternalError(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateRequest checks the name, that mineral values are within a
+// plausible range, and that each concentrate addition is complete.
+func validateRequest(req CreateRequest) []api.FieldError {
+	var errs validate.Errors
+	if req.Name == "" {
+		errs.Add("name", "Name is required")
+	}
+
+	errs.FloatRange("gh", req.GH, 0, MaxPPM)
+	errs.FloatRange("kh", req.KH, 0, MaxPPM)
+	errs.FloatRange("tds", req.TDS, 0, MaxPPM)
+	errs.FloatRange("calcium", req.Calcium, 0, MaxPPM)
+	errs.FloatRange("magnesium", req.Magnesium, 0, MaxPPM)
+	errs.FloatRange("sodium", req.Sodium, 0, MaxPPM)
+	errs.FloatRange("bicarbonate", req.Bicarbonate, 0, MaxPPM)
+
+	for i, a := range req.Additions {
+		prefix := fmt.Sprintf("additions[%d]", i)
+		if strings.TrimSpace(a.Concentrate) == "" {
+			errs.Add(prefix+".concentrate", "Concentrate is required")
+		}
+		errs.Positive(prefix+".amount", &a.Amount, MaxAdditionAmount)
+		if !validUnits[a.Unit] {
+			errs.Add(prefix+".unit", "must be one of ml_per_l, g_per_l, drops_per_l")
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,427 @@
+package water
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+	"github.com/poimgs/coffee-tracker/backend/internal/middleware"
+)
+
+const testSecret = "test-jwt-secret-key"
+
+// --- Mock Repository ---
+
+type mockRepo struct {
+	profiles map[string]*WaterProfile
+	nextID   int
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{profiles: make(map[string]*WaterProfile), nextID: 1}
+}
+
+func (m *mockRepo) List(_ context.Context, userID string, page, perPage int, sort string) ([]WaterProfile, int, error) {
+	var result []WaterProfile
+	for _, p := range m.profiles {
+		if p.UserID == userID && p.DeletedAt == nil {
+			result = append(result, *p)
+		}
+	}
+	total := len(result)
+
+	offset := (page - 1) * perPage
+	if offset >= len(result) {
+		return []WaterProfile{}, total, nil
+	}
+	end := offset + perPage
+	if end > len(result) {
+		end = len(result)
+	}
+	return result[offset:end], total, nil
+}
+
+func (m *mockRepo) GetByID(_ context.Context, userID, id string) (*WaterProfile, error) {
+	p := m.profiles[id]
+	if p == nil || p.UserID != userID || p.DeletedAt != nil {
+		return nil, nil
+	}
+	return p, nil
+}
+
+func (m *mockRepo) Create(_ context.Context, userID string, req CreateRequest) (*WaterProfile, error) {
+	// Check for duplicate name
+	for _, p := range m.profiles {
+		if p.UserID == userID && p.Name == req.Name && p.DeletedAt == nil {
+			return nil, ErrConflict
+		}
+	}
+
+	m.nextID++
+	id := fmt.Sprintf("wp-%d", m.nextID)
+	now := time.Now()
+	p := &WaterProfile{ID: id, UserID: userID, CreatedAt: now}
+	m.apply(p, UpdateRequest(req))
+	m.profiles[id] = p
+	return p, nil
+}
+
+func (m *mockRepo) Update(_ context.Context, userID, id string, req UpdateRequest) (*WaterProfile, error) {
+	p := m.profiles[id]
+	if p == nil || p.UserID != userID || p.DeletedAt != nil {
+		return nil, nil
+	}
+
+	// Check for duplicate name (excluding self)
+	for _, other := range m.profiles {
+		if other.UserID == userID && other.Name == req.Name && other.ID != id && other.DeletedAt == nil {
+			return nil, ErrConflict
+		}
+	}
+
+	m.apply(p, req)
+	return p, nil
+}
+
+func (m *mockRepo) apply(p *WaterProfile, req UpdateRequest) {
+	p.Name = req.Name
+	p.Notes = req.Notes
+	p.GH, p.KH, p.TDS = req.GH, req.KH, req.TDS
+	p.Calcium, p.Magnesium, p.Sodium, p.Bicarbonate = req.Calcium, req.Magnesium, req.Sodium, req.Bicarbonate
+	p.BaseWater = req.BaseWater
+	p.Additions = req.Additions
+	if p.Additions == nil {
+		p.Additions = []Addition{}
+	}
+	p.UpdatedAt = time.Now()
+}
+
+func (m *mockRepo) SoftDelete(_ context.Context, userID, id string) error {
+	p := m.profiles[id]
+	if p == nil || p.UserID != userID || p.DeletedAt != nil {
+		return ErrNotFound
+	}
+	now := time.Now()
+	p.DeletedAt = &now
+	return nil
+}
+
+// Error-returning mock
+
+type errorRepo struct{}
+
+func (e *errorRepo) List(_ context.Context, _ string, _, _ int, _ string) ([]WaterProfile, int, error) {
+	return nil, 0, errors.New("database error")
+}
+func (e *errorRepo) GetByID(_ context.Context, _, _ string) (*WaterProfile, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Create(_ context.Context, _ string, _ CreateRequest) (*WaterProfile, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) Update(_ context.Context, _, _ string, _ UpdateRequest) (*WaterProfile, error) {
+	return nil, errors.New("database error")
+}
+func (e *errorRepo) SoftDelete(_ context.Context, _, _ string) error {
+	return errors.New("database error")
+}
+
+// --- Helpers ---
+
+func generateTestAccessToken(userID string) string {
+	claims := jwt.MapClaims{
+		"sub":   userID,
+		"email": "test@example.com",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	s, _ := token.SignedString([]byte(testSecret))
+	return s
+}
+
+func setupRouter(h *Handler) *chi.Mux {
+	r := chi.NewRouter()
+	r.Route("/api/v1/water-profiles", func(r chi.Router) {
+		r.Use(middleware.RequireAuth(testSecret))
+		r.Get("/", h.List)
+		r.Post("/", h.Create)
+		r.Get("/{id}", h.GetByID)
+		r.Put("/{id}", h.Update)
+		r.Delete("/{id}", h.Delete)
+	})
+	return r
+}
+
+func authRequest(method, url string, body string) *http.Request {
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req = httptest.NewRequest(method, url, nil)
+	}
+	req.Header.Set("Authorization", "Bearer "+generateTestAccessToken("user-123"))
+	return req
+}
+
+func seedProfile(repo *mockRepo, id, userID, name string) *WaterProfile {
+	now := time.Now()
+	p := &WaterProfile{
+		ID:        id,
+		UserID:    userID,
+		Name:      name,
+		Additions: []Addition{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	repo.profiles[id] = p
+	return p
+}
+
+// --- List Tests ---
+
+func TestList_ExcludesSoftDeleted(t *testing.T) {
+	repo := newMockRepo()
+	seedProfile(repo, "wp-1", "user-123", "Third Wave Water")
+	deleted := seedProfile(repo, "wp-2", "user-123", "Tap")
+	now := time.Now()
+	deleted.DeletedAt = &now
+	seedProfile(repo, "wp-3", "other-user", "Theirs")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/water-profiles", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Items      []WaterProfile     `json:"items"`
+		Pagination api.PaginationMeta `json:"pagination"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Items) != 1 || resp.Items[0].Name != "Third Wave Water" {
+		t.Errorf("expected only Third Wave Water, got %v", resp.Items)
+	}
+	if resp.Pagination.Total != 1 {
+		t.Errorf("expected total 1, got %d", resp.Pagination.Total)
+	}
+}
+
+func TestList_DatabaseError(t *testing.T) {
+	h := NewHandler(&errorRepo{})
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/water-profiles", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", w.Code)
+	}
+}
+
+// --- GetByID Tests ---
+
+func TestGetByID_OtherUserProfile(t *testing.T) {
+	repo := newMockRepo()
+	seedProfile(repo, "wp-1", "other-user", "Theirs")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodGet, "/api/v1/water-profiles/wp-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Create Tests ---
+
+func TestCreate_Composition(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":" Rao/Perger ","gh":68,"kh":40,"tds":110,"calcium":17.5,"magnesium":6,"bicarbonate":48.8}`
+	req := authRequest(http.MethodPost, "/api/v1/water-profiles", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var p WaterProfile
+	json.Unmarshal(w.Body.Bytes(), &p)
+	if p.Name != "Rao/Perger" {
+		t.Errorf("expected trimmed name, got %q", p.Name)
+	}
+	if p.GH == nil || *p.GH != 68 || p.KH == nil || *p.KH != 40 {
+		t.Errorf("expected GH 68 / KH 40, got %v / %v", p.GH, p.KH)
+	}
+}
+
+func TestCreate_BaseWaterWithAdditions(t *testing.T) {
+	repo := newMockRepo()
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	body := `{"name":"Lotus light","base_water":"distilled","additions":[
+		{"concentrate":"Lotus Calcium","amount":0.5,"unit":"ml_per_l"},
+		{"concentrate":"Lotus Bicarbonate","amount":4,"unit":"drops_per_l"}
+	]}`
+	req := authRequest(http.MethodPost, "/api/v1/water-profiles", body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var p WaterProfile
+	json.Unmarshal(w.Body.Bytes(), &p)
+	if p.BaseWater == nil || *p.BaseWater != "distilled" {
+		t.Errorf("expected base water distilled, got %v", p.BaseWater)
+	}
+	if len(p.Additions) != 2 || p.Additions[1].Unit != UnitDropsPerLitre {
+		t.Errorf("unexpected additions: %+v", p.Additions)
+	}
+}
+
+func TestCreate_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{"empty name", `{"name":""}`, "name"},
+		{"negative gh", `{"name":"Tap","gh":-5}`, "gh"},
+		{"implausible tds", `{"name":"Tap","tds":5000}`, "tds"},
+		{"addition without concentrate", `{"name":"Mix","additions":[{"concentrate":"","amount":1,"unit":"ml_per_l"}]}`, "additions[0].concentrate"},
+		{"zero amount", `{"name":"Mix","additions":[{"concentrate":"Epsom","amount":0,"unit":"g_per_l"}]}`, "additions[0].amount"},
+		{"bad unit", `{"name":"Mix","additions":[{"concentrate":"Epsom","amount":1,"unit":"tsp"}]}`, "additions[0].unit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(newMockRepo())
+			router := setupRouter(h)
+
+			req := authRequest(http.MethodPost, "/api/v1/water-profiles", tt.body)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var resp api.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if len(resp.Error.Details) != 1 || resp.Error.Details[0].Field != tt.wantField {
+				t.Errorf("expected %s error, got %v", tt.wantField, resp.Error.Details)
+			}
+		})
+	}
+}
+
+func TestCreate_DuplicateName(t *testing.T) {
+	repo := newMockRepo()
+	seedProfile(repo, "wp-1", "user-123", "Tap")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPost, "/api/v1/water-profiles", `{"name":"Tap"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// --- Update Tests ---
+
+func TestUpdate_ReplacesAdditions(t *testing.T) {
+	repo := newMockRepo()
+	p := seedProfile(repo, "wp-1", "user-123", "Mix")
+	p.Additions = []Addition{{Concentrate: "Epsom", Amount: 0.1, Unit: UnitGPerLitre}}
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/water-profiles/wp-1", `{"name":"Mix","kh":20}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var got WaterProfile
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if len(got.Additions) != 0 || got.KH == nil || *got.KH != 20 {
+		t.Errorf("expected additions cleared and KH 20, got %+v", got)
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodPut, "/api/v1/water-profiles/missing", `{"name":"Tap"}`)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+// --- Delete Tests ---
+
+func TestDelete_Success(t *testing.T) {
+	repo := newMockRepo()
+	seedProfile(repo, "wp-1", "user-123", "Tap")
+	h := NewHandler(repo)
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/water-profiles/wp-1", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if repo.profiles["wp-1"].DeletedAt == nil {
+		t.Error("expected profile to be soft-deleted")
+	}
+}
+
+func TestDelete_NotFound(t *testing.T) {
+	h := NewHandler(newMockRepo())
+	router := setupRouter(h)
+
+	req := authRequest(http.MethodDelete, "/api/v1/water-profiles/missing", "")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
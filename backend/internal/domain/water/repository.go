@@ -0,0 +1,13 @@
+package water
+
+import (
+	"context"
+)
+
+type Repository interface {
+	List(ctx context.Context, userID string, page, perPage int, sort string) ([]WaterProfile, int, error)
+	GetByID(ctx context.Context, userID, id string) (*WaterProfile, error)
+	Create(ctx context.Context, userID string, req CreateRequest) (*WaterProfile, error)
+	Update(ctx context.Context, userID, id string, req UpdateRequest) (*WaterProfile, error)
+	SoftDelete(ctx context.Context, userID, id string) error
+}
@@ -0,0 +1,275 @@
+package water
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+)
+
+type PgRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPgRepository(pool *pgxpool.Pool) *PgRepository {
+	return &PgRepository{pool: pool}
+}
+
+const profileColumns = `id, user_id, name, notes,
+	gh, kh, tds, calcium, magnesium, sodium, bicarbonate, base_water,
+	created_at, updated_at`
+
+var allowedSortFields = map[string]string{
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func parseSortParam(sort string) string {
+	if sort == "" {
+		sort = "-created_at"
+	}
+
+	desc := false
+	field := sort
+	if strings.HasPrefix(sort, "-") {
+		desc = true
+		field = sort[1:]
+	}
+
+	col, ok := allowedSortFields[field]
+	if !ok {
+		col = "created_at"
+		desc = true
+	}
+
+	if desc {
+		return col + " DESC"
+	}
+	return col + " ASC"
+}
+
+func scanProfile(row pgx.Row) (*WaterProfile, error) {
+	var p WaterProfile
+	err := row.Scan(
+		&p.ID, &p.UserID, &p.Name, &p.Notes,
+		&p.GH, &p.KH, &p.TDS, &p.Calcium, &p.Magnesium, &p.Sodium, &p.Bicarbonate, &p.BaseWater,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PgRepository) loadAdditions(ctx context.Context, profileIDs []string) (map[string][]Addition, error) {
+	result := make(map[string][]Addition)
+	if len(profileIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT water_profile_id, concentrate, amount, unit
+		 FROM water_profile_additions WHERE water_profile_id = ANY($1) ORDER BY water_profile_id, position`,
+		profileIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var profileID string
+		var a Addition
+		if err := rows.Scan(&profileID, &a.Concentrate, &a.Amount, &a.Unit); err != nil {
+			return nil, err
+		}
+		result[profileID] = append(result[profileID], a)
+	}
+	return result, rows.Err()
+}
+
+func saveAdditions(ctx context.Context, tx pgx.Tx, profileID string, additions []Addition) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM water_profile_additions WHERE water_profile_id = $1`, profileID); err != nil {
+		return err
+	}
+
+	for i, a := range additions {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO water_profile_additions (water_profile_id, position, concentrate, amount, unit)
+			 VALUES ($1, $2, $3, $4, $5)`,
+			profileID, i+1, a.Concentrate, a.Amount, a.Unit,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PgRepository) List(ctx context.Context, userID string, page, perPage int, sort string) ([]WaterProfile, int, error) {
+	var total int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM water_profiles WHERE user_id = $1 AND deleted_at IS NULL`,
+		userID,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := fmt.Sprintf(
+		`SELECT %s
+		 FROM water_profiles
+		 WHERE user_id = $1 AND deleted_at IS NULL
+		 ORDER BY %s
+		 LIMIT $2 OFFSET $3`,
+		profileColumns, parseSortParam(sort),
+	)
+
+	rows, err := r.pool.Query(ctx, query, userID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var profiles []WaterProfile
+	var profileIDs []string
+	for rows.Next() {
+		p, err := scanProfile(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		profileIDs = append(profileIDs, p.ID)
+		profiles = append(profiles, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	additions, err := r.loadAdditions(ctx, profileIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range profiles {
+		profiles[i].Additions = additions[profiles[i].ID]
+		if profiles[i].Additions == nil {
+			profiles[i].Additions = []Addition{}
+		}
+	}
+
+	if profiles == nil {
+		profiles = []WaterProfile{}
+	}
+
+	return profiles, total, nil
+}
+
+func (r *PgRepository) GetByID(ctx context.Context, userID, id string) (*WaterProfile, error) {
+	p, err := scanProfile(r.pool.QueryRow(ctx,
+		fmt.Sprintf(`SELECT %s FROM water_profiles WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, profileColumns),
+		id, userID,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	additions, err := r.loadAdditions(ctx, []string{p.ID})
+	if err != nil {
+		return nil, err
+	}
+	p.Additions = additions[p.ID]
+	if p.Additions == nil {
+		p.Additions = []Addition{}
+	}
+
+	return p, nil
+}
+
+func (r *PgRepository) Create(ctx context.Context, userID string, req CreateRequest) (*WaterProfile, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO water_profiles (user_id, name, notes,
+			gh, kh, tds, calcium, magnesium, sodium, bicarbonate, base_water)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		 RETURNING id`,
+		userID, req.Name, req.Notes,
+		req.GH, req.KH, req.TDS, req.Calcium, req.Magnesium, req.Sodium, req.Bicarbonate, req.BaseWater,
+	).Scan(&id)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+
+	if err := saveAdditions(ctx, tx, id, req.Additions); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+func (r *PgRepository) Update(ctx context.Context, userID, id string, req UpdateRequest) (*WaterProfile, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`UPDATE water_profiles
+		 SET name = $1, notes = $2, gh = $3, kh = $4, tds = $5,
+			calcium = $6, magnesium = $7, sodium = $8, bicarbonate = $9, base_water = $10,
+			updated_at = NOW()
+		 WHERE id = $11 AND user_id = $12 AND deleted_at IS NULL`,
+		req.Name, req.Notes, req.GH, req.KH, req.TDS,
+		req.Calcium, req.Magnesium, req.Sodium, req.Bicarbonate, req.BaseWater,
+		id, userID,
+	)
+	if err != nil {
+		return nil, domainerr.FromPg(err, pgErrors)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, nil
+	}
+
+	if err := saveAdditions(ctx, tx, id, req.Additions); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, userID, id)
+}
+
+func (r *PgRepository) SoftDelete(ctx context.Context, userID, id string) error {
+	result, err := r.pool.Exec(ctx,
+		`UPDATE water_profiles SET deleted_at = NOW(), updated_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Package domainerr defines the error kinds shared by the domain packages.
+// Repositories return these instead of driver errors so handlers can map them
+// to HTTP responses without inspecting error strings.
+package domainerr
+
+import "errors"
+
+// Kinds. Domain errors wrap exactly one of these, so callers can test the
+// category with errors.Is regardless of which package produced the error.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrConflict         = errors.New("conflict")
+	ErrInvalidReference = errors.New("invalid reference")
+	ErrInvalid          = errors.New("invalid")
+)
+
+// Error is a domain error with a user-facing message. Field names the request
+// field at fault, if any.
+type Error struct {
+	Kind    error
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Field != "" {
+		return e.Field + ": " + e.Message
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+func InvalidReference(field, message string) *Error {
+	return &Error{Kind: ErrInvalidReference, Field: field, Message: message}
+}
+
+func Invalid(field, message string) *Error {
+	return &Error{Kind: ErrInvalid, Field: field, Message: message}
+}
@@ -0,0 +1,73 @@
+package domainerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres SQLSTATE codes for constraint violations.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+)
+
+// FromPg translates Postgres constraint violations into domain errors.
+// known maps constraint or index names to the error to return for them;
+// other violations fall back to a generic error of the matching kind. Errors
+// that are not constraint violations are returned unchanged.
+func FromPg(err error, known map[string]error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	if e, ok := known[pgErr.ConstraintName]; ok {
+		return e
+	}
+
+	field := constraintField(pgErr.TableName, pgErr.ConstraintName)
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		return Conflict("Resource already exists")
+	case pgForeignKeyViolation:
+		return InvalidReference(field, "Referenced record not found")
+	case pgCheckViolation:
+		return Invalid(field, "Value out of range")
+	}
+	return err
+}
+
+// CheckOwned returns missing unless table holds a row with the given ID that
+// belongs to the user and is not soft-deleted. Foreign keys can't tell whose
+// record an ID points at, so references to a user's equipment are checked
+// this way before writing. IDs are compared as text so a malformed UUID reads
+// as missing instead of failing the cast.
+func CheckOwned(ctx context.Context, tx pgx.Tx, table, userID, id string, missing error) error {
+	var exists bool
+	err := tx.QueryRow(ctx,
+		fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE id::text = $1 AND user_id = $2 AND deleted_at IS NULL)`, table),
+		id, userID,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return missing
+	}
+	return nil
+}
+
+// constraintField recovers the column from Postgres' default constraint names,
+// e.g. brews_aroma_intensity_check -> aroma_intensity.
+func constraintField(table, constraint string) string {
+	name := strings.TrimPrefix(constraint, table+"_")
+	for _, suffix := range []string{"_check", "_fkey"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	return name
+}
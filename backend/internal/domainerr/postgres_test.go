@@ -0,0 +1,63 @@
+package domainerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestFromPg_KnownConstraint(t *testing.T) {
+	errDuplicate := Conflict("A dripper with this name already exists")
+	pgErr := &pgconn.PgError{Code: pgUniqueViolation, TableName: "drippers", ConstraintName: "idx_drippers_user_name"}
+
+	err := FromPg(fmt.Errorf("inserting: %w", pgErr), map[string]error{"idx_drippers_user_name": errDuplicate})
+	if err != errDuplicate {
+		t.Errorf("expected known error, got %v", err)
+	}
+}
+
+func TestFromPg_CheckViolation(t *testing.T) {
+	pgErr := &pgconn.PgError{Code: pgCheckViolation, TableName: "brews", ConstraintName: "brews_aroma_intensity_check"}
+
+	err := FromPg(pgErr, nil)
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+	var de *Error
+	if !errors.As(err, &de) || de.Field != "aroma_intensity" {
+		t.Errorf("expected field aroma_intensity, got %+v", de)
+	}
+}
+
+func TestFromPg_ForeignKeyViolation(t *testing.T) {
+	pgErr := &pgconn.PgError{Code: pgForeignKeyViolation, TableName: "brews", ConstraintName: "brews_filter_paper_id_fkey"}
+
+	err := FromPg(pgErr, nil)
+	if !errors.Is(err, ErrInvalidReference) {
+		t.Fatalf("expected ErrInvalidReference, got %v", err)
+	}
+	var de *Error
+	if !errors.As(err, &de) || de.Field != "filter_paper_id" {
+		t.Errorf("expected field filter_paper_id, got %+v", de)
+	}
+}
+
+func TestFromPg_UnknownUniqueViolation(t *testing.T) {
+	pgErr := &pgconn.PgError{Code: pgUniqueViolation, TableName: "brew_pours", ConstraintName: "brew_pours_brew_id_pour_number_key"}
+
+	if err := FromPg(pgErr, nil); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestFromPg_PassThrough(t *testing.T) {
+	original := errors.New("connection reset")
+	if err := FromPg(original, nil); err != original {
+		t.Errorf("expected original error, got %v", err)
+	}
+	if err := FromPg(nil, nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+// Package grind translates grind settings between grinders using
+// user-supplied calibration data.
+//
+// Two kinds of calibration are supported. Paired points record settings on
+// two grinders that produced equivalent brews and are used directly when
+// enough exist for the grinder pair. Otherwise each grinder's setting→micron
+// curve is used: the source setting is converted to microns and the micron
+// value is mapped back onto the target grinder. Both curves are
+// piecewise-linear, extrapolating along the outermost segment.
+package grind
+
+import (
+	"math"
+	"sort"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// Translation methods.
+const (
+	MethodSame    = "same"
+	MethodPaired  = "paired"
+	MethodMicrons = "microns"
+)
+
+var (
+	ErrGrinderNotFound = domainerr.NotFound("Grinder not found")
+	ErrNotCalibrated   = domainerr.Invalid("to", "Not enough calibration data to translate between these grinders")
+)
+
+// Point is one (x, y) sample of a piecewise-linear curve.
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Pair records settings on two grinders that produced equivalent brews.
+type Pair struct {
+	FromGrinderID string
+	FromSetting   float64
+	ToGrinderID   string
+	ToSetting     float64
+}
+
+// Grinder is the calibration data for one grinder.
+type Grinder struct {
+	ID           string
+	Scale        validate.GrindScale
+	MicronPoints []Point // X: setting, Y: microns
+}
+
+// Translation is the result of converting a setting from one grinder to
+// another. Setting is the interpolated value clamped to the target scale;
+// DialSetting is Setting snapped to the target grinder's step.
+type Translation struct {
+	FromGrinderID string   `json:"from_grinder_id"`
+	FromSetting   float64  `json:"from_setting"`
+	ToGrinderID   string   `json:"to_grinder_id"`
+	Setting       float64  `json:"setting"`
+	DialSetting   float64  `json:"dial_setting"`
+	Microns       *float64 `json:"microns"`
+	Method        string   `json:"method"`
+	OutOfRange    bool     `json:"out_of_range"`
+}
+
+// Interpolate evaluates the piecewise-linear curve through points at x. It
+// needs at least two points with distinct X values.
+func Interpolate(points []Point, x float64) (float64, bool) {
+	pts := normalize(points)
+	if len(pts) < 2 {
+		return 0, false
+	}
+
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].X >= x })
+	switch {
+	case i == 0:
+		i = 1
+	case i == len(pts):
+		i = len(pts) - 1
+	}
+	a, b := pts[i-1], pts[i]
+	return a.Y + (x-a.X)*(b.Y-a.Y)/(b.X-a.X), true
+}
+
+// normalize sorts points by X and averages any points that share an X value.
+func normalize(points []Point) []Point {
+	pts := make([]Point, len(points))
+	copy(pts, points)
+	sort.Slice(pts, func(i, j int) bool { return pts[i].X < pts[j].X })
+
+	var out []Point
+	for i := 0; i < len(pts); {
+		j, sum := i, 0.0
+		for ; j < len(pts) && pts[j].X == pts[i].X; j++ {
+			sum += pts[j].Y
+		}
+		out = append(out, Point{X: pts[i].X, Y: sum / float64(j-i)})
+		i = j
+	}
+	return out
+}
+
+// micronCurve returns the grinder's setting→micron samples, adding the ends
+// of the scale's micron mapping where it has one.
+func (g Grinder) micronCurve() []Point {
+	pts := append([]Point(nil), g.MicronPoints...)
+	if g.Scale.MicronMin != nil && g.Scale.MicronMax != nil {
+		pts = append(pts,
+			Point{X: g.Scale.Min, Y: float64(*g.Scale.MicronMin)},
+			Point{X: g.Scale.Max, Y: float64(*g.Scale.MicronMax)},
+		)
+	}
+	return pts
+}
+
+// Translate converts setting on from to the equivalent setting on to.
+func Translate(from, to Grinder, pairs []Pair, setting float64) (*Translation, error) {
+	t := &Translation{
+		FromGrinderID: from.ID,
+		FromSetting:   setting,
+		ToGrinderID:   to.ID,
+	}
+
+	if from.ID == to.ID {
+		t.Method = MethodSame
+		t.Setting = setting
+		t.DialSetting = setting
+		return t, nil
+	}
+
+	var paired []Point
+	for _, p := range pairs {
+		switch {
+		case p.FromGrinderID == from.ID && p.ToGrinderID == to.ID:
+			paired = append(paired, Point{X: p.FromSetting, Y: p.ToSetting})
+		case p.FromGrinderID == to.ID && p.ToGrinderID == from.ID:
+			paired = append(paired, Point{X: p.ToSetting, Y: p.FromSetting})
+		}
+	}
+
+	if v, ok := Interpolate(paired, setting); ok {
+		t.Method = MethodPaired
+		t.Setting = v
+	} else {
+		microns, ok := Interpolate(from.micronCurve(), setting)
+		if !ok {
+			return nil, ErrNotCalibrated
+		}
+		inverse := to.micronCurve()
+		for i := range inverse {
+			inverse[i].X, inverse[i].Y = inverse[i].Y, inverse[i].X
+		}
+		v, ok := Interpolate(inverse, microns)
+		if !ok {
+			return nil, ErrNotCalibrated
+		}
+		t.Method = MethodMicrons
+		t.Setting = v
+		t.Microns = &microns
+	}
+
+	if t.Setting < to.Scale.Min {
+		t.Setting, t.OutOfRange = to.Scale.Min, true
+	} else if t.Setting > to.Scale.Max {
+		t.Setting, t.OutOfRange = to.Scale.Max, true
+	}
+	t.DialSetting = snap(t.Setting, to.Scale)
+	return t, nil
+}
+
+// snap rounds v to the nearest step on the scale. Stepless settings are
+// rounded to two decimal places.
+func snap(v float64, s validate.GrindScale) float64 {
+	if s.Stepless || s.Step == nil || *s.Step <= 0 {
+		return math.Round(v*100) / 100
+	}
+	n := math.Round((v - s.Min) / *s.Step)
+	snapped := s.Min + n**s.Step
+	if snapped > s.Max {
+		snapped -= *s.Step
+	}
+	return math.Round(snapped*1000) / 1000
+}
@@ -0,0 +1,128 @@
+package grind
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+func intPtr(v int) *int           { return &v }
+func floatPtr(v float64) *float64 { return &v }
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestInterpolate(t *testing.T) {
+	pts := []Point{{X: 10, Y: 100}, {X: 0, Y: 0}, {X: 20, Y: 300}}
+
+	tests := []struct {
+		x    float64
+		want float64
+	}{
+		{5, 50},
+		{10, 100},
+		{15, 200},
+		{-5, -50}, // extrapolates along the first segment
+		{25, 400}, // extrapolates along the last segment
+	}
+	for _, tt := range tests {
+		got, ok := Interpolate(pts, tt.x)
+		if !ok || !approx(got, tt.want) {
+			t.Errorf("Interpolate(%v) = %v, %v; want %v", tt.x, got, ok, tt.want)
+		}
+	}
+}
+
+func TestInterpolate_NeedsTwoDistinctPoints(t *testing.T) {
+	if _, ok := Interpolate(nil, 1); ok {
+		t.Error("expected no result for empty curve")
+	}
+	if _, ok := Interpolate([]Point{{X: 1, Y: 1}, {X: 1, Y: 3}}, 1); ok {
+		t.Error("expected no result for a single distinct X")
+	}
+}
+
+func TestTranslate_Paired(t *testing.T) {
+	from := Grinder{ID: "a", Scale: validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(1)}}
+	to := Grinder{ID: "b", Scale: validate.GrindScale{Min: 0, Max: 40, Step: floatPtr(1)}}
+	pairs := []Pair{
+		{FromGrinderID: "a", FromSetting: 4, ToGrinderID: "b", ToSetting: 16},
+		// Recorded from the other grinder's side
+		{FromGrinderID: "b", FromSetting: 24, ToGrinderID: "a", ToSetting: 6},
+	}
+
+	tr, err := Translate(from, to, pairs, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tr.Method != MethodPaired || !approx(tr.Setting, 20) || !approx(tr.DialSetting, 20) {
+		t.Errorf("unexpected translation: %+v", tr)
+	}
+}
+
+func TestTranslate_Microns(t *testing.T) {
+	from := Grinder{
+		ID:           "a",
+		Scale:        validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)},
+		MicronPoints: []Point{{X: 1, Y: 200}, {X: 6, Y: 700}, {X: 11, Y: 1200}},
+	}
+	to := Grinder{
+		ID:    "b",
+		Scale: validate.GrindScale{Min: 0, Max: 40, Step: floatPtr(1), MicronMin: intPtr(200), MicronMax: intPtr(1400)},
+	}
+
+	tr, err := Translate(from, to, nil, 3.5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tr.Method != MethodMicrons || tr.Microns == nil || !approx(*tr.Microns, 450) {
+		t.Fatalf("unexpected translation: %+v", tr)
+	}
+	// 450µm sits 250/1200 of the way along the target scale
+	if !approx(tr.Setting, 250.0/1200*40) {
+		t.Errorf("expected setting %v, got %v", 250.0/1200*40, tr.Setting)
+	}
+	if tr.DialSetting != 8 {
+		t.Errorf("expected dial setting 8, got %v", tr.DialSetting)
+	}
+}
+
+func TestTranslate_ClampsToTargetScale(t *testing.T) {
+	from := Grinder{ID: "a", Scale: validate.GrindScale{Min: 0, Max: 100, Stepless: true}}
+	to := Grinder{ID: "b", Scale: validate.GrindScale{Min: 1, Max: 10, Step: floatPtr(1)}}
+	pairs := []Pair{
+		{FromGrinderID: "a", FromSetting: 10, ToGrinderID: "b", ToSetting: 2},
+		{FromGrinderID: "a", FromSetting: 20, ToGrinderID: "b", ToSetting: 4},
+	}
+
+	tr, err := Translate(from, to, pairs, 90)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tr.OutOfRange || tr.Setting != 10 || tr.DialSetting != 10 {
+		t.Errorf("expected clamped translation, got %+v", tr)
+	}
+}
+
+func TestTranslate_SameGrinder(t *testing.T) {
+	g := Grinder{ID: "a", Scale: validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(1)}}
+	tr, err := Translate(g, g, nil, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tr.Method != MethodSame || tr.Setting != 4 {
+		t.Errorf("unexpected translation: %+v", tr)
+	}
+}
+
+func TestTranslate_NotCalibrated(t *testing.T) {
+	from := Grinder{ID: "a", Scale: validate.GrindScale{Min: 1, Max: 11, Step: floatPtr(1)}}
+	to := Grinder{ID: "b", Scale: validate.GrindScale{Min: 0, Max: 40, Step: floatPtr(1), MicronMin: intPtr(200), MicronMax: intPtr(1400)}}
+	pairs := []Pair{{FromGrinderID: "a", FromSetting: 4, ToGrinderID: "b", ToSetting: 16}}
+
+	_, err := Translate(from, to, pairs, 5)
+	if !errors.Is(err, ErrNotCalibrated) {
+		t.Errorf("expected ErrNotCalibrated, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package grind
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// ErrTargetNotFound is returned when the grinder being translated to does not
+// belong to the user.
+var ErrTargetNotFound = domainerr.InvalidReference("to", "Grinder not found")
+
+// Querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Load reads a grinder's scale and micron calibration points. It returns
+// nil if the grinder does not belong to the user or has been deleted.
+func Load(ctx context.Context, q Querier, userID, grinderID string) (*Grinder, error) {
+	g := Grinder{ID: grinderID}
+	err := q.QueryRow(ctx,
+		`SELECT scale_min, scale_max, scale_step, stepless, micron_min, micron_max
+		 FROM grinders WHERE id::text = $1 AND user_id = $2 AND deleted_at IS NULL`,
+		grinderID, userID,
+	).Scan(&g.Scale.Min, &g.Scale.Max, &g.Scale.Step, &g.Scale.Stepless, &g.Scale.MicronMin, &g.Scale.MicronMax)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.Query(ctx,
+		`SELECT setting, microns FROM grinder_calibration_points
+		 WHERE grinder_id = $1 AND microns IS NOT NULL`,
+		grinderID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p Point
+		var microns int
+		if err := rows.Scan(&p.X, &microns); err != nil {
+			return nil, err
+		}
+		p.Y = float64(microns)
+		g.MicronPoints = append(g.MicronPoints, p)
+	}
+	return &g, rows.Err()
+}
+
+// LoadPairs reads the paired calibration points recorded between two
+// grinders, in either direction.
+func LoadPairs(ctx context.Context, q Querier, grinderA, grinderB string) ([]Pair, error) {
+	rows, err := q.Query(ctx,
+		`SELECT grinder_id, setting, paired_grinder_id, paired_setting
+		 FROM grinder_calibration_points
+		 WHERE (grinder_id = $1 AND paired_grinder_id = $2)
+		    OR (grinder_id = $2 AND paired_grinder_id = $1)`,
+		grinderA, grinderB,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []Pair
+	for rows.Next() {
+		var p Pair
+		if err := rows.Scan(&p.FromGrinderID, &p.FromSetting, &p.ToGrinderID, &p.ToSetting); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+// TranslateStored loads calibration data for two of the user's grinders and
+// translates setting from one to the other. The setting must be valid on the
+// source grinder's scale.
+func TranslateStored(ctx context.Context, q Querier, userID, fromID, toID string, setting float64) (*Translation, error) {
+	from, err := Load(ctx, q, userID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, ErrGrinderNotFound
+	}
+	if err := validate.GrindSizeError("setting", &setting, from.Scale); err != nil {
+		return nil, err
+	}
+
+	to, err := Load(ctx, q, userID, toID)
+	if err != nil {
+		return nil, err
+	}
+	if to == nil {
+		return nil, ErrTargetNotFound
+	}
+
+	pairs, err := LoadPairs(ctx, q, from.ID, to.ID)
+	if err != nil {
+		return nil, err
+	}
+	return Translate(*from, *to, pairs, setting)
+}
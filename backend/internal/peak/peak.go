@@ -0,0 +1,165 @@
+// Package peak estimates the rest window in which a coffee tastes best.
+//
+// Scores are fitted against days off roast with a least-squares parabola. A
+// coffee that peaks has a downward-opening curve whose vertex falls inside
+// the observed days; the window is the run of days whose fitted score stays
+// within Tolerance of that vertex.
+package peak
+
+import "math"
+
+// Estimation limits. Fewer than MinSamples brews, or brews on fewer than
+// MinDistinctDays days, cannot support a curve. Confidence reaches its
+// ceiling once FullConfidenceSamples brews back the fit.
+const (
+	MinSamples            = 4
+	MinDistinctDays       = 3
+	FullConfidenceSamples = 12
+	Tolerance             = 0.5
+)
+
+// Rest statuses of a coffee relative to its window.
+const (
+	StatusResting  = "resting"
+	StatusInWindow = "in_window"
+	StatusPastPeak = "past_peak"
+)
+
+// Point is one brew's score at its days off roast.
+type Point struct {
+	DaysOffRoast float64
+	Score        float64
+}
+
+// Window is the estimated rest window, in whole days off roast. Confidence is
+// in [0, 1]: the fit's R² scaled down while the sample is small.
+type Window struct {
+	OptimalStart int     `json:"optimal_start"`
+	OptimalEnd   int     `json:"optimal_end"`
+	PeakDay      int     `json:"peak_day"`
+	PeakScore    float64 `json:"peak_score"`
+	Confidence   float64 `json:"confidence"`
+}
+
+// Estimate fits points and returns the window, or nil when there is too
+// little data or the scores show no peak within the observed days.
+func Estimate(points []Point) *Window {
+	n := len(points)
+	if n < MinSamples {
+		return nil
+	}
+
+	days := map[float64]bool{}
+	minDay, maxDay := math.Inf(1), math.Inf(-1)
+	var mean float64
+	for _, p := range points {
+		days[p.DaysOffRoast] = true
+		minDay = math.Min(minDay, p.DaysOffRoast)
+		maxDay = math.Max(maxDay, p.DaysOffRoast)
+		mean += p.DaysOffRoast
+	}
+	if len(days) < MinDistinctDays {
+		return nil
+	}
+	mean /= float64(n)
+
+	// Centre the days so the normal equations stay well conditioned.
+	var sx, sx2, sx3, sx4, sy, sxy, sx2y float64
+	for _, p := range points {
+		x := p.DaysOffRoast - mean
+		sx += x
+		sx2 += x * x
+		sx3 += x * x * x
+		sx4 += x * x * x * x
+		sy += p.Score
+		sxy += x * p.Score
+		sx2y += x * x * p.Score
+	}
+	a, b, c, ok := solve3(
+		[3][3]float64{
+			{float64(n), sx, sx2},
+			{sx, sx2, sx3},
+			{sx2, sx3, sx4},
+		},
+		[3]float64{sy, sxy, sx2y},
+	)
+	if !ok || c >= 0 {
+		return nil
+	}
+
+	vertex := -b / (2 * c)
+	peakDay := vertex + mean
+	if peakDay < minDay || peakDay > maxDay {
+		return nil
+	}
+	peakScore := a + b*vertex + c*vertex*vertex
+
+	half := math.Sqrt(Tolerance / -c)
+	start := int(math.Ceil(math.Max(minDay, peakDay-half)))
+	end := int(math.Floor(math.Min(maxDay, peakDay+half)))
+	if start > end {
+		start = int(math.Round(peakDay))
+		end = start
+	}
+
+	meanScore := sy / float64(n)
+	var ssRes, ssTot float64
+	for _, p := range points {
+		x := p.DaysOffRoast - mean
+		fit := a + b*x + c*x*x
+		ssRes += (p.Score - fit) * (p.Score - fit)
+		ssTot += (p.Score - meanScore) * (p.Score - meanScore)
+	}
+	r2 := 0.0
+	if ssTot > 0 {
+		r2 = math.Max(0, 1-ssRes/ssTot)
+	}
+	confidence := r2 * math.Min(1, float64(n)/FullConfidenceSamples)
+
+	return &Window{
+		OptimalStart: start,
+		OptimalEnd:   end,
+		PeakDay:      int(math.Round(peakDay)),
+		PeakScore:    round2(peakScore),
+		Confidence:   round2(confidence),
+	}
+}
+
+// Status places a coffee daysOffRoast days after roasting relative to w.
+func (w Window) Status(daysOffRoast int) string {
+	switch {
+	case daysOffRoast < w.OptimalStart:
+		return StatusResting
+	case daysOffRoast > w.OptimalEnd:
+		return StatusPastPeak
+	default:
+		return StatusInWindow
+	}
+}
+
+// solve3 solves m·v = rhs by Cramer's rule. ok is false when m is singular.
+func solve3(m [3][3]float64, rhs [3]float64) (x, y, z float64, ok bool) {
+	det := det3(m)
+	if math.Abs(det) < 1e-9 {
+		return 0, 0, 0, false
+	}
+	var out [3]float64
+	for col := range out {
+		mc := m
+		for row := range mc {
+			mc[row][col] = rhs[row]
+		}
+		out[col] = det3(mc) / det
+	}
+	return out[0], out[1], out[2], true
+}
+
+func det3(m [3][3]float64) float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,88 @@
+package peak
+
+import "testing"
+
+// parabola returns points on score = 9 - (d-10)²/8 at the given days.
+func parabola(days ...float64) []Point {
+	pts := make([]Point, len(days))
+	for i, d := range days {
+		pts[i] = Point{DaysOffRoast: d, Score: 9 - (d-10)*(d-10)/8}
+	}
+	return pts
+}
+
+func TestEstimate(t *testing.T) {
+	w := Estimate(parabola(4, 6, 8, 10, 12, 14, 16, 18, 20, 22, 24, 26))
+	if w == nil {
+		t.Fatal("expected a window")
+	}
+	// Fitted score stays within 0.5 of the peak for |d-10| <= 2.
+	if w.PeakDay != 10 || w.OptimalStart != 8 || w.OptimalEnd != 12 {
+		t.Errorf("window = %d..%d peak %d, want 8..12 peak 10", w.OptimalStart, w.OptimalEnd, w.PeakDay)
+	}
+	if w.PeakScore != 9 {
+		t.Errorf("peak score = %v, want 9", w.PeakScore)
+	}
+	if w.Confidence != 1 {
+		t.Errorf("confidence = %v, want 1 for an exact fit on a full sample", w.Confidence)
+	}
+}
+
+func TestEstimate_ConfidenceScalesWithSampleSize(t *testing.T) {
+	w := Estimate(parabola(4, 8, 12, 16, 20, 24))
+	if w == nil {
+		t.Fatal("expected a window")
+	}
+	if w.Confidence != 0.5 {
+		t.Errorf("confidence = %v, want 0.5 for 6 of %d samples", w.Confidence, FullConfidenceSamples)
+	}
+}
+
+func TestEstimate_ClampsToObservedDays(t *testing.T) {
+	w := Estimate(parabola(9, 10, 11, 14, 18))
+	if w == nil {
+		t.Fatal("expected a window")
+	}
+	if w.OptimalStart != 9 {
+		t.Errorf("start = %d, want 9 (first observed day)", w.OptimalStart)
+	}
+}
+
+func TestEstimate_NoPeak(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []Point
+	}{
+		{"too few samples", parabola(6, 10, 14)},
+		{"too few distinct days", []Point{{7, 8}, {7, 9}, {14, 7}, {14, 8}}},
+		{"flat", []Point{{5, 7}, {10, 7}, {15, 7}, {20, 7}}},
+		{"still improving", []Point{{5, 5}, {10, 6}, {15, 7}, {20, 8}}},
+		{"valley", []Point{{5, 8}, {10, 6}, {15, 6}, {20, 8}}},
+		{"peak beyond last brew", []Point{{2, 4}, {4, 6}, {6, 7.5}, {8, 8.5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := Estimate(tt.points); w != nil {
+				t.Errorf("expected no window, got %+v", *w)
+			}
+		})
+	}
+}
+
+func TestWindowStatus(t *testing.T) {
+	w := Window{OptimalStart: 8, OptimalEnd: 12}
+	tests := []struct {
+		days int
+		want string
+	}{
+		{3, StatusResting},
+		{8, StatusInWindow},
+		{12, StatusInWindow},
+		{13, StatusPastPeak},
+	}
+	for _, tt := range tests {
+		if got := w.Status(tt.days); got != tt.want {
+			t.Errorf("Status(%d) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package tags attaches a user's tags to coffees and brews. Tags are matched
+// by name ignoring case; naming a tag that doesn't exist yet creates it.
+package tags
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+// Ways a tag filter combines its names.
+const (
+	MatchAny = "any"
+	MatchAll = "all"
+)
+
+// Tag limits. A name fits the VARCHAR(100) column and has no commas, which
+// separate names in tag filters.
+const (
+	MaxLength = 100
+	MaxTags   = 20
+)
+
+// Target is a kind of taggable record, identified by its join table.
+type Target struct {
+	table  string
+	column string
+}
+
+var (
+	Coffees = Target{table: "coffee_tags", column: "coffee_id"}
+	Brews   = Target{table: "brew_tags", column: "brew_id"}
+)
+
+// Normalize trims names and drops blanks and case-insensitive duplicates,
+// keeping the first spelling. A nil slice stays nil, so callers can tell
+// "leave the tags alone" from "remove every tag".
+func Normalize(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	out := []string{}
+	seen := map[string]bool{}
+	for _, n := range names {
+		n = strings.TrimSpace(n)
+		key := strings.ToLower(n)
+		if n == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, n)
+	}
+	return out
+}
+
+// ValidateName checks a single tag name, which must already be trimmed.
+func ValidateName(errs *validate.Errors, field, name string) {
+	switch {
+	case name == "":
+		errs.Add(field, "is required")
+	case utf8.RuneCountInString(name) > MaxLength:
+		errs.Add(field, fmt.Sprintf("must be at most %d characters", MaxLength))
+	case strings.Contains(name, ","):
+		errs.Add(field, "must not contain commas")
+	}
+}
+
+// Validate checks the tag names on a coffee or brew.
+func Validate(errs *validate.Errors, field string, names []string) {
+	if len(names) > MaxTags {
+		errs.Add(field, fmt.Sprintf("must have at most %d tags", MaxTags))
+	}
+	for i, n := range names {
+		ValidateName(errs, fmt.Sprintf("%s[%d]", field, i), n)
+	}
+}
+
+// Parse splits a comma-separated query parameter into normalized names.
+func Parse(param string) []string {
+	if param == "" {
+		return nil
+	}
+	names := Normalize(strings.Split(param, ","))
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// NamesSQL selects the tag names of the record whose ID is ref, sorted
+// ignoring case, as a text array.
+func (t Target) NamesSQL(ref string) string {
+	return fmt.Sprintf(
+		`ARRAY(SELECT tg.name FROM %[1]s jt JOIN tags tg ON tg.id = jt.tag_id
+			WHERE jt.%[2]s = %[3]s ORDER BY LOWER(tg.name))`,
+		t.table, t.column, ref,
+	)
+}
+
+// FilterSQL is a condition matching records, whose ID is ref, tagged with
+// any or with all of the names bound to placeholder $argIdx as a text array.
+func (t Target) FilterSQL(ref, match string, argIdx int) string {
+	tagged := fmt.Sprintf(
+		`SELECT 1 FROM %s jt JOIN tags tg ON tg.id = jt.tag_id WHERE jt.%s = %s`,
+		t.table, t.column, ref,
+	)
+	if match == MatchAll {
+		return fmt.Sprintf(
+			`NOT EXISTS (SELECT 1 FROM unnest($%d::text[]) AS want(name)
+				WHERE NOT EXISTS (%s AND LOWER(tg.name) = LOWER(want.name)))`,
+			argIdx, tagged,
+		)
+	}
+	return fmt.Sprintf(
+		`EXISTS (%s AND LOWER(tg.name) IN (SELECT LOWER(n) FROM unnest($%d::text[]) AS n))`,
+		tagged, argIdx,
+	)
+}
+
+// Set replaces the tags on record id with names, creating the user's missing
+// tags. names must already be normalized.
+func Set(ctx context.Context, tx pgx.Tx, t Target, userID, id string, names []string) error {
+	if _, err := tx.Exec(ctx,
+		fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, t.table, t.column),
+		id,
+	); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO tags (user_id, name)
+		 SELECT $1, n FROM unnest($2::text[]) AS n
+		 ON CONFLICT (user_id, LOWER(name)) DO NOTHING`,
+		userID, names,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx,
+		fmt.Sprintf(
+			`INSERT INTO %s (%s, tag_id)
+			 SELECT $1, id FROM tags
+			 WHERE user_id = $2 AND LOWER(name) IN (SELECT LOWER(n) FROM unnest($3::text[]) AS n)`,
+			t.table, t.column,
+		),
+		id, userID, names,
+	)
+	return err
+}
+
+// Names returns the tag names of record id, sorted ignoring case.
+func Names(ctx context.Context, tx pgx.Tx, t Target, id string) ([]string, error) {
+	var names []string
+	err := tx.QueryRow(ctx, `SELECT `+t.NamesSQL("$1::uuid"), id).Scan(&names)
+	return names, err
+}
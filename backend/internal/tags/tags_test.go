@@ -0,0 +1,79 @@
+package tags
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/validate"
+)
+
+func TestNormalize(t *testing.T) {
+	got := Normalize([]string{" Blueberry", "jasmine", "", "blueberry ", "  ", "Jasmine", "Cacao"})
+	want := []string{"Blueberry", "jasmine", "Cacao"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize = %q, want %q", got, want)
+	}
+
+	if got := Normalize(nil); got != nil {
+		t.Errorf("Normalize(nil) = %q, want nil", got)
+	}
+	if got := Normalize([]string{" "}); got == nil || len(got) != 0 {
+		t.Errorf("Normalize of blanks = %#v, want an empty non-nil slice", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	if got := Parse("blueberry, Jasmine,,BLUEBERRY"); !reflect.DeepEqual(got, []string{"blueberry", "Jasmine"}) {
+		t.Errorf("Parse = %q", got)
+	}
+	if got := Parse(""); got != nil {
+		t.Errorf("Parse(\"\") = %q, want nil", got)
+	}
+	if got := Parse(" , "); got != nil {
+		t.Errorf("Parse of blanks = %q, want nil", got)
+	}
+}
+
+func TestFilterSQL(t *testing.T) {
+	anySQL := Coffees.FilterSQL("c.id", MatchAny, 3)
+	if !strings.HasPrefix(anySQL, "EXISTS") || !strings.Contains(anySQL, "coffee_tags") || !strings.Contains(anySQL, "$3::text[]") {
+		t.Errorf("unexpected any filter: %s", anySQL)
+	}
+
+	allSQL := Brews.FilterSQL("b.id", MatchAll, 5)
+	if !strings.HasPrefix(allSQL, "NOT EXISTS") || !strings.Contains(allSQL, "jt.brew_id = b.id") || !strings.Contains(allSQL, "$5::text[]") {
+		t.Errorf("unexpected all filter: %s", allSQL)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var errs validate.Errors
+	Validate(&errs, "tags", []string{"blueberry", "", strings.Repeat("é", MaxLength+1), "lemon, lime"})
+	want := []string{"tags[1]", "tags[2]", "tags[3]"}
+	if len(errs) != len(want) {
+		t.Fatalf("expected errors on %v, got %v", want, errs)
+	}
+	for i := range errs {
+		if errs[i].Field != want[i] {
+			t.Errorf("expected %s, got %s", want[i], errs[i].Field)
+		}
+	}
+
+	errs = nil
+	Validate(&errs, "tags", []string{strings.Repeat("é", MaxLength)})
+	if len(errs) != 0 {
+		t.Errorf("expected a %d-character name to pass, got %v", MaxLength, errs)
+	}
+
+	errs = nil
+	many := make([]string, MaxTags+1)
+	for i := range many {
+		many[i] = fmt.Sprintf("tag-%d", i)
+	}
+	Validate(&errs, "tags", many)
+	if len(errs) != 1 || errs[0].Field != "tags" {
+		t.Errorf("expected one error on tags, got %v", errs)
+	}
+}
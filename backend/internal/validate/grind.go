@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/domainerr"
+)
+
+// GrindScale describes the settings a grinder accepts. Step is nil for
+// stepless grinders. MicronMin and MicronMax, when set, give the approximate
+// particle size at either end of the scale.
+type GrindScale struct {
+	Min       float64
+	Max       float64
+	Step      *float64
+	Stepless  bool
+	MicronMin *int
+	MicronMax *int
+}
+
+// stepTolerance absorbs decimal round-trip error when checking that a
+// setting falls on a step.
+const stepTolerance = 1e-6
+
+// OnStep reports whether v lies on one of the scale's steps. Every value is
+// on a step for stepless grinders.
+func (s GrindScale) OnStep(v float64) bool {
+	if s.Stepless || s.Step == nil || *s.Step <= 0 {
+		return true
+	}
+	n := (v - s.Min) / *s.Step
+	return math.Abs(n-math.Round(n)) < stepTolerance
+}
+
+// Microns estimates the particle size for a setting by interpolating linearly
+// between MicronMin and MicronMax. It returns nil when the grinder has no
+// micron mapping.
+func (s GrindScale) Microns(setting float64) *float64 {
+	if s.MicronMin == nil || s.MicronMax == nil || s.Max <= s.Min {
+		return nil
+	}
+	frac := (setting - s.Min) / (s.Max - s.Min)
+	microns := float64(*s.MicronMin) + frac*float64(*s.MicronMax-*s.MicronMin)
+	return &microns
+}
+
+// GrindScale checks that a grinder's scale definition is usable.
+func (e *Errors) GrindScale(s GrindScale) {
+	if s.Max <= s.Min {
+		e.Add("scale_max", "must be greater than scale_min")
+	}
+	if !s.Stepless {
+		switch {
+		case s.Step == nil:
+			e.Add("scale_step", "Step is required for stepped grinders")
+		case *s.Step <= 0:
+			e.Add("scale_step", "must be a positive number")
+		case s.Max > s.Min && *s.Step > s.Max-s.Min:
+			e.Add("scale_step", "must not exceed the scale range")
+		}
+	}
+	if (s.MicronMin == nil) != (s.MicronMax == nil) {
+		e.Add("micron_min", "micron_min and micron_max must be set together")
+	} else if s.MicronMin != nil {
+		if *s.MicronMin <= 0 {
+			e.Add("micron_min", "must be a positive number")
+		}
+		if *s.MicronMax == *s.MicronMin {
+			e.Add("micron_max", "must differ from micron_min")
+		}
+	}
+}
+
+// GrindSize checks that v, when set, is a valid setting on the given scale.
+func (e *Errors) GrindSize(field string, v *float64, s GrindScale) {
+	if v == nil {
+		return
+	}
+	if *v < s.Min || *v > s.Max {
+		e.Add(field, fmt.Sprintf("must be between %g and %g for this grinder", s.Min, s.Max))
+		return
+	}
+	if !s.OnStep(*v) {
+		e.Add(field, fmt.Sprintf("must be in steps of %g for this grinder", *s.Step))
+	}
+}
+
+// GrindSizeError is GrindSize for checks that can only run once the grinder
+// has been loaded, typically inside a repository transaction. It returns a
+// domainerr.ErrInvalid error, or nil when the setting is valid.
+func GrindSizeError(field string, v *float64, s GrindScale) error {
+	var errs Errors
+	errs.GrindSize(field, v, s)
+	if len(errs) > 0 {
+		return domainerr.Invalid(errs[0].Field, errs[0].Message)
+	}
+	return nil
+}
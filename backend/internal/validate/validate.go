@@ -0,0 +1,141 @@
+// Package validate provides field-level request validation helpers that
+// collect api.FieldError values instead of failing on the first problem.
+package validate
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/poimgs/coffee-tracker/backend/internal/api"
+)
+
+// Bounds for the setup fields shared by brews, recipes and defaults. They
+// mirror the column precision in the database and reject obvious typos.
+const (
+	MaxCoffeeWeight     = 1000
+	MinRatio            = 1
+	MaxRatio            = 30
+	MinWaterTemperature = 0
+	MaxWaterTemperature = 100
+)
+
+// Errors accumulates field errors for a single request.
+type Errors []api.FieldError
+
+// Add records an error for the given field.
+func (e *Errors) Add(field, message string) {
+	*e = append(*e, api.FieldError{Field: field, Message: message})
+}
+
+// IntRange checks that v, when set, lies within [min, max].
+func (e *Errors) IntRange(field string, v *int, min, max int) {
+	if v != nil && (*v < min || *v > max) {
+		e.Add(field, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+}
+
+// FloatRange checks that v, when set, lies within [min, max].
+func (e *Errors) FloatRange(field string, v *float64, min, max float64) {
+	if v != nil && (*v < min || *v > max) {
+		e.Add(field, fmt.Sprintf("must be between %g and %g", min, max))
+	}
+}
+
+// Positive checks that v, when set, is greater than zero and below max.
+func (e *Errors) Positive(field string, v *float64, max float64) {
+	if v == nil {
+		return
+	}
+	if *v <= 0 {
+		e.Add(field, "must be a positive number")
+	} else if *v >= max {
+		e.Add(field, fmt.Sprintf("must be less than %g", max))
+	}
+}
+
+// NonNegative checks that v, when set, is zero or greater.
+func (e *Errors) NonNegative(field string, v *int) {
+	if v != nil && *v < 0 {
+		e.Add(field, "must not be negative")
+	}
+}
+
+// Date checks that v, when set, is a YYYY-MM-DD date that is not after today.
+func (e *Errors) Date(field string, v *string) {
+	if v == nil {
+		return
+	}
+	d, err := time.Parse("2006-01-02", *v)
+	if err != nil {
+		e.Add(field, "must be a date in YYYY-MM-DD format")
+		return
+	}
+	if isFuture(d, time.Now()) {
+		e.Add(field, "cannot be in the future")
+	}
+}
+
+// latestZone is UTC+14, the first timezone to reach each date.
+var latestZone = time.FixedZone("UTC+14", 14*60*60)
+
+// isFuture reports whether the date d is after today in every timezone at
+// now. Dates don't carry the user's timezone, so a user ahead of UTC can
+// always enter their local today.
+func isFuture(d, now time.Time) bool {
+	y, m, day := now.In(latestZone).Date()
+	return d.After(time.Date(y, m, day, 0, 0, 0, 0, time.UTC))
+}
+
+// Setup checks the dose, ratio and water temperature of a brew template.
+func (e *Errors) Setup(coffeeWeight, ratio, waterTemperature *float64) {
+	e.Positive("coffee_weight", coffeeWeight, MaxCoffeeWeight)
+	e.FloatRange("ratio", ratio, MinRatio, MaxRatio)
+	e.FloatRange("water_temperature", waterTemperature, MinWaterTemperature, MaxWaterTemperature)
+}
+
+// Pour is the common shape of a brew pour, recipe pour, or pour default.
+type Pour struct {
+	PourNumber  int
+	WaterAmount *float64
+	PourStyle   *string
+	WaitTime    *int
+}
+
+// Pours checks that pour numbers are unique and contiguous from 1, that each
+// pour has a valid style and non-negative amounts, and that the total water
+// poured does not exceed coffeeWeight * ratio when both are known.
+func (e *Errors) Pours(field string, pours []Pour, coffeeWeight, ratio *float64) {
+	seen := make(map[int]bool, len(pours))
+	var total float64
+	for i, p := range pours {
+		prefix := fmt.Sprintf("%s[%d]", field, i)
+
+		switch {
+		case p.PourNumber < 1 || p.PourNumber > len(pours):
+			e.Add(prefix+".pour_number", fmt.Sprintf("must be between 1 and %d", len(pours)))
+		case seen[p.PourNumber]:
+			e.Add(prefix+".pour_number", fmt.Sprintf("duplicate pour_number %d", p.PourNumber))
+		}
+		seen[p.PourNumber] = true
+
+		if p.WaterAmount != nil {
+			if *p.WaterAmount <= 0 {
+				e.Add(prefix+".water_amount", "must be a positive number")
+			}
+			total += *p.WaterAmount
+		}
+		if p.PourStyle != nil && *p.PourStyle != "circular" && *p.PourStyle != "center" {
+			e.Add(prefix+".pour_style", "must be 'circular' or 'center'")
+		}
+		e.NonNegative(prefix+".wait_time", p.WaitTime)
+	}
+
+	if coffeeWeight == nil || ratio == nil {
+		return
+	}
+	// Allow for rounding of the computed water weight.
+	waterWeight := *coffeeWeight * *ratio
+	if total > waterWeight+0.01 {
+		e.Add(field, fmt.Sprintf("total water poured (%gg) exceeds water weight (%gg)", total, waterWeight))
+	}
+}
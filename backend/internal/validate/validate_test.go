@@ -0,0 +1,222 @@
+package validate
+
+import (
+	"testing"
+	"time"
+)
+
+func intPtr(v int) *int           { return &v }
+func floatPtr(v float64) *float64 { return &v }
+func strPtr(s string) *string     { return &s }
+
+func fields(errs Errors) []string {
+	var out []string
+	for _, e := range errs {
+		out = append(out, e.Field)
+	}
+	return out
+}
+
+func TestIntRange(t *testing.T) {
+	var errs Errors
+	errs.IntRange("a", nil, 1, 10)
+	errs.IntRange("b", intPtr(1), 1, 10)
+	errs.IntRange("c", intPtr(10), 1, 10)
+	errs.IntRange("d", intPtr(0), 1, 10)
+	errs.IntRange("e", intPtr(11), 1, 10)
+
+	got := fields(errs)
+	if len(got) != 2 || got[0] != "d" || got[1] != "e" {
+		t.Errorf("expected errors for d and e, got %v", got)
+	}
+}
+
+func TestPositive(t *testing.T) {
+	var errs Errors
+	errs.Positive("ok", floatPtr(15), 1000)
+	errs.Positive("zero", floatPtr(0), 1000)
+	errs.Positive("negative", floatPtr(-1), 1000)
+	errs.Positive("too_big", floatPtr(1000), 1000)
+
+	got := fields(errs)
+	if len(got) != 3 || got[0] != "zero" || got[1] != "negative" || got[2] != "too_big" {
+		t.Errorf("unexpected errors: %v", got)
+	}
+}
+
+func TestDate(t *testing.T) {
+	// Two days ahead of UTC is in the future everywhere.
+	future := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+	tests := []struct {
+		value   *string
+		wantErr bool
+	}{
+		{nil, false},
+		{strPtr("2026-01-15"), false},
+		{strPtr("15/01/2026"), true},
+		{strPtr("2026-02-30"), true},
+		{strPtr(future), true},
+	}
+
+	for _, tt := range tests {
+		var errs Errors
+		errs.Date("brew_date", tt.value)
+		if (len(errs) > 0) != tt.wantErr {
+			t.Errorf("Date(%v): got errors %v, wantErr %v", tt.value, errs, tt.wantErr)
+		}
+	}
+}
+
+func TestIsFuture_LatestTimezone(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	tests := []struct {
+		date string
+		now  string
+		want bool
+	}{
+		// Midnight in UTC+14: March 11 has begun there.
+		{"2026-03-11", "2026-03-10T10:00:00Z", false},
+		{"2026-03-11", "2026-03-10T09:59:59Z", true},
+		{"2026-03-12", "2026-03-10T10:00:00Z", true},
+		{"2026-03-10", "2026-03-10T00:00:00Z", false},
+	}
+	for _, tt := range tests {
+		now, _ := time.Parse(time.RFC3339, tt.now)
+		if got := isFuture(date(tt.date), now); got != tt.want {
+			t.Errorf("isFuture(%s, %s) = %v, want %v", tt.date, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestPours_Valid(t *testing.T) {
+	var errs Errors
+	errs.Pours("pours", []Pour{
+		{PourNumber: 2, WaterAmount: floatPtr(195), PourStyle: strPtr("circular")},
+		{PourNumber: 1, WaterAmount: floatPtr(45), PourStyle: strPtr("center"), WaitTime: intPtr(30)},
+	}, floatPtr(15), floatPtr(16))
+
+	if len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestPours_NumberingErrors(t *testing.T) {
+	var errs Errors
+	errs.Pours("pours", []Pour{
+		{PourNumber: 1},
+		{PourNumber: 1},
+		{PourNumber: 4},
+	}, nil, nil)
+
+	got := fields(errs)
+	if len(got) != 2 || got[0] != "pours[1].pour_number" || got[1] != "pours[2].pour_number" {
+		t.Errorf("unexpected errors: %v", got)
+	}
+}
+
+func TestPours_FieldErrors(t *testing.T) {
+	var errs Errors
+	errs.Pours("pours", []Pour{
+		{PourNumber: 1, WaterAmount: floatPtr(0), PourStyle: strPtr("pulse"), WaitTime: intPtr(-5)},
+	}, nil, nil)
+
+	got := fields(errs)
+	want := []string{"pours[0].water_amount", "pours[0].pour_style", "pours[0].wait_time"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %s, got %s", want[i], got[i])
+		}
+	}
+}
+
+func TestPours_ExceedsWaterWeight(t *testing.T) {
+	var errs Errors
+	errs.Pours("pours", []Pour{
+		{PourNumber: 1, WaterAmount: floatPtr(50)},
+		{PourNumber: 2, WaterAmount: floatPtr(200)},
+	}, floatPtr(15), floatPtr(16))
+
+	got := fields(errs)
+	if len(got) != 1 || got[0] != "pours" {
+		t.Errorf("expected a single pours error, got %v", got)
+	}
+}
+
+func TestGrindSize(t *testing.T) {
+	stepped := GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}
+	stepless := GrindScale{Min: 0, Max: 40, Stepless: true}
+
+	tests := []struct {
+		name    string
+		value   *float64
+		scale   GrindScale
+		wantErr bool
+	}{
+		{"unset", nil, stepped, false},
+		{"on step", floatPtr(3.5), stepped, false},
+		{"scale max", floatPtr(11), stepped, false},
+		{"off step", floatPtr(3.3), stepped, true},
+		{"below min", floatPtr(0.5), stepped, true},
+		{"above max", floatPtr(11.5), stepped, true},
+		{"stepless any value", floatPtr(17.3), stepless, false},
+		{"stepless above max", floatPtr(41), stepless, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs Errors
+			errs.GrindSize("grind_size", tt.value, tt.scale)
+			if (len(errs) > 0) != tt.wantErr {
+				t.Errorf("got errors %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGrindScale(t *testing.T) {
+	tests := []struct {
+		name       string
+		scale      GrindScale
+		wantFields []string
+	}{
+		{"valid stepped", GrindScale{Min: 1, Max: 11, Step: floatPtr(0.5)}, nil},
+		{"valid stepless", GrindScale{Min: 0, Max: 40, Stepless: true}, nil},
+		{"inverted range", GrindScale{Min: 10, Max: 1, Step: floatPtr(1)}, []string{"scale_max"}},
+		{"missing step", GrindScale{Min: 1, Max: 11}, []string{"scale_step"}},
+		{"half micron mapping", GrindScale{Min: 1, Max: 11, Step: floatPtr(1), MicronMin: intPtr(200)}, []string{"micron_min"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs Errors
+			errs.GrindScale(tt.scale)
+			got := fields(errs)
+			if len(got) != len(tt.wantFields) {
+				t.Fatalf("expected %v, got %v", tt.wantFields, got)
+			}
+			for i := range got {
+				if got[i] != tt.wantFields[i] {
+					t.Errorf("expected %s, got %s", tt.wantFields[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestGrindScale_Microns(t *testing.T) {
+	scale := GrindScale{Min: 1, Max: 11, Step: floatPtr(1), MicronMin: intPtr(250), MicronMax: intPtr(1250)}
+
+	got := scale.Microns(6)
+	if got == nil || *got != 750 {
+		t.Errorf("expected 750 microns, got %v", got)
+	}
+	if (GrindScale{Min: 1, Max: 11}).Microns(6) != nil {
+		t.Error("expected nil without a micron mapping")
+	}
+}
@@ -0,0 +1,209 @@
+// Package wheel maps free-text tasting notes onto the SCA/WCR Coffee
+// Taster's Flavor Wheel.
+//
+// The wheel and its synonym dictionary are embedded from wheel.json. Every
+// subcategory and descriptor name is a term in its own right; synonyms add
+// further terms, and a synonym with an empty target marks a word that is too
+// generic to map ("fresh"). Category names only match through synonyms.
+package wheel
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Category is a top-level segment of the wheel.
+type Category struct {
+	Name          string        `json:"name"`
+	Subcategories []Subcategory `json:"subcategories"`
+}
+
+// Subcategory is a middle-ring segment. Some have no descriptors.
+type Subcategory struct {
+	Name        string   `json:"name"`
+	Descriptors []string `json:"descriptors"`
+}
+
+// Match is the wheel position of one recognized term. Subcategory and
+// Descriptor are empty when the term names a broader segment.
+type Match struct {
+	Category    string
+	Subcategory string
+	Descriptor  string
+}
+
+//go:embed wheel.json
+var wheelJSON []byte
+
+var std = mustLoad(wheelJSON)
+
+// dictionary holds the wheel and its terms, keyed by normalized phrase.
+type dictionary struct {
+	categories []Category
+	terms      map[string]Match
+	// maxWords is the length in words of the longest term.
+	maxWords int
+}
+
+func mustLoad(data []byte) *dictionary {
+	d, err := load(data)
+	if err != nil {
+		panic(fmt.Sprintf("wheel: %v", err))
+	}
+	return d
+}
+
+func load(data []byte) (*dictionary, error) {
+	var file struct {
+		Categories []Category        `json:"categories"`
+		Synonyms   map[string]string `json:"synonyms"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	d := &dictionary{categories: file.Categories, terms: map[string]Match{}}
+	categories := map[string]Match{}
+	subcategories := map[string]Match{}
+	descriptors := map[string]Match{}
+	for _, c := range file.Categories {
+		categories[c.Name] = Match{Category: c.Name}
+		for _, s := range c.Subcategories {
+			subcategories[s.Name] = Match{Category: c.Name, Subcategory: s.Name}
+			d.add(s.Name, subcategories[s.Name])
+			for _, desc := range s.Descriptors {
+				descriptors[desc] = Match{Category: c.Name, Subcategory: s.Name, Descriptor: desc}
+				d.add(desc, descriptors[desc])
+			}
+		}
+	}
+
+	for term, target := range file.Synonyms {
+		if target == "" {
+			delete(d.terms, normalize(term))
+			continue
+		}
+		m, ok := descriptors[target]
+		if !ok {
+			m, ok = subcategories[target]
+		}
+		if !ok {
+			m, ok = categories[target]
+		}
+		if !ok {
+			return nil, fmt.Errorf("synonym %q names unknown segment %q", term, target)
+		}
+		d.add(term, m)
+	}
+	return d, nil
+}
+
+func (d *dictionary) add(term string, m Match) {
+	key := normalize(term)
+	d.terms[key] = m
+	if n := len(strings.Fields(key)); n > d.maxWords {
+		d.maxWords = n
+	}
+}
+
+// Categories returns the wheel's categories in wheel order. The result is
+// shared and must not be modified.
+func Categories() []Category {
+	return std.categories
+}
+
+// Map finds the wheel segments named in text, in the order they appear and
+// without repeats. Comma- or semicolon-separated notes are matched
+// separately; within a note the longest known phrase wins, so "raw honey"
+// is Honey rather than Raw.
+func Map(text string) []Match {
+	var matches []Match
+	seen := map[Match]bool{}
+	for _, note := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		words := strings.Fields(normalize(note))
+		for i := 0; i < len(words); {
+			m, n := std.longest(words[i:])
+			if n == 0 {
+				i++
+				continue
+			}
+			if !seen[m] {
+				seen[m] = true
+				matches = append(matches, m)
+			}
+			i += n
+		}
+	}
+	return matches
+}
+
+// TopCategories returns the categories named in text, in wheel order.
+func TopCategories(text string) []string {
+	found := map[string]bool{}
+	for _, m := range Map(text) {
+		found[m.Category] = true
+	}
+	names := []string{}
+	for _, c := range std.categories {
+		if found[c.Name] {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// longest returns the longest term at the start of words and its length in
+// words, or zero when none matches.
+func (d *dictionary) longest(words []string) (Match, int) {
+	n := d.maxWords
+	if n > len(words) {
+		n = len(words)
+	}
+	for ; n > 0; n-- {
+		if m, ok := d.lookup(words[:n]); ok {
+			return m, n
+		}
+	}
+	return Match{}, 0
+}
+
+// lookup matches a phrase, also trying its last word without a plural
+// ending: "cherries", "peaches", "almonds".
+func (d *dictionary) lookup(words []string) (Match, bool) {
+	phrase := strings.Join(words, " ")
+	if m, ok := d.terms[phrase]; ok {
+		return m, true
+	}
+	for _, singular := range singulars(phrase) {
+		if m, ok := d.terms[singular]; ok {
+			return m, true
+		}
+	}
+	return Match{}, false
+}
+
+func singulars(phrase string) []string {
+	var out []string
+	if s, ok := strings.CutSuffix(phrase, "ies"); ok {
+		out = append(out, s+"y")
+	}
+	if s, ok := strings.CutSuffix(phrase, "es"); ok {
+		out = append(out, s)
+	}
+	if s, ok := strings.CutSuffix(phrase, "s"); ok {
+		out = append(out, s)
+	}
+	return out
+}
+
+// normalize lower-cases s and turns punctuation into word breaks, so
+// "Hay-like" and "hay like" are the same term.
+func normalize(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,65 @@
+package wheel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCategories(t *testing.T) {
+	cats := Categories()
+	if len(cats) != 9 {
+		t.Fatalf("expected 9 categories, got %d", len(cats))
+	}
+	if cats[0].Name != "Floral" || cats[8].Name != "Sweet" {
+		t.Errorf("expected Floral..Sweet, got %s..%s", cats[0].Name, cats[8].Name)
+	}
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Match
+	}{
+		{"Blueberry", []Match{{"Fruity", "Berry", "Blueberry"}}},
+		{"Apricot Nectar, Lemon Sorbet, Raw Honey", []Match{
+			{"Fruity", "Other Fruit", ""},
+			{"Fruity", "Citrus Fruit", "Lemon"},
+			{"Sweet", "Brown Sugar", "Honey"},
+		}},
+		{"dark chocolate; toasted almonds", []Match{
+			{"Nutty/Cocoa", "Cocoa", "Dark Chocolate"},
+			{"Nutty/Cocoa", "Nutty", "Almond"},
+		}},
+		{"CHERRIES and hay-like", []Match{
+			{"Fruity", "Other Fruit", "Cherry"},
+			{"Green/Vegetative", "Green/Vegetative", "Hay-like"},
+		}},
+		{"juicy and sweet", []Match{{"Sweet", "", ""}}},
+		{"lemon, lemon, Lemon", []Match{{"Fruity", "Citrus Fruit", "Lemon"}}},
+		{"fresh and clean", nil},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Map(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Map(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTopCategories(t *testing.T) {
+	got := TopCategories("Raw Honey, jasmine, Lemon Sorbet, lime")
+	want := []string{"Floral", "Fruity", "Sweet"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TopCategories = %v, want %v", got, want)
+	}
+	if got := TopCategories("clean"); len(got) != 0 {
+		t.Errorf("expected no categories, got %v", got)
+	}
+}
+
+func TestLoad_UnknownSynonymTarget(t *testing.T) {
+	data := []byte(`{"categories": [{"name": "Sweet", "subcategories": []}], "synonyms": {"sugar": "Candy"}}`)
+	if _, err := load(data); err == nil {
+		t.Error("expected an error for a synonym naming no segment")
+	}
+}